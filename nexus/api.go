@@ -4,11 +4,13 @@
 package nexus
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"mime"
 	"net/http"
+	"time"
 )
 
 // Package version.
@@ -16,10 +18,27 @@ import (
 const version = "dev"
 
 const (
-	headerContentType    = "Content-Type"
-	headerOperationState = "Nexus-Operation-State"
-	headerOperationID    = "Nexus-Operation-Id"
-	headerRequestID      = "Nexus-Request-Id"
+	headerContentType     = "Content-Type"
+	headerLocation        = "Location"
+	headerOperationState  = "Nexus-Operation-State"
+	headerOperationID     = "Nexus-Operation-Id"
+	headerRequestID       = "Nexus-Request-Id"
+	headerRequestTimeout  = "Request-Timeout"
+	headerPriority        = "Nexus-Priority"
+	headerIdempotencyKey  = "Idempotency-Key"
+	headerResultPartial   = "Nexus-Result-Partial"
+	headerTimeoutSource   = "Nexus-Timeout-Source"
+	headerNextPageToken   = "Nexus-Next-Page-Token"
+	headerStartAfter      = "Nexus-Start-After"
+	headerResultAvailable = "Nexus-Result-Available"
+	headerClientWillPoll  = "Nexus-Client-Will-Poll"
+)
+
+// Values for the Nexus-Timeout-Source header, set on long poll timeout responses to indicate whether the server's
+// max wait time or the client's requested timeout was exceeded.
+const (
+	timeoutSourceServer = "server"
+	timeoutSourceClient = "client"
 )
 
 const contentTypeJSON = "application/json"
@@ -30,6 +49,9 @@ const queryCallbackURL = "callback"
 // Query param for passing wait duration.
 const queryWait = "wait"
 
+// Query param for passing a result page token.
+const queryPageToken = "page_token"
+
 const statusOperationRunning = http.StatusPreconditionFailed
 
 // HTTP status code for failed operation responses.
@@ -60,15 +82,102 @@ func (e *UnsuccessfulOperationError) Error() string {
 	return fmt.Sprintf("operation %s", e.State)
 }
 
+// Failure metadata key for an application defined failure code, see [WithFailureCode].
+const failureMetadataCode = "code"
+
+// Code returns the application defined failure code set with [WithFailureCode], or an empty string if not set.
+func (f Failure) Code() string {
+	return f.Metadata[failureMetadataCode]
+}
+
+// FailureOption customizes a [Failure] constructed with [NewFailedOperationError] or [NewCanceledOperationError].
+type FailureOption func(*Failure)
+
+// WithFailureCode sets an application defined failure code, e.g. "INSUFFICIENT_FUNDS", allowing callers to handle
+// failures without parsing messages. The code is delivered in the failure's metadata and read with [Failure.Code].
+func WithFailureCode(code string) FailureOption {
+	return WithFailureMetadata(failureMetadataCode, code)
+}
+
+// WithFailureMetadata sets a failure metadata entry.
+func WithFailureMetadata(key, value string) FailureOption {
+	return func(f *Failure) {
+		if f.Metadata == nil {
+			f.Metadata = make(map[string]string)
+		}
+		f.Metadata[key] = value
+	}
+}
+
+// WithFailureDetails sets the failure's JSON details.
+func WithFailureDetails(details json.RawMessage) FailureOption {
+	return func(f *Failure) {
+		f.Details = details
+	}
+}
+
+// NewFailedOperationError constructs an [UnsuccessfulOperationError] for an operation that completed as failed.
+func NewFailedOperationError(message string, options ...FailureOption) *UnsuccessfulOperationError {
+	return newUnsuccessfulOperationError(OperationStateFailed, message, options)
+}
+
+// NewCanceledOperationError constructs an [UnsuccessfulOperationError] for an operation that completed as canceled.
+func NewCanceledOperationError(message string, options ...FailureOption) *UnsuccessfulOperationError {
+	return newUnsuccessfulOperationError(OperationStateCanceled, message, options)
+}
+
+func newUnsuccessfulOperationError(state OperationState, message string, options []FailureOption) *UnsuccessfulOperationError {
+	failure := Failure{Message: message}
+	for _, option := range options {
+		option(&failure)
+	}
+	return &UnsuccessfulOperationError{State: state, Failure: failure}
+}
+
 // ErrOperationStillRunning indicates that an operation is still running while trying to get its result.
 var ErrOperationStillRunning = errors.New("operation still running")
 
+// ErrOperationCanceledSynchronously may be returned from Handler.CancelOperation to indicate that the operation was
+// canceled before returning, rather than the cancelation request merely being delivered. The handler responds with
+// 204 No Content instead of 202 Accepted.
+var ErrOperationCanceledSynchronously = errors.New("operation canceled synchronously")
+
+// ErrOperationConflict indicates that an operation conflicts with an existing resource, e.g. when starting an operation
+// with an ID that is already in use by a different operation. Handlers may return it, optionally wrapped with
+// additional context, to fail a request with a 409 status code. Clients fail requests that get a 409 status code with
+// an *[UnexpectedResponseError] matching ErrOperationConflict via [errors.Is].
+var ErrOperationConflict = errors.New("operation conflict")
+
+// ErrOperationResultUnavailable indicates that an operation was accepted by a handler without a result to retrieve,
+// see [OperationResponseAccepted].
+var ErrOperationResultUnavailable = errors.New("operation result unavailable")
+
+// ErrOperationDeadlineExceeded indicates that the deadline of an operation, as communicated by the handler in
+// [OperationInfo.Deadline], passed while waiting for the operation's completion.
+var ErrOperationDeadlineExceeded = errors.New("operation deadline exceeded")
+
+// ErrRequestTimeoutExceeded is returned from [OperationHandle.GetResult] when the handler timed out a long poll request
+// at the timeout requested by the client, derived from the call's context deadline, before the operation completed.
+// Timeouts at the handler's own max wait time, see [HandlerOptions.GetResultTimeout], are followed by another poll
+// instead. Matches [context.DeadlineExceeded] via [errors.Is].
+var ErrRequestTimeoutExceeded = fmt.Errorf("request timeout exceeded: %w", context.DeadlineExceeded)
+
 // OperationInfo conveys information about an operation.
 type OperationInfo struct {
 	// ID of the operation.
 	ID string `json:"id"`
 	// State of the operation.
 	State OperationState `json:"state"`
+	// Progress of the operation as a fraction between 0 and 1. Optional.
+	// Clients that don't understand this field ignore it.
+	Progress *float64 `json:"progress,omitempty"`
+	// A free-form, human readable message describing the operation's current status. Optional.
+	// Clients that don't understand this field ignore it.
+	Message string `json:"message,omitempty"`
+	// Time after which the handler gives up on the operation. Optional.
+	// Clients stop waiting for the operation's completion once the deadline passes, failing with
+	// [ErrOperationDeadlineExceeded]. Clients that don't understand this field ignore it.
+	Deadline *time.Time `json:"deadline,omitempty"`
 }
 
 // OperationState represents the variable states of an operation.
@@ -1,6 +1,7 @@
 package nexus
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -74,3 +75,43 @@ func TestFailure_JSONMarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUnsuccessfulOperationError(t *testing.T) {
+	err := NewFailedOperationError("insufficient funds",
+		WithFailureCode("INSUFFICIENT_FUNDS"),
+		WithFailureMetadata("account", "123"),
+		WithFailureDetails(json.RawMessage(`{"balance":0}`)),
+	)
+	require.Equal(t, &UnsuccessfulOperationError{
+		State: OperationStateFailed,
+		Failure: Failure{
+			Message:  "insufficient funds",
+			Metadata: map[string]string{"code": "INSUFFICIENT_FUNDS", "account": "123"},
+			Details:  json.RawMessage(`{"balance":0}`),
+		},
+	}, err)
+	require.Equal(t, "INSUFFICIENT_FUNDS", err.Failure.Code())
+
+	err = NewCanceledOperationError("canceled by user")
+	require.Equal(t, &UnsuccessfulOperationError{State: OperationStateCanceled, Failure: Failure{Message: "canceled by user"}}, err)
+	require.Equal(t, "", err.Failure.Code())
+}
+
+func TestNewUnsuccessfulOperationError_RoundTrip(t *testing.T) {
+	ctx, client, teardown := setup(t, &failureCodeHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateCanceled, unsuccessfulError.State)
+	require.Equal(t, "CODE", unsuccessfulError.Failure.Code())
+}
+
+type failureCodeHandler struct {
+	UnimplementedHandler
+}
+
+func (h *failureCodeHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, NewCanceledOperationError("canceled", WithFailureCode("CODE"))
+}
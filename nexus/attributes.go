@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type requestAttributesKey struct{}
+
+// RequestAttributes is a request scoped bag of values for passing data computed by middleware, e.g. a resolved tenant
+// or a parsed auth token, to a [Handler] without defining dedicated context keys. It is safe for concurrent use.
+//
+// Handlers created with [NewHTTPHandler] populate an empty bag in the request context unless one is already present.
+// Middleware wrapping the handler may create the bag up front with [ContextWithRequestAttributes], the handler reuses
+// it.
+type RequestAttributes struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+// Set sets the value for key, replacing any existing value.
+func (a *RequestAttributes) Set(key string, value any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.values == nil {
+		a.values = make(map[string]any)
+	}
+	a.values[key] = value
+}
+
+// Get returns the value for key and whether it was set.
+func (a *RequestAttributes) Get(key string) (any, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	value, ok := a.values[key]
+	return value, ok
+}
+
+// RequestAttributesFromContext returns the [RequestAttributes] stored in ctx, or nil if there are none.
+func RequestAttributesFromContext(ctx context.Context) *RequestAttributes {
+	attributes, _ := ctx.Value(requestAttributesKey{}).(*RequestAttributes)
+	return attributes
+}
+
+// ContextWithRequestAttributes returns a context holding a [RequestAttributes] bag along with the bag itself. If ctx
+// already holds a bag, ctx and the existing bag are returned.
+func ContextWithRequestAttributes(ctx context.Context) (context.Context, *RequestAttributes) {
+	if attributes := RequestAttributesFromContext(ctx); attributes != nil {
+		return ctx, attributes
+	}
+	attributes := &RequestAttributes{}
+	return context.WithValue(ctx, requestAttributesKey{}, attributes), attributes
+}
+
+// withRequestAttributes wraps an [http.Handler], ensuring that each request's context holds a [RequestAttributes] bag.
+func withRequestAttributes(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if RequestAttributesFromContext(request.Context()) == nil {
+			ctx, _ := ContextWithRequestAttributes(request.Context())
+			request = request.WithContext(ctx)
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
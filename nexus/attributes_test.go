@@ -0,0 +1,58 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type attributesEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *attributesEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	attributes := RequestAttributesFromContext(ctx)
+	if attributes == nil {
+		return nil, newBadRequestError("missing request attributes")
+	}
+	tenant, _ := attributes.Get("tenant")
+	return NewOperationResponseSync(tenant)
+}
+
+func TestRequestAttributes(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &attributesEchoHandler{}})
+
+	// An empty bag is populated when no middleware sets one.
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "null", writer.Body.String())
+
+	// Values set by middleware are visible to the handler.
+	middleware := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx, attributes := ContextWithRequestAttributes(request.Context())
+		attributes.Set("tenant", "acme")
+		handler.ServeHTTP(writer, request.WithContext(ctx))
+	})
+	writer = httptest.NewRecorder()
+	middleware.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, `"acme"`, writer.Body.String())
+}
+
+func TestContextWithRequestAttributes_ReusesExisting(t *testing.T) {
+	require.Nil(t, RequestAttributesFromContext(context.Background()))
+	ctx, attributes := ContextWithRequestAttributes(context.Background())
+	attributes.Set("foo", 1)
+	ctx2, attributes2 := ContextWithRequestAttributes(ctx)
+	require.Equal(t, ctx, ctx2)
+	require.Same(t, attributes, attributes2)
+	value, ok := attributes2.Get("foo")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+	_, ok = attributes2.Get("bar")
+	require.False(t, ok)
+}
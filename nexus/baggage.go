@@ -0,0 +1,89 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const headerBaggage = "Baggage"
+
+type baggageKey struct{}
+
+// Baggage is a set of key-value pairs propagated across operation boundaries in the W3C baggage header, e.g. for
+// correlation or business context that should flow through chained Nexus calls.
+//
+// Handlers created with [NewHTTPHandler] populate the request context with the caller's baggage and clients
+// propagate the baggage in their request context, so baggage received by a handler flows into calls it makes to other
+// services without additional plumbing. Baggage entry properties are not supported and dropped.
+type Baggage map[string]string
+
+// ContextWithBaggage returns a context holding the given baggage, replacing any existing baggage.
+func ContextWithBaggage(ctx context.Context, baggage Baggage) context.Context {
+	return context.WithValue(ctx, baggageKey{}, baggage)
+}
+
+// BaggageFromContext returns the [Baggage] stored in ctx, or nil if there is none. The returned baggage must not be
+// modified, use [ContextWithBaggage] with a copy to add entries.
+func BaggageFromContext(ctx context.Context) Baggage {
+	baggage, _ := ctx.Value(baggageKey{}).(Baggage)
+	return baggage
+}
+
+// parseBaggage parses baggage header values, skipping malformed entries.
+func parseBaggage(values []string) Baggage {
+	var baggage Baggage
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			// Drop properties.
+			member, _, _ = strings.Cut(member, ";")
+			key, value, ok := strings.Cut(member, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				continue
+			}
+			value, err := url.PathUnescape(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+			if baggage == nil {
+				baggage = make(Baggage)
+			}
+			baggage[key] = value
+		}
+	}
+	return baggage
+}
+
+// format formats baggage as a baggage header value with entries sorted by key.
+func (b Baggage) format() string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	members := make([]string, len(keys))
+	for i, key := range keys {
+		members[i] = key + "=" + url.PathEscape(b[key])
+	}
+	return strings.Join(members, ",")
+}
+
+// setBaggageHeader sets the baggage header from the baggage in ctx, unless the header is already set.
+func setBaggageHeader(ctx context.Context, header http.Header) {
+	if baggage := BaggageFromContext(ctx); len(baggage) > 0 && header.Get(headerBaggage) == "" {
+		header.Set(headerBaggage, baggage.format())
+	}
+}
+
+// withBaggage wraps an [http.Handler], storing the baggage received in the request's baggage header in its context.
+func withBaggage(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if baggage := parseBaggage(request.Header.Values(headerBaggage)); baggage != nil {
+			request = request.WithContext(ContextWithBaggage(request.Context(), baggage))
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type baggageEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *baggageEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(BaggageFromContext(ctx))
+}
+
+// baggageForwardingHandler calls a downstream service, adding an entry to the baggage it received.
+type baggageForwardingHandler struct {
+	UnimplementedHandler
+	downstream *Client
+}
+
+func (h *baggageForwardingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	baggage := Baggage{"hop": "forwarder"}
+	for key, value := range BaggageFromContext(ctx) {
+		baggage[key] = value
+	}
+	result, err := h.downstream.StartOperation(ContextWithBaggage(ctx, baggage), StartOperationOptions{Operation: request.Operation})
+	if err != nil {
+		return nil, err
+	}
+	return &OperationResponseSync{Header: result.Successful.Header, Body: result.Successful.Body}, nil
+}
+
+func TestBaggage_TwoHops(t *testing.T) {
+	_, downstream, teardownDownstream := setup(t, &baggageEchoHandler{})
+	defer teardownDownstream()
+	ctx, client, teardown := setup(t, &baggageForwardingHandler{downstream: downstream})
+	defer teardown()
+
+	ctx = ContextWithBaggage(ctx, Baggage{"tenant": "acme corp", "request": "a,b=c"})
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var baggage Baggage
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&baggage))
+	require.Equal(t, Baggage{"tenant": "acme corp", "request": "a,b=c", "hop": "forwarder"}, baggage)
+}
+
+func TestBaggage_ExplicitHeader(t *testing.T) {
+	ctx, client, teardown := setup(t, &baggageEchoHandler{})
+	defer teardown()
+
+	ctx = ContextWithBaggage(ctx, Baggage{"from": "context"})
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{
+		Operation: "foo",
+		Header:    http.Header{headerBaggage: []string{"from=header;property, invalid, =empty"}},
+	})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var baggage Baggage
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&baggage))
+	require.Equal(t, Baggage{"from": "header"}, baggage)
+}
+
+func TestBaggage_Format(t *testing.T) {
+	baggage := Baggage{"b": "2 3", "a": "1"}
+	require.Equal(t, "a=1,b=2%203", baggage.format())
+	require.Equal(t, baggage, parseBaggage([]string{baggage.format()}))
+	require.Nil(t, parseBaggage(nil))
+}
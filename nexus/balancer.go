@@ -0,0 +1,134 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Error indicating both ServiceBaseURL and ServiceBaseURLs were used to create a [Client].
+var errServiceBaseURLsConflict = errors.New("ServiceBaseURL and ServiceBaseURLs are mutually exclusive")
+
+const (
+	defaultMaxConsecutiveFailures = 3
+	defaultEjectionCooldown       = 30 * time.Second
+)
+
+// LoadBalancingOptions configure passive health checking of the service instances listed in
+// [ClientOptions.ServiceBaseURLs].
+type LoadBalancingOptions struct {
+	// Number of consecutive failed requests after which an instance is ejected, i.e. stops receiving requests that are
+	// not pinned to it. A request fails when no response is received or when the response has a 502, 503, or 504
+	// status code. Optional, defaults to 3.
+	MaxConsecutiveFailures int
+	// Duration an ejected instance is excluded for before being reintroduced. Optional, defaults to 30 seconds.
+	EjectionCooldown time.Duration
+}
+
+// balancer distributes requests round robin across service instances, ejecting instances that fail consecutive
+// requests for a cooldown period.
+type balancer struct {
+	options   LoadBalancingOptions
+	mu        sync.Mutex
+	instances []*balancedInstance
+	// Index of the instance to consider next.
+	next int
+}
+
+type balancedInstance struct {
+	baseURL      *url.URL
+	failures     int
+	ejectedUntil time.Time
+}
+
+func newBalancer(baseURLs []*url.URL, options LoadBalancingOptions) (*balancer, error) {
+	if options.MaxConsecutiveFailures <= 0 {
+		options.MaxConsecutiveFailures = defaultMaxConsecutiveFailures
+	}
+	if options.EjectionCooldown <= 0 {
+		options.EjectionCooldown = defaultEjectionCooldown
+	}
+	b := &balancer{options: options}
+	hosts := make(map[string]bool, len(baseURLs))
+	for _, u := range baseURLs {
+		// Responses are attributed to instances by host.
+		if hosts[u.Host] {
+			return nil, fmt.Errorf("duplicate host in ServiceBaseURLs: %q", u.Host)
+		}
+		hosts[u.Host] = true
+		b.instances = append(b.instances, &balancedInstance{baseURL: u})
+	}
+	return b, nil
+}
+
+// pick returns the base URL of the next healthy instance, or of the next instance if all instances are ejected.
+func (b *balancer) pick() *url.URL {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for i := 0; i < len(b.instances); i++ {
+		instance := b.instances[(b.next+i)%len(b.instances)]
+		if !now.Before(instance.ejectedUntil) {
+			b.next = (b.next + i + 1) % len(b.instances)
+			return instance.baseURL
+		}
+	}
+	// Fail open rather than rejecting requests when all instances are ejected.
+	instance := b.instances[b.next]
+	b.next = (b.next + 1) % len(b.instances)
+	return instance.baseURL
+}
+
+// baseURLs returns the base URLs of all instances, including ejected ones.
+func (b *balancer) baseURLs() []*url.URL {
+	baseURLs := make([]*url.URL, len(b.instances))
+	for i, instance := range b.instances {
+		baseURLs[i] = instance.baseURL
+	}
+	return baseURLs
+}
+
+// record updates the health of the instance hosting the given URL.
+func (b *balancer) record(u *url.URL, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, instance := range b.instances {
+		if instance.baseURL.Host != u.Host {
+			continue
+		}
+		if !failed {
+			instance.failures = 0
+			return
+		}
+		instance.failures++
+		if instance.failures >= b.options.MaxConsecutiveFailures {
+			instance.failures = 0
+			instance.ejectedUntil = time.Now().Add(b.options.EjectionCooldown)
+		}
+		return
+	}
+}
+
+// wrap wraps an HTTP caller, recording the outcome of each request against the instance it was sent to.
+func (b *balancer) wrap(caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		response, err := caller(request)
+		if err != nil {
+			// Don't penalize the instance for requests canceled by the caller.
+			if request.Context().Err() == nil {
+				b.record(request.URL, true)
+			}
+			return response, err
+		}
+		switch response.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			b.record(request.URL, true)
+		default:
+			b.record(request.URL, false)
+		}
+		return response, nil
+	}
+}
@@ -0,0 +1,158 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInstances simulates multiple service instances, responding with operation info identifying the instance.
+type fakeInstances struct {
+	mu        sync.Mutex
+	unhealthy map[string]bool
+	hosts     []string
+}
+
+func (f *fakeInstances) call(request *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	host := request.URL.Host
+	f.hosts = append(f.hosts, host)
+	if f.unhealthy[host] {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    request,
+		}, nil
+	}
+	b, err := json.Marshal(OperationInfo{ID: host, State: OperationStateRunning})
+	if err != nil {
+		return nil, err
+	}
+	statusCode := http.StatusOK
+	if request.Method == "POST" {
+		statusCode = http.StatusCreated
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{headerContentType: []string{contentTypeJSON}},
+		Body:       io.NopCloser(strings.NewReader(string(b))),
+		Request:    request,
+	}, nil
+}
+
+func (f *fakeInstances) setUnhealthy(host string, unhealthy bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unhealthy[host] = unhealthy
+}
+
+func (f *fakeInstances) takeHosts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hosts := f.hosts
+	f.hosts = nil
+	return hosts
+}
+
+func TestLoadBalancing(t *testing.T) {
+	instances := &fakeInstances{unhealthy: map[string]bool{}}
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURLs: []string{"http://a", "http://b", "http://c"},
+		LoadBalancing:   LoadBalancingOptions{MaxConsecutiveFailures: 2, EjectionCooldown: time.Millisecond * 100},
+		HTTPCaller:      instances.call,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := func() (*OperationHandle, error) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+		if err != nil {
+			return nil, err
+		}
+		return result.Pending, nil
+	}
+
+	var handles []*OperationHandle
+	for i := 0; i < 3; i++ {
+		handle, err := start()
+		require.NoError(t, err)
+		handles = append(handles, handle)
+	}
+	require.Equal(t, []string{"a", "b", "c"}, instances.takeHosts())
+
+	// Handles are pinned to the instance their operation was started on.
+	for _, handle := range handles {
+		for i := 0; i < 2; i++ {
+			info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+			require.NoError(t, err)
+			require.Equal(t, handle.ID, info.ID)
+		}
+		require.Equal(t, handle.ID, handle.URL().Host)
+	}
+	require.Equal(t, []string{"a", "a", "b", "b", "c", "c"}, instances.takeHosts())
+
+	// Eject a after two consecutive failures.
+	instances.setUnhealthy("a", true)
+	for i := 0; i < 2; i++ {
+		_, err = handles[0].GetInfo(ctx, GetOperationInfoOptions{})
+		require.Error(t, err)
+	}
+	instances.takeHosts()
+	for i := 0; i < 4; i++ {
+		_, err := start()
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"b", "c", "b", "c"}, instances.takeHosts())
+
+	// Pinned handles keep sending requests to ejected instances.
+	_, err = handles[0].GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+	require.Equal(t, []string{"a"}, instances.takeHosts())
+
+	// a is reintroduced after the cooldown.
+	instances.setUnhealthy("a", false)
+	time.Sleep(time.Millisecond * 150)
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		handle, err := start()
+		require.NoError(t, err)
+		seen[handle.ID] = true
+	}
+	require.Equal(t, map[string]bool{"a": true, "b": true, "c": true}, seen)
+}
+
+func TestLoadBalancing_AllEjected(t *testing.T) {
+	instances := &fakeInstances{unhealthy: map[string]bool{"a": true, "b": true}}
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURLs: []string{"http://a", "http://b"},
+		LoadBalancing:   LoadBalancingOptions{MaxConsecutiveFailures: 1},
+		HTTPCaller:      instances.call,
+	})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+		require.Error(t, err)
+	}
+	// Requests keep being distributed when all instances are ejected.
+	require.Equal(t, []string{"a", "b", "a", "b"}, instances.takeHosts())
+}
+
+func TestLoadBalancing_InvalidOptions(t *testing.T) {
+	_, err := NewClient(ClientOptions{ServiceBaseURL: "http://a", ServiceBaseURLs: []string{"http://b"}})
+	require.ErrorIs(t, err, errServiceBaseURLsConflict)
+
+	_, err = NewClient(ClientOptions{ServiceBaseURLs: []string{"http://a/x", "http://a/y"}})
+	require.ErrorContains(t, err, "duplicate host")
+
+	_, err = NewClient(ClientOptions{ServiceBaseURLs: []string{"http://a", "ftp://b"}})
+	require.ErrorIs(t, err, errInvalidURLScheme)
+}
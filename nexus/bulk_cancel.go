@@ -0,0 +1,182 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Path of the bulk cancelation endpoint, relative to the service base URL.
+const cancelOperationsPath = "/_cancel"
+
+// CancelFilter selects the operations to cancel in [Handler.CancelOperations]. Operations must match all of the set
+// criteria, interpretation of the criteria is up to the handler.
+type CancelFilter struct {
+	// Cancel operations with this name. Optional, empty matches all operations.
+	Operation string `json:"operation,omitempty"`
+	// Cancel operations whose ID starts with this prefix. Optional.
+	IDPrefix string `json:"idPrefix,omitempty"`
+	// Handler defined criteria, e.g. {"tenant": "acme"}. Optional.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CancelResult summarizes the outcome of a bulk cancelation request.
+type CancelResult struct {
+	// Number of matching operations cancelation was delivered to, including operations that were already canceled.
+	Canceled int `json:"canceled"`
+	// Number of matching operations cancelation could not be delivered to.
+	Failed int `json:"failed"`
+}
+
+// CancelOperationsRequest is input for Handler.CancelOperations.
+type CancelOperationsRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
+	// Request ID that may be used by the handler to dedupe retried requests, see [CancelOperationsOptions.RequestID].
+	RequestID string
+	// Operations to cancel.
+	Filter CancelFilter
+	// The original HTTP request.
+	HTTPRequest *http.Request
+}
+
+func (h *httpHandler) cancelOperations(writer http.ResponseWriter, request *http.Request) {
+	handlerRequest := &CancelOperationsRequest{
+		RequestID:   request.Header.Get(headerRequestID),
+		HTTPRequest: request,
+	}
+	if service, ok := mux.Vars(request)["service"]; ok {
+		var err error
+		if handlerRequest.Service, err = url.PathUnescape(service); err != nil {
+			h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+			return
+		}
+	}
+	limit := h.options.MaxRequestBodyBytes
+	if limit <= 0 {
+		limit = maxCancelFilterBytes
+	}
+	if err := h.limitRequestBody(writer, request, limit); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		var handlerError *HandlerError
+		if !errors.As(err, &handlerError) {
+			err = newBadRequestError("failed to read request body")
+		}
+		h.writeFailure(writer, err)
+		return
+	}
+	if len(body) > 0 {
+		if err := h.jsonDecodingOptions().decode(body, &handlerRequest.Filter); err != nil {
+			h.writeFailure(writer, newBadRequestError("invalid cancel filter: %v", err))
+			return
+		}
+	}
+
+	result, err := h.options.Handler.CancelOperations(request.Context(), handlerRequest)
+	err = h.checkUnimplemented(err, handlerRequest.Filter.Operation, "CancelOperations")
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	bytes, err := json.Marshal(result)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal cancel result: %w", err))
+		return
+	}
+	writer.Header().Set(headerContentType, contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// CancelOperationsOptions are options for [Client.CancelOperations].
+type CancelOperationsOptions struct {
+	// Name of the service hosting the operations. Optional, defaults to [ClientOptions.Service].
+	Service string
+	// Operations to cancel.
+	Filter CancelFilter
+	// Request ID that may be used by the handler to dedupe retried requests. Optional, a random UUID is generated if
+	// not set. Set it explicitly and reuse it when retrying a request to make the retry safe against double counting.
+	RequestID string
+	// Header to attach to the HTTP request. Optional.
+	Header http.Header
+}
+
+// CancelOperations requests to cancel all operations matching a filter, e.g. all operations of a tenant, issuing a
+// single network request to the service handler.
+//
+// As with [OperationHandle.Cancel], cancelation is asynchronous and idempotent, the returned result counts the
+// operations cancelation was delivered to. Fails with an [UnexpectedResponseError] if the handler does not support
+// bulk cancelation, with the response status set to 501.
+func (c *Client) CancelOperations(ctx context.Context, options CancelOperationsOptions) (*CancelResult, error) {
+	ctx, record := c.recordCall(ctx, options.Filter.Operation, ClientMethodCancelOperations)
+	result, err := c.cancelOperations(ctx, options)
+	record(ClientCallOutcomeSuccessful, err)
+	return result, c.mapError(err)
+}
+
+func (c *Client) cancelOperations(ctx context.Context, options CancelOperationsOptions) (*CancelResult, error) {
+	if options.Service == "" {
+		options.Service = c.options.Service
+	}
+	b, err := json.Marshal(options.Filter)
+	if err != nil {
+		return nil, err
+	}
+	base := c.baseURL()
+	if options.Service != "" {
+		base = base.JoinPath(url.PathEscape(options.Service))
+	}
+	url := base.JoinPath(cancelOperationsPath)
+	if err := c.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := c.newRequest(ctx, options.Filter.Operation, "POST", url.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if options.Header != nil {
+		request.Header = options.Header.Clone()
+	}
+	if options.RequestID == "" {
+		options.RequestID = uuid.NewString()
+	}
+	request.Header.Set(headerRequestID, options.RequestID)
+	request.Header.Set(headerContentType, contentTypeJSON)
+	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, c.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	}
+	if !isContentTypeJSON(response.Header) {
+		return nil, c.newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get(headerContentType)), response, body)
+	}
+	var result CancelResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,100 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type bulkCancelHandler struct {
+	UnimplementedHandler
+	requests []*CancelOperationsRequest
+}
+
+func (h *bulkCancelHandler) CancelOperations(ctx context.Context, request *CancelOperationsRequest) (*CancelResult, error) {
+	h.requests = append(h.requests, request)
+	if request.Filter.Labels["tenant"] == "" {
+		return nil, newBadRequestError("tenant label required")
+	}
+	return &CancelResult{Canceled: 3, Failed: 1}, nil
+}
+
+func TestCancelOperations(t *testing.T) {
+	handler := &bulkCancelHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	filter := CancelFilter{Operation: "foo", IDPrefix: "acme-", Labels: map[string]string{"tenant": "acme"}}
+	result, err := client.CancelOperations(ctx, CancelOperationsOptions{Filter: filter, RequestID: "abc"})
+	require.NoError(t, err)
+	require.Equal(t, &CancelResult{Canceled: 3, Failed: 1}, result)
+	require.Len(t, handler.requests, 1)
+	require.Equal(t, filter, handler.requests[0].Filter)
+	require.Equal(t, "abc", handler.requests[0].RequestID)
+	require.Equal(t, "", handler.requests[0].Service)
+
+	_, err = client.CancelOperations(ctx, CancelOperationsOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "tenant label required", unexpectedResponseError.Failure.Message)
+	// A request ID is generated when not provided.
+	require.NotEmpty(t, handler.requests[1].RequestID)
+}
+
+func TestCancelOperations_ServiceRouting(t *testing.T) {
+	handler := &bulkCancelHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler, ServiceRouting: true}, ClientOptions{Service: "my/service"})
+	defer teardown()
+
+	_, err := client.CancelOperations(ctx, CancelOperationsOptions{Filter: CancelFilter{Labels: map[string]string{"tenant": "acme"}}})
+	require.NoError(t, err)
+	require.Equal(t, "my/service", handler.requests[0].Service)
+}
+
+func TestCancelOperations_NotImplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &UnimplementedHandler{})
+	defer teardown()
+
+	_, err := client.CancelOperations(ctx, CancelOperationsOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestCancelOperations_InvalidFilter(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &bulkCancelHandler{}})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/_cancel", strings.NewReader("[]")))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+}
+
+func TestCancelOperations_BodyTooLarge(t *testing.T) {
+	filter := `{"labels":{"tenant":"` + strings.Repeat("x", maxCancelFilterBytes) + `"}}`
+	cases := []struct {
+		name    string
+		options HandlerOptions
+		body    io.Reader
+		limit   int
+	}{
+		{name: "declared length", body: strings.NewReader(filter), limit: maxCancelFilterBytes},
+		// MultiReader hides the length of the body.
+		{name: "unknown length", body: io.MultiReader(strings.NewReader(filter)), limit: maxCancelFilterBytes},
+		{name: "max request body bytes", options: HandlerOptions{MaxRequestBodyBytes: 10}, body: strings.NewReader(`{"operation":"charge"}`), limit: 10},
+	}
+	for _, c := range cases {
+		handler := &bulkCancelHandler{}
+		c.options.Handler = handler
+		writer := httptest.NewRecorder()
+		NewHTTPHandler(c.options).ServeHTTP(writer, httptest.NewRequest("POST", "/_cancel", c.body))
+		require.Equal(t, http.StatusBadRequest, writer.Code, c.name)
+		require.Contains(t, writer.Body.String(), fmt.Sprintf("request body exceeds max size of %d bytes", c.limit), c.name)
+		require.Empty(t, handler.requests, c.name)
+	}
+}
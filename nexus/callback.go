@@ -0,0 +1,163 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Header for passing callbacks in start operation requests. May be repeated.
+//
+// Values are formatted similarly to the Link header: `<https://example.com/callback>; events="succeeded,failed"`. The
+// events parameter is optional, omitting it implies that the callback should be called for all completion states.
+const headerCallback = "Nexus-Callback"
+
+var errInvalidCallback = errors.New("invalid callback")
+
+// Callback describes a URL to deliver async operation completions to.
+type Callback struct {
+	// URL to deliver completions to.
+	URL string
+	// Completion states this callback should be called for. Optional.
+	// An empty list implies all completion states (succeeded, failed, and canceled).
+	Events []OperationState
+}
+
+// Matches returns true if this callback should be called for the given completion state.
+func (c Callback) Matches(state OperationState) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, event := range c.Events {
+		if event == state {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHeaderValue formats the callback as a Nexus-Callback header value, failing if its URL is invalid. Characters
+// that would break parsing of the value, e.g. angle brackets, are percent-encoded.
+func (c Callback) formatHeaderValue() (string, error) {
+	if _, err := url.Parse(c.URL); err != nil || c.URL == "" {
+		return "", fmt.Errorf("%w: invalid URL: %q", errInvalidCallback, c.URL)
+	}
+	u := escapeCallbackURL(c.URL)
+	if len(c.Events) == 0 {
+		return "<" + u + ">", nil
+	}
+	events := make([]string, len(c.Events))
+	for i, event := range c.Events {
+		events[i] = string(event)
+	}
+	return fmt.Sprintf(`<%s>; events="%s"`, u, strings.Join(events, ",")), nil
+}
+
+// escapeCallbackURL percent-encodes characters that may not appear in a URL enclosed in angle brackets, preserving the
+// meaning of the URL.
+func escapeCallbackURL(u string) string {
+	var b strings.Builder
+	for i := 0; i < len(u); i++ {
+		switch c := u[i]; {
+		case c == '<', c == '>', c == '"', c <= ' ', c >= 0x7f:
+			fmt.Fprintf(&b, "%%%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// splitCallbackHeaderValue splits a Nexus-Callback header value into its elements, which may have been combined into
+// a single comma separated line, e.g. by a proxy. Commas within angle brackets and quoted strings don't separate
+// elements.
+func splitCallbackHeaderValue(value string) []string {
+	var elements []string
+	var inURL, inQuotes bool
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case inQuotes:
+			if c == '"' {
+				inQuotes = false
+			}
+		case inURL:
+			if c == '>' {
+				inURL = false
+			}
+		case c == '"':
+			inQuotes = true
+		case c == '<':
+			inURL = true
+		case c == ',':
+			elements = append(elements, value[start:i])
+			start = i + 1
+		}
+	}
+	elements = append(elements, value[start:])
+	// Empty elements are allowed in comma separated header lists.
+	nonEmpty := elements[:0]
+	for _, element := range elements {
+		if strings.TrimSpace(element) != "" {
+			nonEmpty = append(nonEmpty, element)
+		}
+	}
+	return nonEmpty
+}
+
+func parseCallbackHeaderValue(value string) (Callback, error) {
+	var callback Callback
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "<") {
+		return callback, fmt.Errorf("%w: expected URL enclosed in angle brackets: %q", errInvalidCallback, value)
+	}
+	end := strings.Index(value, ">")
+	if end < 0 {
+		return callback, fmt.Errorf("%w: expected URL enclosed in angle brackets: %q", errInvalidCallback, value)
+	}
+	callback.URL = value[1:end]
+	if _, err := url.Parse(callback.URL); err != nil || callback.URL == "" {
+		return callback, fmt.Errorf("%w: invalid URL: %q", errInvalidCallback, callback.URL)
+	}
+	for _, param := range strings.Split(value[end+1:], ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		k, v, found := strings.Cut(param, "=")
+		if !found || strings.TrimSpace(k) != "events" {
+			return callback, fmt.Errorf("%w: unsupported parameter: %q", errInvalidCallback, param)
+		}
+		for _, event := range strings.Split(strings.Trim(strings.TrimSpace(v), `"`), ",") {
+			state := OperationState(strings.TrimSpace(event))
+			switch state {
+			case OperationStateSucceeded, OperationStateFailed, OperationStateCanceled:
+				callback.Events = append(callback.Events, state)
+			default:
+				return callback, fmt.Errorf("%w: invalid event: %q", errInvalidCallback, state)
+			}
+		}
+	}
+	return callback, nil
+}
+
+// callbacksFromRequest extracts all callbacks from the callback query params and Nexus-Callback headers of a start
+// operation request.
+func callbacksFromRequest(request *http.Request) ([]Callback, error) {
+	var callbacks []Callback
+	for _, u := range request.URL.Query()[queryCallbackURL] {
+		callbacks = append(callbacks, Callback{URL: u})
+	}
+	for _, value := range request.Header.Values(headerCallback) {
+		for _, element := range splitCallbackHeaderValue(value) {
+			callback, err := parseCallbackHeaderValue(element)
+			if err != nil {
+				return nil, err
+			}
+			callbacks = append(callbacks, callback)
+		}
+	}
+	return callbacks, nil
+}
@@ -0,0 +1,134 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackHeaderValue_RoundTrip(t *testing.T) {
+	cases := []Callback{
+		{URL: "http://example.com/callback?a=b;c=d"},
+		{URL: "http://example.com/failed", Events: []OperationState{OperationStateFailed, OperationStateCanceled}},
+	}
+	for _, c := range cases {
+		value, err := c.formatHeaderValue()
+		require.NoError(t, err)
+		parsed, err := parseCallbackHeaderValue(value)
+		require.NoError(t, err)
+		require.Equal(t, c, parsed)
+	}
+}
+
+func TestCallbackHeaderValue_Escaping(t *testing.T) {
+	value, err := Callback{URL: `http://example.com/callback?next=<a> "b"`}.formatHeaderValue()
+	require.NoError(t, err)
+	require.Equal(t, `<http://example.com/callback?next=%3Ca%3E%20%22b%22>`, value)
+	parsed, err := parseCallbackHeaderValue(value)
+	require.NoError(t, err)
+	u, err := url.Parse(parsed.URL)
+	require.NoError(t, err)
+	require.Equal(t, `<a> "b"`, u.Query().Get("next"))
+
+	for _, invalid := range []string{"", "http://[::1"} {
+		_, err := Callback{URL: invalid}.formatHeaderValue()
+		require.ErrorIs(t, err, errInvalidCallback, invalid)
+	}
+}
+
+func TestCallbacksFromRequest_CombinedHeader(t *testing.T) {
+	request := httptest.NewRequest("POST", "/foo", nil)
+	// As combined by a proxy from repeated headers.
+	request.Header.Set(headerCallback, `<http://example.com/a,b>; events="succeeded,failed", , <http://example.com/all>`)
+	callbacks, err := callbacksFromRequest(request)
+	require.NoError(t, err)
+	require.Equal(t, []Callback{
+		{URL: "http://example.com/a,b", Events: []OperationState{OperationStateSucceeded, OperationStateFailed}},
+		{URL: "http://example.com/all"},
+	}, callbacks)
+}
+
+func TestCallbackHeaderValue_Invalid(t *testing.T) {
+	cases := []string{
+		"http://example.com",
+		"<http://example.com",
+		"<>",
+		`<http://example.com>; events="running"`,
+		`<http://example.com>; foo=bar`,
+	}
+	for _, c := range cases {
+		_, err := parseCallbackHeaderValue(c)
+		require.ErrorIs(t, err, errInvalidCallback, c)
+	}
+}
+
+type callbacksEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *callbacksEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(request.Callbacks)
+}
+
+func TestStart_Callbacks(t *testing.T) {
+	ctx, client, teardown := setup(t, &callbacksEchoHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation:   "foo",
+		CallbackURL: "http://test/callback",
+		Callbacks: []Callback{
+			{URL: "http://test/succeeded", Events: []OperationState{OperationStateSucceeded}},
+			{URL: "http://test/unsuccessful", Events: []OperationState{OperationStateFailed, OperationStateCanceled}},
+		},
+	})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var callbacks []Callback
+	require.NoError(t, json.Unmarshal(body, &callbacks))
+	require.Equal(t, []Callback{
+		{URL: "http://test/callback"},
+		{URL: "http://test/succeeded", Events: []OperationState{OperationStateSucceeded}},
+		{URL: "http://test/unsuccessful", Events: []OperationState{OperationStateFailed, OperationStateCanceled}},
+	}, callbacks)
+}
+
+func TestNewCompletionHTTPRequests(t *testing.T) {
+	callbacks := []Callback{
+		{URL: "http://test/all"},
+		{URL: "http://test/succeeded", Events: []OperationState{OperationStateSucceeded}},
+		{URL: "http://test/unsuccessful", Events: []OperationState{OperationStateFailed, OperationStateCanceled}},
+	}
+
+	completion, err := NewOperationCompletionSuccessful("success")
+	require.NoError(t, err)
+	requests, err := NewCompletionHTTPRequests(context.Background(), callbacks, completion)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(requests))
+	require.Equal(t, "http://test/all", requests[0].URL.String())
+	require.Equal(t, "http://test/succeeded", requests[1].URL.String())
+	for _, request := range requests {
+		b, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`"success"`), b)
+		require.Equal(t, string(OperationStateSucceeded), request.Header.Get(headerOperationState))
+	}
+
+	requests, err = NewCompletionHTTPRequests(context.Background(), callbacks, &OperationCompletionUnsuccessful{
+		State:   OperationStateCanceled,
+		Failure: &Failure{Message: "canceled"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(requests))
+	require.Equal(t, "http://test/all", requests[0].URL.String())
+	require.Equal(t, "http://test/unsuccessful", requests[1].URL.String())
+}
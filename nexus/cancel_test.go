@@ -2,7 +2,9 @@ package nexus
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -60,3 +62,34 @@ func TestCancel_HandleFromClient(t *testing.T) {
 	err = handle.Cancel(ctx, CancelOperationOptions{})
 	require.NoError(t, err)
 }
+
+type syncCancelHandler struct {
+	UnimplementedHandler
+}
+
+func (h *syncCancelHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	if request.OperationID == "sync" {
+		return fmt.Errorf("done: %w", ErrOperationCanceledSynchronously)
+	}
+	return nil
+}
+
+func TestCancel_Synchronous(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &syncCancelHandler{}})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo/sync/cancel", nil))
+	require.Equal(t, http.StatusNoContent, writer.Code)
+	require.Empty(t, writer.Body.Bytes())
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo/async/cancel", nil))
+	require.Equal(t, http.StatusAccepted, writer.Code)
+
+	ctx, client, teardown := setup(t, &syncCancelHandler{})
+	defer teardown()
+	for _, id := range []string{"sync", "async"} {
+		handle, err := client.NewHandle("foo", id)
+		require.NoError(t, err)
+		require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	}
+}
@@ -0,0 +1,139 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Large enough to exceed the server's response buffer, forcing the headers and the first chunks out before the body
+// is complete.
+var chunkedResult = strings.Repeat("x", 64<<10)
+
+// chunkedResultHandler streams results of unknown length, failing mid-stream for the "reset" operation.
+type chunkedResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *chunkedResultHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	// MultiReader hides the length of the result.
+	body := io.MultiReader(strings.NewReader(chunkedResult))
+	if request.Operation == "reset" {
+		body = io.MultiReader(body, iotest.ErrReader(errors.New("source failed")))
+	}
+	// Cacheable, see TestChunkedResult_ResetWhileCaching.
+	return &OperationResponseSync{Header: http.Header{"Cache-Control": []string{"max-age=60"}}, Body: body}, nil
+}
+
+func (h *chunkedResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	response, err := h.StartOperation(ctx, &StartOperationRequest{Operation: request.OperationID})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*OperationResponseSync), nil
+}
+
+func TestChunkedResult(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &chunkedResultHandler{}}, ClientOptions{
+		ResponseBodyIdleTimeout: time.Second,
+		VerifyResultDigest:      true,
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "complete"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.Equal(t, int64(-1), response.ContentLength)
+	require.Equal(t, []string{"chunked"}, response.TransferEncoding)
+	b, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, chunkedResult, string(b))
+
+	handle, err := client.NewHandle("foo", "complete")
+	require.NoError(t, err)
+	// Chunked results are not resumable, read them in their entirety.
+	response, err = handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 3})
+	require.NoError(t, err)
+	b, err = io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, chunkedResult, string(b))
+}
+
+func TestChunkedResult_Reset(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &chunkedResultHandler{}}, ClientOptions{
+		ResponseBodyIdleTimeout: time.Second,
+		VerifyResultDigest:      true,
+	})
+	defer teardown()
+	tracker := &connectionTracker{}
+	ctx = tracker.context(ctx)
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "reset"})
+	require.NoError(t, err)
+	response := result.Successful
+	b, err := io.ReadAll(response.Body)
+	// A reset is not mistaken for the end of the result.
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.NotErrorIs(t, err, ErrResultDigestMismatch)
+	require.LessOrEqual(t, len(b), len(chunkedResult))
+	require.NoError(t, response.Body.Close())
+
+	handle, err := client.NewHandle("foo", "reset")
+	require.NoError(t, err)
+	response, err = handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 3})
+	require.NoError(t, err)
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.NoError(t, response.Body.Close())
+
+	// Reset connections are not reused.
+	require.Equal(t, []bool{false, false}, tracker.take())
+}
+
+func TestChunkedResult_ResetWhileCaching(t *testing.T) {
+	cache := &mapResultCache{results: map[string]*CachedResult{}}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &chunkedResultHandler{}}, ClientOptions{
+		ResultCache: cache,
+	})
+	defer teardown()
+
+	// Results are read in their entirety to be cached, resets fail the call.
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "reset"})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorContains(t, err, "failed to read response body")
+	require.Empty(t, cache.results)
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "complete"})
+	require.NoError(t, err)
+	b, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, chunkedResult, string(b))
+	require.Len(t, cache.results, 1)
+}
+
+func TestChunkedResponse_ResetBeforeDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(headerContentType, contentTypeJSON)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(writer, `{"id":"a/sync",`)
+		http.NewResponseController(writer).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorContains(t, err, "failed to read response body")
+}
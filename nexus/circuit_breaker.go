@@ -0,0 +1,113 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by client methods when the circuit breaker configured via
+// [ClientOptions.CircuitBreaker] is open for the target host.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreakerOptions configures a client side circuit breaker, see [ClientOptions.CircuitBreaker].
+type CircuitBreakerOptions struct {
+	// Number of consecutive failures (connection errors or 5xx responses) to a host after which the circuit opens.
+	// Defaults to 5.
+	FailureThreshold int
+	// Duration the circuit stays open before a single trial request is let through. If the trial succeeds the circuit
+	// closes, otherwise it opens for another cooldown period.
+	// Defaults to 10 seconds.
+	Cooldown time.Duration
+}
+
+type circuitState struct {
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInFlight       bool
+}
+
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+	mu      sync.Mutex
+	hosts   map[string]*circuitState
+}
+
+func newCircuitBreaker(options CircuitBreakerOptions) *circuitBreaker {
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 10 * time.Second
+	}
+	return &circuitBreaker{options: options, hosts: make(map[string]*circuitState)}
+}
+
+// wrap returns an HTTP caller that short-circuits requests with [ErrCircuitOpen] while the circuit for the request's
+// host is open.
+func (b *circuitBreaker) wrap(caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		host := request.URL.Host
+		trial, err := b.allow(host)
+		if err != nil {
+			return nil, err
+		}
+		response, err := caller(request)
+		if err != nil && errors.Is(err, context.Canceled) {
+			// Canceled by the caller (e.g. a losing hedged request), says nothing about the host's health.
+			b.release(host, trial)
+		} else {
+			b.record(host, trial, err != nil || response.StatusCode >= http.StatusInternalServerError)
+		}
+		return response, err
+	}
+}
+
+func (b *circuitBreaker) allow(host string) (trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.hosts[host]
+	if state == nil || state.openUntil.IsZero() {
+		return false, nil
+	}
+	if time.Now().Before(state.openUntil) || state.trialInFlight {
+		return false, ErrCircuitOpen
+	}
+	state.trialInFlight = true
+	return true, nil
+}
+
+func (b *circuitBreaker) release(host string, trial bool) {
+	if !trial {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.hosts[host].trialInFlight = false
+}
+
+func (b *circuitBreaker) record(host string, trial bool, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.hosts[host]
+	if state == nil {
+		if !failed {
+			return
+		}
+		state = &circuitState{}
+		b.hosts[host] = state
+	}
+	if trial {
+		state.trialInFlight = false
+	}
+	if !failed {
+		delete(b.hosts, host)
+		return
+	}
+	state.consecutiveFailures++
+	if trial || state.consecutiveFailures >= b.options.FailureThreshold {
+		state.openUntil = time.Now().Add(b.options.Cooldown)
+	}
+}
@@ -0,0 +1,88 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		CircuitBreaker: &CircuitBreakerOptions{FailureThreshold: 2, Cooldown: time.Millisecond * 100},
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			if !healthy.Load() {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    request,
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+	start := func() error {
+		result, err := client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+		if err == nil {
+			result.Successful.Body.Close()
+		}
+		return err
+	}
+
+	// Failures below the threshold are passed through.
+	require.ErrorContains(t, start(), "connection refused")
+	require.ErrorContains(t, start(), "connection refused")
+	require.Equal(t, int32(2), calls.Load())
+
+	// The circuit is open, requests are short-circuited.
+	require.ErrorIs(t, start(), ErrCircuitOpen)
+	require.Equal(t, int32(2), calls.Load())
+
+	// A failed trial request reopens the circuit.
+	time.Sleep(time.Millisecond * 150)
+	require.ErrorContains(t, start(), "connection refused")
+	require.ErrorIs(t, start(), ErrCircuitOpen)
+	require.Equal(t, int32(3), calls.Load())
+
+	// A successful trial request closes the circuit.
+	healthy.Store(true)
+	time.Sleep(time.Millisecond * 150)
+	require.NoError(t, start())
+	require.NoError(t, start())
+	require.Equal(t, int32(5), calls.Load())
+}
+
+func TestCircuitBreaker_ServerErrors(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1, Cooldown: time.Minute})
+	status := http.StatusInternalServerError
+	caller := breaker.wrap(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	})
+	request, err := http.NewRequest("GET", "http://a.example.com", nil)
+	require.NoError(t, err)
+	_, err = caller(request)
+	require.NoError(t, err)
+	_, err = caller(request)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// Circuits are tracked per host, 4xx responses are not failures.
+	status = http.StatusBadRequest
+	request, err = http.NewRequest("GET", "http://b.example.com", nil)
+	require.NoError(t, err)
+	_, err = caller(request)
+	require.NoError(t, err)
+	_, err = caller(request)
+	require.NoError(t, err)
+}
@@ -3,13 +3,19 @@ package nexus
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,9 +25,177 @@ import (
 type ClientOptions struct {
 	// Base URL of the service.
 	ServiceBaseURL string
+	// Base URLs of multiple instances of the service, for balancing requests across instances without an external load
+	// balancer. Mutually exclusive with ServiceBaseURL, instances must have distinct hosts.
+	//
+	// Requests are distributed round robin across instances, skipping instances ejected by passive health checking,
+	// see LoadBalancing. Handles returned from [Client.StartOperation] are pinned to the instance the operation was
+	// started on. Requests of handles created with [Client.NewHandle] are distributed across instances, which is only
+	// suitable for instances sharing their state.
+	ServiceBaseURLs []string
+	// Configures passive health checking of ServiceBaseURLs. Optional.
+	LoadBalancing LoadBalancingOptions
+	// Name of the service to target. Optional.
+	// When set, operation URLs are prefixed with a service segment, e.g. {ServiceBaseURL}/{service}/{operation}, as
+	// expected by handlers created with [HandlerOptions.ServiceRouting] enabled.
+	// May be overridden per call via StartOperationOptions and ExecuteOperationOptions.
+	Service string
 	// A function for making HTTP requests.
 	// Defaults to [http.DefaultClient.Do].
 	HTTPCaller func(*http.Request) (*http.Response, error)
+	// Max duration to wait for a connection to be established, including DNS resolution. Optional.
+	//
+	// When set, the client uses a dedicated transport cloned from [http.DefaultTransport] with this dial timeout.
+	// Cannot be combined with a custom HTTPCaller, configure the dialer of the custom caller's transport instead.
+	//
+	// The context passed to the client's methods continues to bound the overall duration of a request. Connect timeout
+	// only applies to establishing new connections and does not affect long poll wait times (see
+	// [OperationHandle.GetResult]), making it possible to fail fast on unreachable hosts while allowing long waits for
+	// operation results.
+	ConnectTimeout time.Duration
+	// Invoked by the dialer of the transport created for ConnectTimeout before connecting, see
+	// [net.Dialer.ControlContext]. Allows tests to simulate unreachable hosts.
+	dialControl func(ctx context.Context, network, address string, conn syscall.RawConn) error
+	// If set, the client computes a SHA-256 digest of start operation request bodies and sends it in the Digest header
+	// for the handler to verify. Note that this requires reading the request body into memory before sending it.
+	SendBodyDigest bool
+	// If set, the bodies of successful start operation and get operation result responses are verified against the
+	// SHA-256 digest sent by handlers with [HandlerOptions.SendResultDigest] enabled, guarding against corruption by
+	// intermediaries. The digest is computed as the body is read, reading the body to EOF fails with
+	// [ErrResultDigestMismatch] on mismatch. Responses without a digest, and resumed downloads, see
+	// [GetOperationResultOptions.MaxResumes], are not verified.
+	VerifyResultDigest bool
+	// A function for transforming request URLs, e.g. for adding credentials or routing hints to the URL query. Optional.
+	// Invoked after the client constructs an operation URL and before issuing a request for any of the client's and
+	// [OperationHandle]'s methods.
+	URLTransformer func(*url.URL)
+	// If set, idempotent requests for getting operation info and results (excluding long polls) are hedged: when no
+	// response is received within this delay, a second identical request is issued in parallel and whichever
+	// successful response arrives first is used. The other request is canceled. Responses with a 5xx status code only
+	// win if all requests fail, in which case the last one is used. Optional.
+	//
+	// Hedging reduces tail latency against slow backends at the cost of additional load.
+	HedgeDelay time.Duration
+	// Codecs for decoding failures in error responses, selected by the media type of the response's Content-Type
+	// header. Optional.
+	//
+	// JSON failures are always supported, use this for handlers configured with a custom [HandlerOptions.FailureCodec].
+	FailureCodecs []FailureCodec
+	// Max duration to wait for data while reading the body of a successful operation response, returned from
+	// [Client.StartOperation] and [OperationHandle.GetResult]. Optional.
+	//
+	// When a single read of the body does not complete in time, the body is closed and the read fails with
+	// [ErrBodyIdleTimeout]. This protects against servers stalling mid-body independently of the overall context
+	// deadline, which may be generous for long running operations.
+	ResponseBodyIdleTimeout time.Duration
+	// If set, the client tracks consecutive failures per host and, once a threshold is reached, fails subsequent
+	// requests to that host with [ErrCircuitOpen] without issuing them for a cooldown period. Optional.
+	//
+	// Protects both the caller and the handler during outages, requests failed with [ErrCircuitOpen] may be retried
+	// after the cooldown.
+	CircuitBreaker *CircuitBreakerOptions
+	// If set, retries issued by the client, i.e. hedged requests, see HedgeDelay, and resumed result downloads, see
+	// [GetOperationResultOptions.MaxResumes], are limited by a budget shared by all of the client's calls. Optional.
+	//
+	// Prevents retries from amplifying the load on a broadly failing service: the budget is replenished by successful
+	// requests, so retries are throttled globally once most requests fail. Requests that aren't retried due to an
+	// exhausted budget fail with their original error, resumed downloads fail with [ErrRetryBudgetExhausted].
+	RetryBudget *RetryBudgetOptions
+	// A cache for the results of operations that complete synchronously. Optional.
+	//
+	// Results are keyed by service, operation, request header, and serialized input, [Client.StartOperation] and
+	// [Client.ExecuteOperation] calls with the same input and header, e.g. the same Accept and Authorization fields,
+	// are served from the cache without issuing a request. The request ID is not part of the key. Only set it for
+	// clients calling idempotent operations with deterministic results. Only successful, complete results are cached,
+	// respecting the Cache-Control header of the response: results marked no-store are not cached, max-age sets the
+	// expiry, and no-cache results are revalidated on every call. Results without max-age, no-cache, or an ETag are not
+	// cached. Expired results with an ETag are revalidated with an If-None-Match request, a 304 Not Modified response
+	// serves the cached result.
+	//
+	// Note that enabling the cache reads request bodies and cached result bodies into memory.
+	ResultCache ResultCache
+	// If set, start operation requests that time out at the transport level, e.g. waiting for response headers, are
+	// followed by a lookup of the operation by the request's ID, see [Client.LookupOperation], since the handler may
+	// have started the operation before the response was lost. A found operation is returned as pending, avoiding
+	// duplicate work when the caller would otherwise retry the request. Otherwise the timeout error is returned, wrapping
+	// [ErrOperationNotFound] if the request did not run an operation, in which case it is safe to retry, or
+	// [ErrOperationCompletedSynchronously] if the request completed the operation synchronously, in which case the
+	// result is lost and retrying would run the operation again. Retrying on ErrOperationNotFound relies on the handler
+	// reporting synchronous completions, see [Handler.LookupOperation].
+	//
+	// Requires a handler that implements [Handler.LookupOperation]. Timeouts of the call's context are not recovered.
+	RecoverStartTimeouts bool
+	// If positive, start operation requests with bodies larger than this many bytes are sent with an
+	// "Expect: 100-continue" header, allowing the handler to reject the request (e.g. due to failed authorization or a
+	// size limit) before the body is transferred. Only applies to bodies of known length, e.g. [bytes.Reader],
+	// [bytes.Buffer], and [strings.Reader] bodies. Optional.
+	//
+	// The HTTP transport must be configured to wait for the handler's interim response, [http.DefaultTransport] waits
+	// for up to a second. Custom transports must set [http.Transport.ExpectContinueTimeout]. Handlers served by
+	// [http.Server] reply with 100 Continue once the body is first read, requests rejected without reading the body
+	// never have it sent.
+	ExpectContinueThreshold int64
+	// Max length of request URLs, including the query. Requests with longer URLs, e.g. due to a very long operation
+	// name or ID, fail with [ErrURLTooLong] before being sent rather than running into obscure failures in servers or
+	// proxies along the way. Optional.
+	MaxURLLength int
+	// A function invoked for each request issued by the client, e.g. for debug logging of wire formats. Receives a
+	// copy of the request body capped at ObservedBodyLimit, streaming bodies are observed as they are sent. Optional.
+	OnRequest func(ObservedRequest)
+	// A function invoked for each response received by the client once its body is closed. Receives a copy of the
+	// body bytes read, capped at ObservedBodyLimit. Optional.
+	//
+	// Note that the hook is not invoked for responses whose body is never closed.
+	OnResponse func(ObservedResponse)
+	// Max number of body bytes to capture for OnRequest and OnResponse. Defaults to 4096.
+	ObservedBodyLimit int
+	// A function for classifying start operation responses as synchronous or asynchronous, e.g. for servers behind
+	// intermediaries that rewrite status codes. Responses classified as [StartResponseClassUnknown] are classified by
+	// status code: 200 for synchronous and 201 for asynchronous responses. Optional.
+	//
+	// See [ClassifyByOperationIDHeader] for a classifier that recognizes asynchronous responses by the presence of the
+	// Nexus-Operation-Id header.
+	ResponseClassifier func(*http.Response) StartResponseClass
+	// A handler for per call metrics, e.g. for reporting the outcome and duration of start operation calls. Optional.
+	MetricsHandler ClientMetricsHandler
+	// A function returning the default timeout for calls to the given operation, e.g. for configuring a short timeout
+	// for quick operations and a long one for report generation centrally. Applied as a context deadline to start
+	// operation, get result, get info, and cancel calls when the caller's context has no deadline, a caller supplied
+	// deadline always takes precedence. Zero or negative values mean no timeout. Optional.
+	//
+	// For calls that return a response, the timeout covers reading the response body. Event streams are long lived and
+	// not subject to this timeout, bound them with the context passed to [OperationHandle.StreamEvents] instead.
+	OperationTimeout func(operation string) time.Duration
+	// A structured logger, e.g. for warnings about responses with a Nexus-Request-Id header that does not match the
+	// request's. Defaults to slog.Default().
+	Logger *slog.Logger
+	// A function for translating errors into application errors, e.g. for normalizing failures returned by different
+	// servers into domain errors. Invoked with every error the client is about to return from a call, including
+	// transport errors, [UnexpectedResponseError]s, and [UnsuccessfulOperationError]s. If it returns nil, the original
+	// error is returned. Optional.
+	ErrorMapper func(error) error
+}
+
+// StartResponseClass is the classification of a start operation response, see [ClientOptions.ResponseClassifier].
+type StartResponseClass int
+
+const (
+	// The response is classified by its status code.
+	StartResponseClassUnknown StartResponseClass = iota
+	// The response carries a synchronous result.
+	StartResponseClassSync
+	// The response carries the information of a started asynchronous operation.
+	StartResponseClassAsync
+)
+
+// ClassifyByOperationIDHeader is a [ClientOptions.ResponseClassifier] that classifies successful start operation
+// responses carrying a Nexus-Operation-Id header as asynchronous, regardless of their status code. Such responses must
+// carry the started operation's information in their body. Other responses are classified by status code.
+func ClassifyByOperationIDHeader(response *http.Response) StartResponseClass {
+	if response.StatusCode >= 200 && response.StatusCode < 300 && response.Header.Get(headerOperationID) != "" {
+		return StartResponseClassAsync
+	}
+	return StartResponseClassUnknown
 }
 
 // User-Agent header set on HTTP requests.
@@ -35,12 +209,23 @@ var errEmptyServiceBaseURL = errors.New("empty serviceBaseURL")
 // Error indicating a non HTTP URL was used to create a [Client].
 var errInvalidURLScheme = errors.New("invalid URL scheme")
 
+// Error indicating both ConnectTimeout and a custom HTTPCaller were used to create a [Client].
+var errConnectTimeoutWithHTTPCaller = errors.New("ConnectTimeout cannot be combined with a custom HTTPCaller")
+
 var errEmptyOperationName = errors.New("empty operation name")
 
 var errEmptyOperationID = errors.New("empty operation ID")
 
 var errOperationWaitTimeout = errors.New("operation wait timeout")
 
+// ErrURLTooLong indicates that a request URL exceeds [ClientOptions.MaxURLLength].
+var ErrURLTooLong = errors.New("URL too long")
+
+// ErrMalformedResponse indicates that the server's response violates the Nexus protocol, e.g. a failed operation
+// response without a valid Nexus-Operation-State header. Errors matching ErrMalformedResponse via [errors.Is] are of
+// type *[UnexpectedResponseError] and carry the offending response.
+var ErrMalformedResponse = errors.New("malformed response")
+
 // Error that indicates a client encountered something unexpected in the server's response.
 type UnexpectedResponseError struct {
 	// Error message.
@@ -49,6 +234,9 @@ type UnexpectedResponseError struct {
 	Response *http.Response
 	// Optional failure that may have been emedded in the HTTP response body.
 	Failure *Failure
+	// Optional error classifying this error, e.g. [ErrMalformedResponse], [ErrOperationConflict], or a
+	// *[ValidationError] parsed from Failure.
+	cause error
 }
 
 // Error implements the error interface.
@@ -56,19 +244,42 @@ func (e *UnexpectedResponseError) Error() string {
 	return e.Message
 }
 
-func newUnexpectedResponseError(message string, response *http.Response, body []byte) error {
+// Unwrap returns the error classifying this error, if any.
+func (e *UnexpectedResponseError) Unwrap() error {
+	return e.cause
+}
+
+func (c *Client) newUnexpectedResponseError(message string, response *http.Response, body []byte) error {
 	var failure *Failure
-	if isContentTypeJSON(response.Header) {
-		if err := json.Unmarshal(body, &failure); err == nil && failure.Message != "" {
-			message += ": " + failure.Message
+	if codec, ok := c.failureCodecForResponse(response); ok {
+		var decoded Failure
+		if err := codec.Unmarshal(body, &decoded); err == nil {
+			failure = &decoded
+			if failure.Message != "" {
+				message += ": " + failure.Message
+			}
 		}
 	}
 
-	return &UnexpectedResponseError{
+	responseError := &UnexpectedResponseError{
 		Message:  message,
 		Response: response,
 		Failure:  failure,
 	}
+	if validationError := validationErrorFromFailure(failure); validationError != nil {
+		responseError.cause = validationError
+	} else if quotaError := quotaExceededErrorFromResponse(response); quotaError != nil {
+		responseError.cause = quotaError
+	} else if response.StatusCode == http.StatusConflict {
+		responseError.cause = ErrOperationConflict
+	}
+	return responseError
+}
+
+func (c *Client) newMalformedResponseError(message string, response *http.Response, body []byte) error {
+	err := c.newUnexpectedResponseError(message, response, body).(*UnexpectedResponseError)
+	err.cause = ErrMalformedResponse
+	return err
 }
 
 // A Client makes Nexus service requests as defined in the [Nexus HTTP API].
@@ -85,43 +296,117 @@ type Client struct {
 	// The options this client was created with after applying defaults.
 	options        ClientOptions
 	serviceBaseURL *url.URL
+	// Set when ServiceBaseURLs is provided.
+	balancer *balancer
+	// Set when RetryBudget is provided.
+	retryBudget *retryBudget
 }
 
 // NewClient creates a new [Client] from provided [ClientOptions].
 // Only BaseServiceURL is required.
 func NewClient(options ClientOptions) (*Client, error) {
+	if options.ConnectTimeout > 0 {
+		if options.HTTPCaller != nil {
+			return nil, errConnectTimeoutWithHTTPCaller
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{
+			Timeout:        options.ConnectTimeout,
+			KeepAlive:      30 * time.Second,
+			ControlContext: options.dialControl,
+		}).DialContext
+		options.HTTPCaller = (&http.Client{Transport: transport}).Do
+	}
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
 	if options.HTTPCaller == nil {
 		options.HTTPCaller = http.DefaultClient.Do
 	}
-	if options.ServiceBaseURL == "" {
+	client := &Client{}
+	if len(options.ServiceBaseURLs) > 0 {
+		if options.ServiceBaseURL != "" {
+			return nil, errServiceBaseURLsConflict
+		}
+		baseURLs := make([]*url.URL, len(options.ServiceBaseURLs))
+		for i, baseURL := range options.ServiceBaseURLs {
+			var err error
+			if baseURLs[i], err = parseServiceBaseURL(baseURL); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if client.balancer, err = newBalancer(baseURLs, options.LoadBalancing); err != nil {
+			return nil, err
+		}
+		client.serviceBaseURL = baseURLs[0]
+		options.HTTPCaller = client.balancer.wrap(options.HTTPCaller)
+	} else {
+		var err error
+		if client.serviceBaseURL, err = parseServiceBaseURL(options.ServiceBaseURL); err != nil {
+			return nil, err
+		}
+	}
+	if options.RetryBudget != nil {
+		client.retryBudget = newRetryBudget(*options.RetryBudget)
+		options.HTTPCaller = client.retryBudget.wrap(options.HTTPCaller)
+	}
+	if options.CircuitBreaker != nil {
+		options.HTTPCaller = newCircuitBreaker(*options.CircuitBreaker).wrap(options.HTTPCaller)
+	}
+	options.HTTPCaller = warnOnDeprecation(options.Logger, options.HTTPCaller)
+	if options.OnRequest != nil || options.OnResponse != nil {
+		options.HTTPCaller = observeHTTPCaller(options, options.HTTPCaller)
+	}
+	if options.MetricsHandler != nil {
+		options.HTTPCaller = countRequests(options.HTTPCaller)
+	}
+	client.options = options
+	return client, nil
+}
+
+func parseServiceBaseURL(baseURL string) (*url.URL, error) {
+	if baseURL == "" {
 		return nil, errEmptyServiceBaseURL
 	}
-	var serviceBaseURL *url.URL
-	var err error
-	serviceBaseURL, err = url.Parse(options.ServiceBaseURL)
+	serviceBaseURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
 	if serviceBaseURL.Scheme != "http" && serviceBaseURL.Scheme != "https" {
 		return nil, errInvalidURLScheme
 	}
-
-	return &Client{
-		options:        options,
-		serviceBaseURL: serviceBaseURL,
-	}, nil
+	return serviceBaseURL, nil
 }
 
 // StartOperationOptions is input for [Client.StartOperation].
 type StartOperationOptions struct {
+	// Name of the service hosting the operation. Optional, defaults to [ClientOptions.Service].
+	Service string
 	// Name of the operation to start.
 	Operation string
 	// Callback URL to provide to the handle for receiving async operation completions. Optional.
 	// Implement a [CompletionHandler] and expose it as an HTTP handler to handle async completions.
 	CallbackURL string
+	// Additional callbacks to provide to the handler for receiving async operation completions. Optional.
+	// Each callback may be restricted to a subset of completion states, e.g. for wiring different downstreams for
+	// successful and unsuccessful outcomes.
+	Callbacks []Callback
 	// Request ID that may be used by the server handler to dedupe this start request.
 	// By default a v4 UUID will be generated by the client.
 	RequestID string
+	// Value for the standard Idempotency-Key header, for handlers integrating with systems that dedupe requests by this
+	// header. Optional, independent of RequestID.
+	IdempotencyKey string
+	// Priority hint for the handler, e.g. for scheduling work in a multi-tenant service. Optional.
+	// Interpretation of the value is up to the handler, zero means no hint is sent.
+	Priority int
+	// Time to start the operation at, for operations that should begin in the future, e.g. delayed jobs. Optional,
+	// zero means the operation should start immediately. Scheduling the operation is up to the handler.
+	StartAfter time.Time
+	// Hints to the handler that the caller intends to wait for the operation's result. Set by
+	// [Client.ExecuteOperation]. Optional.
+	ClientWillPoll bool
 	// Header to attach to the HTTP request. Optional.
 	Header http.Header
 	// Body of the operation request.
@@ -157,6 +442,18 @@ type StartOperationResult struct {
 	// Set when the handler indicates that it started an asynchronous operation.
 	// The attached handle can be used to perform actions such as cancel the operation or get its result.
 	Pending *OperationHandle
+	// Set when the handler accepted a fire-and-forget operation that has no result to retrieve, see
+	// [OperationResponseAccepted]. Callers should not poll for the operation's result.
+	Accepted *OperationAccepted
+	// Time the handler spent processing the start request, as reported by the handler, see [ServerProcessingTime].
+	// Zero if not reported.
+	ServerProcessingTime time.Duration
+}
+
+// OperationAccepted describes an operation accepted by a handler without a result to retrieve.
+type OperationAccepted struct {
+	// ID of the operation, empty if the handler did not provide one.
+	ID string
 }
 
 // StartOperation calls the configured Nexus endpoint to start an operation.
@@ -171,11 +468,29 @@ type StartOperationResult struct {
 //     [OperationHandle] will be returned as StartOperationResult.Pending, which can be used to perform actions such
 //     as getting its result.
 //
-//  3. The operation was unsuccessful. The returned result will be nil and error will be an
+//  3. The handler accepted a fire-and-forget operation that has no result to retrieve. An [OperationAccepted] will be
+//     returned as StartOperationResult.Accepted.
+//
+//  4. The operation was unsuccessful. The returned result will be nil and error will be an
 //     [UnsuccessfulOperationError].
 //
-//  4. Any other failure.
+//  5. Any other failure.
 func (c *Client) StartOperation(ctx context.Context, options StartOperationOptions) (*StartOperationResult, error) {
+	ctx, cancel := c.withOperationTimeout(ctx, options.Operation)
+	ctx, record := c.recordCall(ctx, options.Operation, ClientMethodStartOperation)
+	result, err := c.startOperation(ctx, options)
+	outcome := ClientCallOutcomeStarted
+	if result != nil && result.Successful != nil {
+		outcome = ClientCallOutcomeSuccessful
+		cancelOnClose(result.Successful, cancel)
+	} else {
+		cancel()
+	}
+	record(outcome, err)
+	return result, c.mapError(err)
+}
+
+func (c *Client) startOperation(ctx context.Context, options StartOperationOptions) (*StartOperationResult, error) {
 	if closer, ok := options.Body.(io.Closer); ok {
 		// Close the request body in case we error before sending the HTTP request (which may double close but that's fine since we ignore the error).
 		defer closer.Close()
@@ -183,14 +498,47 @@ func (c *Client) StartOperation(ctx context.Context, options StartOperationOptio
 	if options.Operation == "" {
 		return nil, errEmptyOperationName
 	}
-	url := c.serviceBaseURL.JoinPath(url.PathEscape(options.Operation))
+	var input []byte
+	if (c.options.SendBodyDigest || c.options.ResultCache != nil) && options.Body != nil {
+		var err error
+		if input, err = io.ReadAll(options.Body); err != nil {
+			return nil, err
+		}
+		options.Body = bytes.NewReader(input)
+	}
+	var digest string
+	if c.options.SendBodyDigest && options.Body != nil {
+		sum := sha256.Sum256(input)
+		digest = formatSHA256Digest(sum[:])
+	}
+	if options.Service == "" {
+		options.Service = c.options.Service
+	}
+	var cacheKey string
+	var cached *CachedResult
+	if c.options.ResultCache != nil {
+		cacheKey = resultCacheKey(options.Service, options.Operation, options.Header, input)
+		if result, ok := c.options.ResultCache.Get(ctx, cacheKey); ok {
+			if result.fresh(time.Now()) {
+				return &StartOperationResult{Successful: result.response()}, nil
+			}
+			if result.ETag != "" {
+				cached = result
+			}
+		}
+	}
+	baseURL := c.baseURL()
+	url := joinOperationURL(baseURL, options.Service, options.Operation)
 
 	if options.CallbackURL != "" {
 		q := url.Query()
 		q.Set(queryCallbackURL, options.CallbackURL)
 		url.RawQuery = q.Encode()
 	}
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), options.Body)
+	if err := c.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := c.newRequest(ctx, options.Operation, "POST", url.String(), options.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -198,6 +546,13 @@ func (c *Client) StartOperation(ctx context.Context, options StartOperationOptio
 	if options.Header != nil {
 		request.Header = options.Header.Clone()
 	}
+	for _, callback := range options.Callbacks {
+		value, err := callback.formatHeaderValue()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Add(headerCallback, value)
+	}
 	if options.RequestID == "" {
 		requestIDFromHeader := options.Header.Get(headerRequestID)
 		if requestIDFromHeader != "" {
@@ -207,16 +562,63 @@ func (c *Client) StartOperation(ctx context.Context, options StartOperationOptio
 		}
 	}
 	request.Header.Set(headerRequestID, options.RequestID)
+	if c.options.ExpectContinueThreshold > 0 && request.ContentLength > c.options.ExpectContinueThreshold {
+		request.Header.Set("Expect", "100-continue")
+	}
+	if options.IdempotencyKey != "" {
+		request.Header.Set(headerIdempotencyKey, options.IdempotencyKey)
+	}
+	if options.Priority != 0 {
+		request.Header.Set(headerPriority, strconv.Itoa(options.Priority))
+	}
+	if options.ClientWillPoll {
+		request.Header.Set(headerClientWillPoll, "true")
+	}
+	if !options.StartAfter.IsZero() {
+		request.Header.Set(headerStartAfter, options.StartAfter.UTC().Format(time.RFC3339Nano))
+	}
 	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
+	if digest != "" {
+		request.Header.Set(headerDigest, digest)
+	}
+	if cached != nil {
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
 
 	response, err := c.options.HTTPCaller(request)
 	if err != nil {
+		if c.options.RecoverStartTimeouts && isTransportTimeout(ctx, err) {
+			return c.recoverStart(ctx, baseURL, options, err)
+		}
 		return nil, err
 	}
+	if cached != nil && response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		c.revalidateCachedResult(ctx, cacheKey, cached, response)
+		return &StartOperationResult{Successful: cached.response()}, nil
+	}
+	if echoed := response.Header.Get(headerRequestID); echoed != "" && echoed != options.RequestID {
+		// Likely a proxy mixing up responses, the response may not correspond to this request.
+		c.options.Logger.Warn("response request ID does not match the request", "operation", options.Operation, "requestID", options.RequestID, "responseRequestID", echoed)
+	}
+	serverProcessingTime, _ := ServerProcessingTime(response)
+	class := StartResponseClassUnknown
+	if c.options.ResponseClassifier != nil {
+		class = c.options.ResponseClassifier(response)
+	}
 	// Do not close response body here to allow successful result to read it.
-	if response.StatusCode == http.StatusOK {
+	if class == StartResponseClassSync || class == StartResponseClassUnknown && response.StatusCode == http.StatusOK {
+		if c.options.ResultCache != nil && response.StatusCode == http.StatusOK {
+			if err := c.cacheResult(ctx, cacheKey, response); err != nil {
+				return nil, err
+			}
+		}
+		c.applyResponseBodyIdleTimeout(response)
+		c.applyResultDigestVerification(response)
 		return &StartOperationResult{
-			Successful: response,
+			Successful:           response,
+			ServerProcessingTime: serverProcessingTime,
 		}, nil
 	}
 
@@ -226,29 +628,60 @@ func (c *Client) StartOperation(ctx context.Context, options StartOperationOptio
 		return nil, err
 	}
 
-	switch response.StatusCode {
+	statusCode := response.StatusCode
+	if class == StartResponseClassAsync {
+		statusCode = http.StatusCreated
+	}
+	switch statusCode {
 	case http.StatusCreated:
-		info, err := operationInfoFromResponse(response, body)
+		info, err := c.operationInfoFromResponse(response, body)
 		if err != nil {
 			return nil, err
 		}
 		if info.State != OperationStateRunning {
-			return nil, newUnexpectedResponseError(fmt.Sprintf("invalid operation state in response info: %q", info.State), response, body)
+			return nil, c.newUnexpectedResponseError(fmt.Sprintf("invalid operation state in response info: %q", info.State), response, body)
+		}
+		handle := &OperationHandle{
+			Service:   options.Service,
+			Operation: options.Operation,
+			ID:        info.ID,
+			client:    c,
+		}
+		if c.balancer != nil {
+			handle.baseURL = baseURL
+		}
+		if info.Deadline != nil {
+			handle.deadline = *info.Deadline
+		}
+		if location := response.Header.Get(headerLocation); location != "" {
+			if locationURL, err := request.URL.Parse(location); err == nil {
+				handle.location = locationURL
+			}
+		}
+		return &StartOperationResult{
+			Pending:              handle,
+			ServerProcessingTime: serverProcessingTime,
+		}, nil
+	case http.StatusAccepted:
+		accepted := &OperationAccepted{}
+		if len(body) > 0 {
+			info, err := c.operationInfoFromResponse(response, body)
+			if err != nil {
+				return nil, err
+			}
+			accepted.ID = info.ID
 		}
 		return &StartOperationResult{
-			Pending: &OperationHandle{
-				Operation: options.Operation,
-				ID:        info.ID,
-				client:    c,
-			},
+			Accepted:             accepted,
+			ServerProcessingTime: serverProcessingTime,
 		}, nil
 	case statusOperationFailed:
-		state, err := getUnsuccessfulStateFromHeader(response, body)
+		state, err := c.getUnsuccessfulStateFromHeader(response, body)
 		if err != nil {
 			return nil, err
 		}
 
-		failure, err := failureFromResponse(response, body)
+		failure, err := c.failureFromResponse(response, body)
 		if err != nil {
 			return nil, err
 		}
@@ -258,21 +691,84 @@ func (c *Client) StartOperation(ctx context.Context, options StartOperationOptio
 			Failure: failure,
 		}
 	default:
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, c.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
 	}
 }
 
+// StartOperationsPipelined starts multiple operations, reusing connections across the start requests. The first
+// request is sent on its own to establish a connection, the remaining requests are then sent concurrently.
+//
+// With HTTP/2 transports, the concurrent requests are multiplexed over the established connection. Go's HTTP/1.1 client
+// does not support pipelining, i.e. sending a request before the previous response was received, each concurrent
+// request occupies a pooled connection, dialing new ones as needed. Set MaxConnsPerHost on the transport of
+// [ClientOptions.HTTPCaller] to bound the number of connections, requests then wait for a connection to be released.
+//
+// Successful response bodies are read into memory so the connection is released and reused for the next request,
+// callers should still close them. Request bodies are streamed as with [Client.StartOperation].
+//
+// Returns a result and an error slice, each of the same length as options, where index i of both corresponds to
+// options[i] and one and only one of results[i] or errs[i] is non-nil.
+func (c *Client) StartOperationsPipelined(ctx context.Context, options []StartOperationOptions) (results []*StartOperationResult, errs []error) {
+	results = make([]*StartOperationResult, len(options))
+	errs = make([]error, len(options))
+	start := func(i int) {
+		if err := ctx.Err(); err != nil {
+			if closer, ok := options[i].Body.(io.Closer); ok {
+				closer.Close()
+			}
+			errs[i] = err
+			return
+		}
+		result, err := c.StartOperation(ctx, options[i])
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		if result.Successful != nil {
+			if _, err := readAndReplaceBody(result.Successful); err != nil {
+				errs[i] = err
+				return
+			}
+		}
+		results[i] = result
+	}
+	if len(options) == 0 {
+		return results, errs
+	}
+	start(0)
+	var wg sync.WaitGroup
+	for i := 1; i < len(options); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start(i)
+		}(i)
+	}
+	wg.Wait()
+	return results, errs
+}
+
 // ExecuteOperationOptions is input for [Client.ExecuteOperation].
 type ExecuteOperationOptions struct {
+	// Name of the service hosting the operation. Optional, defaults to [ClientOptions.Service].
+	Service string
 	// Name of the operation to start.
 	Operation string
 	// Callback URL to provide to the handle for receiving async operation completions. Optional.
 	// Even though Client.ExecuteOperation waits for operation completion, some application may want to set this
 	// callback as a fallback mechanism.
 	CallbackURL string
+	// Additional callbacks to provide to the handler for receiving async operation completions. Optional.
+	Callbacks []Callback
 	// Request ID that may be used by the server handler to dedupe this start request.
 	// By default a v4 UUID will be generated by the client.
 	RequestID string
+	// Value for the Idempotency-Key header. Optional, see [StartOperationOptions.IdempotencyKey].
+	IdempotencyKey string
+	// Priority hint for the handler. Optional, see [StartOperationOptions.Priority].
+	Priority int
+	// Time to start the operation at. Optional, see [StartOperationOptions.StartAfter].
+	StartAfter time.Time
 	// Body of the operation request.
 	// If it is an [io.Closer], the body is guaranteed to be closed in Client.ExecuteOperation.
 	Body io.Reader
@@ -305,11 +801,17 @@ func NewExecuteOperationOptions(operation string, v any) (options ExecuteOperati
 
 func (o *ExecuteOperationOptions) intoStartOptions() StartOperationOptions {
 	return StartOperationOptions{
-		Operation:   o.Operation,
-		CallbackURL: o.CallbackURL,
-		RequestID:   o.RequestID,
-		Header:      o.Header,
-		Body:        o.Body,
+		Service:        o.Service,
+		Operation:      o.Operation,
+		CallbackURL:    o.CallbackURL,
+		Callbacks:      o.Callbacks,
+		RequestID:      o.RequestID,
+		Priority:       o.Priority,
+		StartAfter:     o.StartAfter,
+		ClientWillPoll: true,
+		IdempotencyKey: o.IdempotencyKey,
+		Header:         o.Header,
+		Body:           o.Body,
 	}
 }
 
@@ -339,6 +841,8 @@ func (o *ExecuteOperationOptions) intoGetResultOptions() (options GetOperationRe
 // Note that the wait period is enforced by the server and may not be respected if the server is misbehaving. Set the
 // context deadline to the max allowed wait period to ensure this call returns in a timely fashion.
 //
+// Fails with [ErrOperationResultUnavailable] if the handler accepted the operation without a result to retrieve.
+//
 // ⚠️ If this method completes successfully, the returned response's body must be read in its entirety and closed to
 // free up the underlying connection.
 func (c *Client) ExecuteOperation(ctx context.Context, request ExecuteOperationOptions) (*http.Response, error) {
@@ -349,6 +853,9 @@ func (c *Client) ExecuteOperation(ctx context.Context, request ExecuteOperationO
 	if result.Successful != nil {
 		return result.Successful, nil
 	}
+	if result.Accepted != nil {
+		return nil, c.mapError(ErrOperationResultUnavailable)
+	}
 	handle := result.Pending
 	return handle.GetResult(ctx, request.intoGetResultOptions())
 }
@@ -369,25 +876,70 @@ func (c *Client) NewHandle(operation string, operationID string) (*OperationHand
 	}
 	return &OperationHandle{
 		client:    c,
+		Service:   c.options.Service,
 		Operation: operation,
 		ID:        operationID,
 	}, nil
 }
 
+// baseURL returns the base URL for a request that is not pinned to a service instance.
+func (c *Client) baseURL() *url.URL {
+	if c.balancer != nil {
+		return c.balancer.pick()
+	}
+	return c.serviceBaseURL
+}
+
+// operationURL constructs the URL for an operation in the given (optional) service, escaping each path element.
+func (c *Client) operationURL(service, operation string, elem ...string) *url.URL {
+	return joinOperationURL(c.baseURL(), service, operation, elem...)
+}
+
+// joinOperationURL constructs the URL for an operation relative to the given base URL.
+func joinOperationURL(baseURL *url.URL, service, operation string, elem ...string) *url.URL {
+	var elems []string
+	if service != "" {
+		elems = append(elems, url.PathEscape(service))
+	}
+	elems = append(elems, url.PathEscape(operation))
+	for _, e := range elem {
+		elems = append(elems, url.PathEscape(e))
+	}
+	return baseURL.JoinPath(elems...)
+}
+
+// transformURL applies the configured URLTransformer, if any, to the given URL and validates its length against
+// MaxURLLength.
+func (c *Client) transformURL(u *url.URL) error {
+	if c.options.URLTransformer != nil {
+		c.options.URLTransformer(u)
+	}
+	if c.options.MaxURLLength > 0 {
+		if length := len(u.String()); length > c.options.MaxURLLength {
+			return fmt.Errorf("%w: %d exceeds max length of %d", ErrURLTooLong, length, c.options.MaxURLLength)
+		}
+	}
+	return nil
+}
+
 // readAndReplaceBody reads the response body in its entirety and closes it, and then replaces the original response
 // body with an in-memory buffer.
 // The body is replaced even when there was an error reading the entire body.
+// Errors are wrapped, a body cut short by a connection reset fails with a wrapped [io.ErrUnexpectedEOF].
 func readAndReplaceBody(response *http.Response) ([]byte, error) {
 	responseBody := response.Body
 	body, err := io.ReadAll(responseBody)
 	responseBody.Close()
 	response.Body = io.NopCloser(bytes.NewReader(body))
-	return body, err
+	if err != nil {
+		return body, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
 }
 
-func operationInfoFromResponse(response *http.Response, body []byte) (*OperationInfo, error) {
+func (c *Client) operationInfoFromResponse(response *http.Response, body []byte) (*OperationInfo, error) {
 	if !isContentTypeJSON(response.Header) {
-		return nil, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get(headerContentType)), response, body)
+		return nil, c.newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get(headerContentType)), response, body)
 	}
 	var info OperationInfo
 	if err := json.Unmarshal(body, &info); err != nil {
@@ -396,23 +948,54 @@ func operationInfoFromResponse(response *http.Response, body []byte) (*Operation
 	return &info, nil
 }
 
-func failureFromResponse(response *http.Response, body []byte) (Failure, error) {
-	if !isContentTypeJSON(response.Header) {
-		return Failure{}, newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get(headerContentType)), response, body)
+func (c *Client) failureFromResponse(response *http.Response, body []byte) (Failure, error) {
+	codec, ok := c.failureCodecForResponse(response)
+	if !ok {
+		return Failure{}, c.newUnexpectedResponseError(fmt.Sprintf("invalid response content type: %q", response.Header.Get(headerContentType)), response, body)
 	}
 	var failure Failure
-	err := json.Unmarshal(body, &failure)
+	err := codec.Unmarshal(body, &failure)
 	return failure, err
 }
 
-func getUnsuccessfulStateFromHeader(response *http.Response, body []byte) (OperationState, error) {
+func (c *Client) getUnsuccessfulStateFromHeader(response *http.Response, body []byte) (OperationState, error) {
 	state := OperationState(response.Header.Get(headerOperationState))
 	switch state {
 	case OperationStateCanceled:
 		return state, nil
 	case OperationStateFailed:
 		return state, nil
+	case "":
+		return state, c.newMalformedResponseError(fmt.Sprintf("missing %s header", headerOperationState), response, body)
 	default:
-		return state, newUnexpectedResponseError(fmt.Sprintf("invalid operation state header: %q", state), response, body)
+		return state, c.newMalformedResponseError(fmt.Sprintf("invalid operation state header: %q", state), response, body)
+	}
+}
+
+// withOperationTimeout derives a context with the timeout configured for operation in
+// [ClientOptions.OperationTimeout], unless ctx already has a deadline. The returned cancel function must be called
+// once the call completes.
+func (c *Client) withOperationTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if c.options.OperationTimeout == nil {
+		return ctx, func() {}
+	}
+	if _, set := ctx.Deadline(); set {
+		return ctx, func() {}
+	}
+	timeout := c.options.OperationTimeout(operation)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// mapError translates err with [ClientOptions.ErrorMapper], if set.
+func (c *Client) mapError(err error) error {
+	if err == nil || c.options.ErrorMapper == nil {
+		return err
+	}
+	if mapped := c.options.ErrorMapper(err); mapped != nil {
+		return mapped
 	}
+	return err
 }
@@ -0,0 +1,78 @@
+package nexus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+)
+
+type clientIdentityKey struct{}
+
+// ClientIdentity is the identity of a caller authenticated with a TLS client certificate (mTLS), extracted from the
+// leaf certificate presented by the caller.
+//
+// Note that the handler does not verify the certificate itself, configure the server's [tls.Config.ClientAuth] to
+// verify client certificates, e.g. with [tls.RequireAndVerifyClientCert].
+type ClientIdentity struct {
+	// The certificate's subject distinguished name, e.g. "CN=caller,O=Example".
+	Subject string
+	// The certificate's subject common name.
+	CommonName string
+	// DNS subject alternative names.
+	DNSNames []string
+	// URI subject alternative names, e.g. SPIFFE IDs.
+	URIs []*url.URL
+	// Email subject alternative names.
+	EmailAddresses []string
+	// The leaf certificate presented by the caller.
+	Certificate *x509.Certificate
+}
+
+// ClientIdentityFromContext returns the [ClientIdentity] of the caller, or nil if the caller did not present a TLS
+// client certificate.
+//
+// Handlers created with [NewHTTPHandler] populate the identity in the request context, making it available to
+// [Handler] methods.
+func ClientIdentityFromContext(ctx context.Context) *ClientIdentity {
+	identity, _ := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return identity
+}
+
+// clientIdentityFromTLS extracts the caller's identity from a TLS connection state, returning nil if the caller did
+// not present a certificate.
+func clientIdentityFromTLS(state *tls.ConnectionState) *ClientIdentity {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	certificate := state.PeerCertificates[0]
+	return &ClientIdentity{
+		Subject:        certificate.Subject.String(),
+		CommonName:     certificate.Subject.CommonName,
+		DNSNames:       certificate.DNSNames,
+		URIs:           certificate.URIs,
+		EmailAddresses: certificate.EmailAddresses,
+		Certificate:    certificate,
+	}
+}
+
+// withClientIdentity wraps an [http.Handler], storing the caller's [ClientIdentity] in the request context and
+// rejecting callers without a client certificate if [HandlerOptions.RequireClientCert] is set.
+func (h *httpHandler) withClientIdentity(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		identity := clientIdentityFromTLS(request.TLS)
+		if identity == nil {
+			if h.options.RequireClientCert {
+				h.writeFailure(writer, &HandlerError{
+					StatusCode: http.StatusUnauthorized,
+					Failure:    &Failure{Message: "client certificate required"},
+				})
+				return
+			}
+			handler.ServeHTTP(writer, request)
+			return
+		}
+		handler.ServeHTTP(writer, request.WithContext(context.WithValue(request.Context(), clientIdentityKey{}, identity)))
+	})
+}
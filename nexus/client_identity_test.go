@@ -0,0 +1,181 @@
+package nexus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type clientIdentityEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *clientIdentityEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	identity := ClientIdentityFromContext(ctx)
+	if identity == nil {
+		return NewOperationResponseSync("anonymous")
+	}
+	return NewOperationResponseSync([]string{identity.CommonName, identity.URIs[0].String()})
+}
+
+func newTestClientCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	spiffeID, err := url.Parse("spiffe://example.com/caller")
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "caller"},
+		URIs:         []*url.URL{spiffeID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func setupTLS(t *testing.T, handlerOptions HandlerOptions, certificates []tls.Certificate) (*Client, func()) {
+	server := httptest.NewUnstartedServer(NewHTTPHandler(handlerOptions))
+	// The test certificate is self signed, skip verification, it is out of scope for the handler.
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.Certificates = certificates
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: server.URL,
+		HTTPCaller:     (&http.Client{Transport: transport}).Do,
+	})
+	require.NoError(t, err)
+	return client, server.Close
+}
+
+func TestClientIdentity(t *testing.T) {
+	client, teardown := setupTLS(t, HandlerOptions{Handler: &clientIdentityEchoHandler{}}, []tls.Certificate{newTestClientCertificate(t)})
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var identity []string
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&identity))
+	require.Equal(t, []string{"caller", "spiffe://example.com/caller"}, identity)
+}
+
+func TestClientIdentity_Anonymous(t *testing.T) {
+	client, teardown := setupTLS(t, HandlerOptions{Handler: &clientIdentityEchoHandler{}}, nil)
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var identity string
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&identity))
+	require.Equal(t, "anonymous", identity)
+}
+
+func TestRequireClientCert(t *testing.T) {
+	handlerOptions := HandlerOptions{Handler: &clientIdentityEchoHandler{}, RequireClientCert: true}
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	client, teardown := setupTLS(t, handlerOptions, nil)
+	defer teardown()
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusUnauthorized, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "client certificate required", unexpectedResponseError.Failure.Message)
+
+	client, teardown = setupTLS(t, handlerOptions, []tls.Certificate{newTestClientCertificate(t)})
+	defer teardown()
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+}
+
+// peerRecordingHandler records the peer certificate common name, remote address, and path of the HTTP request passed to
+// each handler method.
+type peerRecordingHandler struct {
+	UnimplementedHandler
+	mu    sync.Mutex
+	peers map[string][]string
+}
+
+func (h *peerRecordingHandler) record(method string, request *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.peers[method] = []string{request.TLS.PeerCertificates[0].Subject.CommonName, request.RemoteAddr, request.URL.Path}
+}
+
+func (h *peerRecordingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	h.record("StartOperation", request.HTTPRequest)
+	return &OperationResponseAsync{OperationID: "abc"}, nil
+}
+
+func (h *peerRecordingHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	h.record("GetOperationResult", request.HTTPRequest)
+	return NewOperationResponseSync("done")
+}
+
+func (h *peerRecordingHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	h.record("GetOperationInfo", request.HTTPRequest)
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func (h *peerRecordingHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	h.record("CancelOperation", request.HTTPRequest)
+	return nil
+}
+
+func TestHTTPRequest_AllMethods(t *testing.T) {
+	handler := &peerRecordingHandler{peers: map[string][]string{}}
+	client, teardown := setupTLS(t, HandlerOptions{Handler: handler}, []tls.Certificate{newTestClientCertificate(t)})
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	handle := result.Pending
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+
+	paths := map[string]string{
+		"StartOperation":     "/foo",
+		"GetOperationResult": "/foo/abc/result",
+		"GetOperationInfo":   "/foo/abc",
+		"CancelOperation":    "/foo/abc/cancel",
+	}
+	require.Len(t, handler.peers, len(paths))
+	for method, path := range paths {
+		peer := handler.peers[method]
+		require.Equal(t, "caller", peer[0], method)
+		require.NotEmpty(t, peer[1], method)
+		require.Equal(t, path, peer[2], method)
+	}
+}
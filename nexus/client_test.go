@@ -1,8 +1,17 @@
 package nexus
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -26,3 +35,337 @@ func TestServiceBaseURL(t *testing.T) {
 	_, err = NewClient(ClientOptions{ServiceBaseURL: "https://example.com"})
 	require.NoError(t, err)
 }
+
+type queryCheckingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *queryCheckingHandler) checkQuery(request *http.Request) error {
+	if request.URL.Query().Get("api-key") != "secret" {
+		return newBadRequestError("invalid 'api-key' query param: %q", request.URL.Query().Get("api-key"))
+	}
+	return nil
+}
+
+func (h *queryCheckingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if err := h.checkQuery(request.HTTPRequest); err != nil {
+		return nil, err
+	}
+	if request.CallbackURL != "http://test/callback" {
+		return nil, newBadRequestError("unexpected callback URL: %s", request.CallbackURL)
+	}
+	return &OperationResponseAsync{OperationID: "async"}, nil
+}
+
+func (h *queryCheckingHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	if err := h.checkQuery(request.HTTPRequest); err != nil {
+		return nil, err
+	}
+	if request.Wait == 0 {
+		return nil, newBadRequestError("expected wait to be set")
+	}
+	return &OperationResponseSync{Body: bytes.NewReader(nil)}, nil
+}
+
+func (h *queryCheckingHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	if err := h.checkQuery(request.HTTPRequest); err != nil {
+		return nil, err
+	}
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func (h *queryCheckingHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	return h.checkQuery(request.HTTPRequest)
+}
+
+func TestURLTransformer(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &queryCheckingHandler{}}, ClientOptions{
+		URLTransformer: func(u *url.URL) {
+			q := u.Query()
+			q.Set("api-key", "secret")
+			u.RawQuery = q.Encode()
+		},
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", CallbackURL: "http://test/callback"})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NotNil(t, handle)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.NoError(t, err)
+	response.Body.Close()
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+}
+
+func TestConnectTimeout(t *testing.T) {
+	_, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		ConnectTimeout: time.Second,
+		HTTPCaller:     http.DefaultClient.Do,
+	})
+	require.ErrorIs(t, err, errConnectTimeoutWithHTTPCaller)
+
+	// Simulate an unreachable host with a dialer that blocks until the connect timeout expires.
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://127.0.0.1",
+		ConnectTimeout: time.Millisecond * 100,
+		dialControl: func(ctx context.Context, network, address string, conn syscall.RawConn) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	startTime := time.Now()
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, ctx.Err())
+	require.Less(t, time.Since(startTime), time.Second)
+}
+
+func TestMalformedOperationStateHeader(t *testing.T) {
+	cases := []struct {
+		state           string
+		expectedMessage string
+	}{
+		{state: "", expectedMessage: "missing Nexus-Operation-State header: boom"},
+		{state: "garbage", expectedMessage: `invalid operation state header: "garbage": boom`},
+		{state: string(OperationStateRunning), expectedMessage: `invalid operation state header: "running": boom`},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.state, func(t *testing.T) {
+			client, err := NewClient(ClientOptions{
+				ServiceBaseURL: "http://example.com",
+				HTTPCaller: func(request *http.Request) (*http.Response, error) {
+					header := http.Header{headerContentType: []string{contentTypeJSON}}
+					if c.state != "" {
+						header.Set(headerOperationState, c.state)
+					}
+					return &http.Response{
+						Status:     "424 Failed Dependency",
+						StatusCode: statusOperationFailed,
+						Header:     header,
+						Body:       io.NopCloser(strings.NewReader(`{"message":"boom"}`)),
+						Request:    request,
+					}, nil
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+			require.ErrorIs(t, err, ErrMalformedResponse)
+			var unexpectedError *UnexpectedResponseError
+			require.ErrorAs(t, err, &unexpectedError)
+			require.Equal(t, c.expectedMessage, unexpectedError.Message)
+			require.Equal(t, statusOperationFailed, unexpectedError.Response.StatusCode)
+			require.Equal(t, "boom", unexpectedError.Failure.Message)
+
+			handle, err := client.NewHandle("foo", "id")
+			require.NoError(t, err)
+			_, err = handle.GetResult(context.Background(), GetOperationResultOptions{})
+			require.ErrorIs(t, err, ErrMalformedResponse)
+		})
+	}
+}
+
+type expectContinueHandler struct {
+	UnimplementedHandler
+}
+
+func (h *expectContinueHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.HTTPRequest.Header.Get("Authorization") == "" {
+		// Reject without reading the body.
+		return nil, &HandlerError{StatusCode: http.StatusUnauthorized, Failure: &Failure{Message: "unauthorized"}}
+	}
+	body, err := io.ReadAll(request.HTTPRequest.Body)
+	if err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync([]any{request.HTTPRequest.Header.Get("Expect"), len(body)})
+}
+
+func TestExpectContinueThreshold(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &expectContinueHandler{}}, ClientOptions{ExpectContinueThreshold: 1024})
+	defer teardown()
+
+	authorized := http.Header{"Authorization": []string{"token"}}
+	cases := []struct {
+		size           int
+		expectedExpect string
+	}{
+		{size: 1024, expectedExpect: ""},
+		{size: 1025, expectedExpect: "100-continue"},
+	}
+	for _, c := range cases {
+		result, err := client.StartOperation(ctx, StartOperationOptions{
+			Operation: "foo",
+			Header:    authorized,
+			Body:      bytes.NewReader(make([]byte, c.size)),
+		})
+		require.NoError(t, err)
+		body, err := io.ReadAll(result.Successful.Body)
+		result.Successful.Body.Close()
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf(`[%q,%d]`, c.expectedExpect, c.size), string(body))
+	}
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Body:      bytes.NewReader(make([]byte, 1<<20)),
+	})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusUnauthorized, unexpectedError.Response.StatusCode)
+}
+
+func TestMaxURLLength(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &serviceEchoHandler{}, MaxURLLength: 100}, ClientOptions{MaxURLLength: 200})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: strings.Repeat("a", 50)})
+	require.NoError(t, err)
+
+	// Rejected by the client before sending.
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: strings.Repeat("a", 200)})
+	require.ErrorIs(t, err, ErrURLTooLong)
+	handle, err := client.NewHandle("foo", strings.Repeat("a", 200))
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, ErrURLTooLong)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorIs(t, err, ErrURLTooLong)
+	require.ErrorIs(t, handle.Cancel(ctx, CancelOperationOptions{}), ErrURLTooLong)
+
+	// Rejected by the server.
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: strings.Repeat("a", 120)})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusRequestURITooLong, unexpectedError.Response.StatusCode)
+	require.Equal(t, "request URI length 121 exceeds max length of 100", unexpectedError.Failure.Message)
+}
+
+func TestOperationTimeout(t *testing.T) {
+	var requestCtx context.Context
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		OperationTimeout: func(operation string) time.Duration {
+			switch operation {
+			case "charge":
+				return time.Second * 10
+			case "report":
+				return time.Minute * 5
+			}
+			return 0
+		},
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			requestCtx = request.Context()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    request,
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	start := func(ctx context.Context, operation string) (deadline time.Time, set bool) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation})
+		require.NoError(t, err)
+		// The context outlives the call until the response body is closed.
+		require.NoError(t, requestCtx.Err())
+		require.NoError(t, result.Successful.Body.Close())
+		return requestCtx.Deadline()
+	}
+
+	now := time.Now()
+	deadline, set := start(context.Background(), "charge")
+	require.True(t, set)
+	require.WithinDuration(t, now.Add(time.Second*10), deadline, time.Second)
+	// The derived context is released once the body is closed.
+	require.ErrorIs(t, requestCtx.Err(), context.Canceled)
+
+	deadline, set = start(context.Background(), "report")
+	require.True(t, set)
+	require.WithinDuration(t, now.Add(time.Minute*5), deadline, time.Second)
+
+	_, set = start(context.Background(), "other")
+	require.False(t, set)
+
+	// A caller supplied deadline takes precedence, even if it is longer.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	callerDeadline, _ := ctx.Deadline()
+	deadline, set = start(ctx, "charge")
+	require.True(t, set)
+	require.Equal(t, callerDeadline, deadline)
+	require.NoError(t, requestCtx.Err())
+
+	handle, err := client.NewHandle("charge", "id")
+	require.NoError(t, err)
+	_, err = handle.GetResult(context.Background(), GetOperationResultOptions{})
+	require.NoError(t, err)
+	deadline, set = requestCtx.Deadline()
+	require.True(t, set)
+	require.WithinDuration(t, time.Now().Add(time.Second*10), deadline, time.Second)
+}
+
+var errInsufficientFunds = errors.New("insufficient funds")
+
+func TestErrorMapper(t *testing.T) {
+	var mapped []error
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &unsuccessfulHandler{}}, ClientOptions{
+		ErrorMapper: func(err error) error {
+			mapped = append(mapped, err)
+			var unsuccessfulError *UnsuccessfulOperationError
+			if errors.As(err, &unsuccessfulError) && unsuccessfulError.Failure.Message == "intentional" {
+				return fmt.Errorf("%w: %w", errInsufficientFunds, err)
+			}
+			return nil
+		},
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: string(OperationStateFailed)})
+	require.ErrorIs(t, err, errInsufficientFunds)
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+
+	// Returning nil preserves the original error.
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	err = handle.Cancel(ctx, CancelOperationOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Len(t, mapped, 2)
+
+	// Errors of successful calls are not mapped and ExecuteOperation maps errors once.
+	_, err = client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo", RequestID: string(OperationStateCanceled)})
+	require.ErrorIs(t, err, errInsufficientFunds)
+	require.Len(t, mapped, 3)
+}
+
+func TestErrorMapper_TransportError(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+		ErrorMapper: func(err error) error {
+			return fmt.Errorf("service unavailable: %w", err)
+		},
+	})
+	require.NoError(t, err)
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(context.Background(), GetOperationInfoOptions{})
+	require.EqualError(t, err, "service unavailable: connection refused")
+	_, err = handle.StreamEvents(context.Background(), StreamOperationEventsOptions{})
+	require.EqualError(t, err, "service unavailable: connection refused")
+}
@@ -0,0 +1,137 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+var errCoalescedStartPanicked = errors.New("coalesced start operation request panicked")
+
+// startCoalescer coalesces concurrent start operation requests with the same key, see
+// [HandlerOptions.CoalesceStartRequests].
+type startCoalescer struct {
+	mu    sync.Mutex
+	calls map[coalesceKey]*coalescedStart
+}
+
+// coalesceKey identifies start requests that are coalesced. A struct rather than a joined string since names may
+// contain any separator.
+type coalesceKey struct {
+	service   string
+	operation string
+	requestID string
+}
+
+type coalescedStart struct {
+	done   chan struct{}
+	replay func() OperationResponse
+	err    error
+	// Set if the context of the request that invoked fn was done by the time fn returned.
+	canceled bool
+}
+
+func newStartCoalescer() *startCoalescer {
+	return &startCoalescer{calls: make(map[coalesceKey]*coalescedStart)}
+}
+
+// do calls fn unless a call with the same key is already in flight, in which case it waits for that call's outcome.
+// Each caller gets its own copy of the response. If the in flight call fails after its caller's context is done, e.g.
+// when its client disconnected, waiting callers call their own fn instead of failing with an error that isn't theirs.
+func (c *startCoalescer) do(ctx context.Context, key coalesceKey, fn func() (OperationResponse, error)) (OperationResponse, error) {
+	for {
+		c.mu.Lock()
+		call, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			if call.err != nil {
+				if call.canceled && ctx.Err() == nil {
+					continue
+				}
+				return nil, call.err
+			}
+			return call.replay(), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &coalescedStart{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	// Fails waiting callers if fn panics.
+	call.err = errCoalescedStartPanicked
+	response, err := fn()
+	if err == nil && isNilOperationResponse(response) {
+		err = errNilOperationResponse
+	}
+	if err == nil {
+		call.replay, err = replayableResponse(response)
+	}
+	call.err = err
+	call.canceled = ctx.Err() != nil
+	if err != nil {
+		return nil, err
+	}
+	return call.replay(), nil
+}
+
+// replayableResponse returns a function producing copies of response that may be written independently. Sync response
+// bodies are buffered in memory.
+func replayableResponse(response OperationResponse) (func() OperationResponse, error) {
+	switch r := response.(type) {
+	case *headerDecoratedOperationResponse:
+		replay, err := replayableResponse(r.OperationResponse)
+		if err != nil {
+			return nil, err
+		}
+		return func() OperationResponse {
+			return &headerDecoratedOperationResponse{OperationResponse: replay(), header: r.header}
+		}, nil
+	case *OperationResponseSync:
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if closer, ok := r.Body.(io.Closer); ok {
+				closer.Close()
+			}
+			if err != nil {
+				if r.OnWritten != nil {
+					r.OnWritten(err)
+				}
+				return nil, err
+			}
+		}
+		// Only the first copy invokes the finalizer, it would otherwise run once per coalesced request.
+		var once sync.Once
+		return func() OperationResponse {
+			replayed := *r
+			// Copies are modified independently, e.g. when encoding the result with each caller's negotiated codec.
+			replayed.Header = r.Header.Clone()
+			if r.source != nil {
+				source := *r.source
+				replayed.source = &source
+			}
+			replayed.Body = bytes.NewReader(body)
+			replayed.ContentLength = int64(len(body))
+			replayed.OnWritten = nil
+			once.Do(func() { replayed.OnWritten = r.OnWritten })
+			return &replayed
+		}, nil
+	default:
+		return func() OperationResponse { return response }, nil
+	}
+}
@@ -0,0 +1,194 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type slowStartHandler struct {
+	UnimplementedHandler
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (h *slowStartHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	call := h.calls.Add(1)
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if request.Operation == "async" {
+		return &OperationResponseAsync{OperationID: "id"}, nil
+	}
+	return NewOperationResponseSync(call)
+}
+
+func TestCoalesceStartRequests(t *testing.T) {
+	handler := &slowStartHandler{release: make(chan struct{})}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler, CoalesceStartRequests: true}, ClientOptions{})
+	defer teardown()
+
+	const concurrency = 5
+	bodies := make([]string, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "sync", RequestID: "request-id"})
+			require.NoError(t, err)
+			defer result.Successful.Body.Close()
+			body, err := io.ReadAll(result.Successful.Body)
+			require.NoError(t, err)
+			bodies[i] = string(body)
+		}()
+	}
+	require.Eventually(t, func() bool { return handler.calls.Load() == 1 }, time.Second, time.Millisecond)
+	// Give the other requests a chance to arrive and block on the first one.
+	time.Sleep(time.Millisecond * 100)
+	close(handler.release)
+	wg.Wait()
+	require.Equal(t, int32(1), handler.calls.Load())
+	for _, body := range bodies {
+		require.Equal(t, "1", body)
+	}
+
+	// Requests are not coalesced once the first one completes, or when their request IDs differ.
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "sync", RequestID: "request-id"})
+	require.NoError(t, err)
+	result.Successful.Body.Close()
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "async", RequestID: "request-id"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, int32(3), handler.calls.Load())
+}
+
+func TestCoalesceStartRequests_FirstCallerDisconnects(t *testing.T) {
+	handler := &slowStartHandler{release: make(chan struct{})}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler, CoalesceStartRequests: true}, ClientOptions{})
+	defer teardown()
+
+	firstCtx, cancelFirst := context.WithCancel(ctx)
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.StartOperation(firstCtx, StartOperationOptions{Operation: "sync", RequestID: "request-id"})
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return handler.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	body := make(chan string, 1)
+	go func() {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "sync", RequestID: "request-id"})
+		require.NoError(t, err)
+		defer result.Successful.Body.Close()
+		b, err := io.ReadAll(result.Successful.Body)
+		require.NoError(t, err)
+		body <- string(b)
+	}()
+	// Give the second request a chance to arrive and block on the first one.
+	time.Sleep(time.Millisecond * 100)
+	cancelFirst()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// The waiting request starts the operation itself instead of failing.
+	require.Eventually(t, func() bool { return handler.calls.Load() == 2 }, time.Second, time.Millisecond)
+	close(handler.release)
+	require.Equal(t, "2", <-body)
+}
+
+func TestCoalesceStartRequests_ReplaysEncodedIndependently(t *testing.T) {
+	handler := &slowStartHandler{release: make(chan struct{})}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:               handler,
+		CoalesceStartRequests: true,
+		OperationCodecs:       map[string][]ResultCodec{"sync": {JSONResultCodec{}, prefixCodec("text/plain")}},
+		ResponseMiddleware: func(ctx context.Context, operation string, result any) (any, error) {
+			return fmt.Sprintf("wrapped %v", result), nil
+		},
+	}, ClientOptions{})
+	defer teardown()
+
+	accepts := []string{contentTypeJSON, "text/plain", contentTypeJSON, "text/plain"}
+	responses := make([]string, len(accepts))
+	var wg sync.WaitGroup
+	for i, accept := range accepts {
+		i, accept := i, accept
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.StartOperation(ctx, StartOperationOptions{
+				Operation: "sync",
+				RequestID: "request-id",
+				Header:    http.Header{"Accept": []string{accept}},
+			})
+			require.NoError(t, err)
+			defer result.Successful.Body.Close()
+			body, err := io.ReadAll(result.Successful.Body)
+			require.NoError(t, err)
+			responses[i] = result.Successful.Header.Get(headerContentType) + " " + string(body)
+		}()
+	}
+	require.Eventually(t, func() bool { return handler.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(time.Millisecond * 100)
+	close(handler.release)
+	wg.Wait()
+	require.Equal(t, int32(1), handler.calls.Load())
+	require.Equal(t, []string{
+		`application/json "wrapped 1"`,
+		"text/plain text/plain:wrapped 1",
+		`application/json "wrapped 1"`,
+		"text/plain text/plain:wrapped 1",
+	}, responses)
+}
+
+func TestCoalesceStartRequests_NilResponse(t *testing.T) {
+	logs := &recordingLogHandler{}
+	handler := NewHTTPHandler(HandlerOptions{Handler: &nilResponseHandler{}, CoalesceStartRequests: true, Logger: slog.New(logs)})
+	for _, operation := range []string{"nil", "typed-nil"} {
+		request := httptest.NewRequest("POST", "/"+operation, nil)
+		request.Header.Set(headerRequestID, "request-id")
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		require.Equal(t, http.StatusInternalServerError, writer.Code, operation)
+		var failure *Failure
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+		require.Equal(t, "internal server error", failure.Message)
+		// Fails gracefully rather than panicking.
+		require.Equal(t, []string{"handler failed"}, logs.take())
+	}
+}
+
+func TestCoalesceStartRequests_KeyedUnambiguously(t *testing.T) {
+	handler := &slowStartHandler{release: make(chan struct{})}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler, CoalesceStartRequests: true}, ClientOptions{})
+	defer teardown()
+
+	// Operation and request ID pairs that would collide if joined with a slash.
+	var wg sync.WaitGroup
+	for _, options := range []StartOperationOptions{{Operation: "a/b", RequestID: "c"}, {Operation: "a", RequestID: "b/c"}} {
+		options := options
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.StartOperation(ctx, options)
+			require.NoError(t, err)
+			require.NoError(t, result.Successful.Body.Close())
+		}()
+	}
+	require.Eventually(t, func() bool { return handler.calls.Load() == 2 }, time.Second, time.Millisecond)
+	close(handler.release)
+	wg.Wait()
+}
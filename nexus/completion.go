@@ -23,10 +23,50 @@ func NewCompletionHTTPRequest(ctx context.Context, url string, completion Operat
 	return httpReq, nil
 }
 
+// NewCompletionHTTPRequests creates HTTP requests to deliver an operation completion to all of the given callbacks
+// that match the completion's state.
+//
+// The completion body is read into memory in order to be delivered to multiple callbacks.
+func NewCompletionHTTPRequests(ctx context.Context, callbacks []Callback, completion OperationCompletion) ([]*http.Request, error) {
+	state := completion.state()
+	var matching []Callback
+	for _, callback := range callbacks {
+		if callback.Matches(state) {
+			matching = append(matching, callback)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, nil
+	}
+	if len(matching) > 1 {
+		// Buffer the body to allow sending it more than once.
+		if successful, ok := completion.(*OperationCompletionSuccessful); ok && successful.Body != nil {
+			if closer, ok := successful.Body.(io.Closer); ok {
+				defer closer.Close()
+			}
+			b, err := io.ReadAll(successful.Body)
+			if err != nil {
+				return nil, err
+			}
+			completion = &bufferedOperationCompletionSuccessful{header: successful.Header, body: b}
+		}
+	}
+	requests := make([]*http.Request, 0, len(matching))
+	for _, callback := range matching {
+		request, err := NewCompletionHTTPRequest(ctx, callback.URL, completion)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
 // OperationCompletion is input for [NewCompletionHTTPRequest].
 // It has two implementations: [OperationCompletionSuccessful] and [OperationCompletionUnsuccessful].
 type OperationCompletion interface {
 	applyToHTTPRequest(*http.Request) error
+	state() OperationState
 }
 
 // OperationCompletionSuccessful is input for [NewCompletionHTTPRequest], used to deliver successful operation results.
@@ -68,6 +108,26 @@ func (c *OperationCompletionSuccessful) applyToHTTPRequest(request *http.Request
 	return nil
 }
 
+func (c *OperationCompletionSuccessful) state() OperationState {
+	return OperationStateSucceeded
+}
+
+// bufferedOperationCompletionSuccessful is a successful completion with an in-memory body that can be applied to more
+// than one HTTP request.
+type bufferedOperationCompletionSuccessful struct {
+	header http.Header
+	body   []byte
+}
+
+func (c *bufferedOperationCompletionSuccessful) applyToHTTPRequest(request *http.Request) error {
+	completion := OperationCompletionSuccessful{Header: c.header, Body: bytes.NewReader(c.body)}
+	return completion.applyToHTTPRequest(request)
+}
+
+func (c *bufferedOperationCompletionSuccessful) state() OperationState {
+	return OperationStateSucceeded
+}
+
 // OperationCompletionUnsuccessful is input for [NewCompletionHTTPRequest], used to deliver unsuccessful operation
 // results.
 type OperationCompletionUnsuccessful struct {
@@ -95,6 +155,10 @@ func (c *OperationCompletionUnsuccessful) applyToHTTPRequest(request *http.Reque
 	return nil
 }
 
+func (c *OperationCompletionUnsuccessful) state() OperationState {
+	return c.State
+}
+
 // CompletionRequest is input for CompletionHandler.CompleteOperation.
 type CompletionRequest struct {
 	// The original HTTP request.
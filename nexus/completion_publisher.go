@@ -0,0 +1,166 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CompletionEvent describes the outcome of an asynchronous operation, published to a message queue via a
+// [CompletionPublisher] as an alternative to delivering completions to HTTP callbacks.
+//
+// Events are serialized as JSON, see [MarshalCompletionEvent] and [UnmarshalCompletionEvent].
+type CompletionEvent struct {
+	// Service the operation belongs to. Optional.
+	Service string `json:"service,omitempty"`
+	// Name of the operation. Required.
+	Operation string `json:"operation"`
+	// ID of the operation. Required.
+	OperationID string `json:"operationId"`
+	// State of the operation, one of succeeded, failed or canceled. Required.
+	State OperationState `json:"state"`
+	// Failure of an operation that failed or was canceled. Required for unsuccessful operations only.
+	Failure *Failure `json:"failure,omitempty"`
+	// JSON result of a successful operation. Optional, mutually exclusive with ResultReference.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Reference to the result of a successful operation, for results that are too large to be published or aren't JSON.
+	// Optional, mutually exclusive with Result.
+	ResultReference *ResultReference `json:"resultReference,omitempty"`
+	// Time the operation completed at. Set by [PublishCompletion] if zero.
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// validate checks that the event is well formed, returning an error describing the first invalid field.
+func (e *CompletionEvent) validate() error {
+	if e.Operation == "" {
+		return errors.New("completion event operation is required")
+	}
+	if e.OperationID == "" {
+		return errors.New("completion event operation ID is required")
+	}
+	switch e.State {
+	case OperationStateSucceeded:
+		if e.Failure != nil {
+			return errors.New("successful completion event must not have a failure")
+		}
+		if e.Result != nil && e.ResultReference != nil {
+			return errors.New("completion event result and result reference are mutually exclusive")
+		}
+		if e.ResultReference != nil && e.ResultReference.URL == "" {
+			return errors.New("completion event result reference URL is required")
+		}
+	case OperationStateFailed, OperationStateCanceled:
+		if e.Failure == nil {
+			return fmt.Errorf("%s completion event must have a failure", e.State)
+		}
+		if e.Result != nil || e.ResultReference != nil {
+			return fmt.Errorf("%s completion event must not have a result", e.State)
+		}
+	default:
+		return fmt.Errorf("invalid completion event state: %q", e.State)
+	}
+	return nil
+}
+
+// key identifies the operation an event belongs to, see [CompletionPublisher].
+func (e *CompletionEvent) key() string {
+	return url.PathEscape(e.Service) + "/" + url.PathEscape(e.Operation) + "/" + url.PathEscape(e.OperationID)
+}
+
+// MarshalCompletionEvent serializes a [CompletionEvent] to JSON, failing if the event is malformed.
+func MarshalCompletionEvent(event *CompletionEvent) ([]byte, error) {
+	if err := event.validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(event)
+}
+
+// UnmarshalCompletionEvent deserializes a [CompletionEvent] consumed from a message queue, failing if the event is
+// malformed.
+func UnmarshalCompletionEvent(message []byte) (*CompletionEvent, error) {
+	var event CompletionEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode completion event: %w", err)
+	}
+	if err := event.validate(); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// A CompletionPublisher publishes serialized [CompletionEvent]s to a message queue, e.g. Kafka, SQS or NATS.
+// Implementations wire the package to a broker, use [PublishCompletion] to publish events.
+type CompletionPublisher interface {
+	// Publish publishes a message. The key identifies the completed operation, all events of an operation share a key,
+	// and may be used for partitioning or deduplication.
+	Publish(ctx context.Context, key string, message []byte) error
+}
+
+// PublishCompletion serializes a [CompletionEvent] and publishes it with the given publisher. Call it from a
+// [Handler] once an asynchronous operation completes, in place of or in addition to delivering the completion to the
+// operation's callbacks. CompletedAt is set to the current time if zero.
+func PublishCompletion(ctx context.Context, publisher CompletionPublisher, event CompletionEvent) error {
+	if event.CompletedAt.IsZero() {
+		event.CompletedAt = time.Now()
+	}
+	message, err := MarshalCompletionEvent(&event)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, event.key(), message)
+}
+
+// InMemoryCompletionQueue is a [CompletionPublisher] that queues events in memory, for tests.
+// Events are deserialized as they're published, failing to publish malformed events.
+type InMemoryCompletionQueue struct {
+	mu     sync.Mutex
+	events []*CompletionEvent
+	// Closed when an event is published while receivers are waiting.
+	published chan struct{}
+}
+
+// Publish implements the [CompletionPublisher] interface.
+func (q *InMemoryCompletionQueue) Publish(ctx context.Context, key string, message []byte) error {
+	event, err := UnmarshalCompletionEvent(message)
+	if err != nil {
+		return err
+	}
+	if key != event.key() {
+		return fmt.Errorf("completion event key mismatch: expected %q, got %q", event.key(), key)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, event)
+	if q.published != nil {
+		close(q.published)
+		q.published = nil
+	}
+	return nil
+}
+
+// Receive removes the oldest event from the queue, blocking until an event is published or ctx is done.
+func (q *InMemoryCompletionQueue) Receive(ctx context.Context) (*CompletionEvent, error) {
+	for {
+		q.mu.Lock()
+		if len(q.events) > 0 {
+			event := q.events[0]
+			q.events = q.events[1:]
+			q.mu.Unlock()
+			return event, nil
+		}
+		if q.published == nil {
+			q.published = make(chan struct{})
+		}
+		published := q.published
+		q.mu.Unlock()
+		select {
+		case <-published:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
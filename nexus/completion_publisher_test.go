@@ -0,0 +1,118 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// publishingHandler starts asynchronous operations that complete in the background, publishing their outcome.
+type publishingHandler struct {
+	UnimplementedHandler
+	publisher CompletionPublisher
+}
+
+func (h *publishingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input json.RawMessage
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	event := CompletionEvent{Operation: request.Operation, OperationID: "a/sync", State: OperationStateSucceeded, Result: input}
+	if request.Operation == "fail" {
+		event.State = OperationStateFailed
+		event.Failure = &Failure{Message: "expected"}
+		event.Result = nil
+	}
+	go func() {
+		_ = PublishCompletion(context.Background(), h.publisher, event)
+	}()
+	return &OperationResponseAsync{OperationID: "a/sync"}, nil
+}
+
+func TestPublishCompletion(t *testing.T) {
+	queue := &InMemoryCompletionQueue{}
+	ctx, client, teardown := setup(t, &publishingHandler{publisher: queue})
+	defer teardown()
+
+	options, err := NewStartOperationOptions("foo", map[string]int{"a": 1})
+	require.NoError(t, err)
+	result, err := client.StartOperation(ctx, options)
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	event, err := queue.Receive(ctx)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), event.CompletedAt, time.Second)
+	event.CompletedAt = time.Time{}
+	require.Equal(t, &CompletionEvent{
+		Operation:   "foo",
+		OperationID: "a/sync",
+		State:       OperationStateSucceeded,
+		Result:      json.RawMessage(`{"a":1}`),
+	}, event)
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "fail"})
+	require.NoError(t, err)
+	event, err = queue.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, OperationStateFailed, event.State)
+	require.Equal(t, &Failure{Message: "expected"}, event.Failure)
+
+	// Receive blocks until an event is published.
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err = queue.Receive(timeoutCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCompletionEventSerialization(t *testing.T) {
+	completedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := &CompletionEvent{
+		Service:         "payments",
+		Operation:       "charge",
+		OperationID:     "abc",
+		State:           OperationStateSucceeded,
+		ResultReference: &ResultReference{URL: "https://example.com/result", Size: 10},
+		CompletedAt:     completedAt,
+	}
+	message, err := MarshalCompletionEvent(event)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"service": "payments",
+		"operation": "charge",
+		"operationId": "abc",
+		"state": "succeeded",
+		"resultReference": {"url": "https://example.com/result", "size": 10},
+		"completedAt": "2024-01-02T03:04:05Z"
+	}`, string(message))
+	decoded, err := UnmarshalCompletionEvent(message)
+	require.NoError(t, err)
+	require.Equal(t, event, decoded)
+	require.Equal(t, "payments/charge/abc", event.key())
+
+	cases := []struct {
+		event CompletionEvent
+		err   string
+	}{
+		{event: CompletionEvent{OperationID: "abc", State: OperationStateSucceeded}, err: "completion event operation is required"},
+		{event: CompletionEvent{Operation: "charge", State: OperationStateSucceeded}, err: "completion event operation ID is required"},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateRunning}, err: `invalid completion event state: "running"`},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateFailed}, err: "failed completion event must have a failure"},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateCanceled, Failure: &Failure{}, Result: json.RawMessage("1")}, err: "canceled completion event must not have a result"},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateSucceeded, Failure: &Failure{}}, err: "successful completion event must not have a failure"},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateSucceeded, Result: json.RawMessage("1"), ResultReference: &ResultReference{URL: "x"}}, err: "completion event result and result reference are mutually exclusive"},
+		{event: CompletionEvent{Operation: "charge", OperationID: "abc", State: OperationStateSucceeded, ResultReference: &ResultReference{}}, err: "completion event result reference URL is required"},
+	}
+	for _, c := range cases {
+		_, err := MarshalCompletionEvent(&c.event)
+		require.EqualError(t, err, c.err)
+		require.EqualError(t, PublishCompletion(context.Background(), &InMemoryCompletionQueue{}, c.event), c.err)
+	}
+
+	_, err = UnmarshalCompletionEvent([]byte("not json"))
+	require.ErrorContains(t, err, "failed to decode completion event")
+	_, err = UnmarshalCompletionEvent([]byte(`{"operation":"charge","operationId":"abc","state":"running"}`))
+	require.EqualError(t, err, `invalid completion event state: "running"`)
+}
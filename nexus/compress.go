@@ -0,0 +1,31 @@
+package nexus
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptsGzip returns true if the request's Accept-Encoding header allows gzip encoded responses, either explicitly or
+// via the * wildcard. An explicit gzip coding takes precedence over the wildcard.
+func acceptsGzip(request *http.Request) bool {
+	wildcard := false
+	for _, value := range request.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			name = strings.TrimSpace(name)
+			accepted := true
+			if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+				weight, err := strconv.ParseFloat(q, 64)
+				accepted = err == nil && weight > 0
+			}
+			if strings.EqualFold(name, "gzip") {
+				return accepted
+			}
+			if name == "*" {
+				wildcard = accepted
+			}
+		}
+	}
+	return wildcard
+}
@@ -0,0 +1,84 @@
+package nexus
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                     false,
+		"gzip":                 true,
+		"deflate, GZIP":        true,
+		"br;q=1.0, gzip;q=0.5": true,
+		"gzip;q=0":             false,
+		"*":                    true,
+		"*;q=0":                false,
+		"gzip;q=0, *":          false,
+		"identity":             false,
+	}
+	for value, expected := range cases {
+		request := httptest.NewRequest("GET", "/", nil)
+		if value != "" {
+			request.Header.Set("Accept-Encoding", value)
+		}
+		require.Equal(t, expected, acceptsGzip(request), value)
+	}
+}
+
+type compressingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *compressingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	response, err := NewOperationResponseSync(strings.Repeat("a", 10000))
+	if err != nil {
+		return nil, err
+	}
+	response.Compress = true
+	return response, nil
+}
+
+func TestCompress(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &compressingHandler{}})
+	expected := `"` + strings.Repeat("a", 10000) + `"`
+
+	writer := httptest.NewRecorder()
+	request := httptest.NewRequest("POST", "/foo", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "gzip", writer.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", writer.Header().Get("Vary"))
+	require.Empty(t, writer.Header().Get("Content-Length"))
+	require.Less(t, writer.Body.Len(), 1000)
+	reader, err := gzip.NewReader(writer.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(body))
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Empty(t, writer.Header().Get("Content-Encoding"))
+	require.Equal(t, expected, writer.Body.String())
+
+	// The Go HTTP client requests and transparently decompresses gzip responses.
+	ctx, client, teardown := setup(t, &compressingHandler{})
+	defer teardown()
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	require.True(t, result.Successful.Uncompressed)
+	body, err = io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(body))
+}
@@ -0,0 +1,93 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Default for [ConcurrencyOptions.RetryAfter].
+const defaultConcurrencyRetryAfter = time.Second
+
+// ConcurrencyOptions bound the number of concurrent [Handler] invocations, see [HandlerOptions.Concurrency].
+type ConcurrencyOptions struct {
+	// Max number of requests handled concurrently. Required, must be positive.
+	MaxConcurrent int
+	// Max number of requests waiting for a slot once MaxConcurrent requests are being handled. Requests arriving once
+	// the queue is full are rejected with a 503 status code. Zero means requests are never queued.
+	MaxQueued int
+	// Value of the Retry-After header set on rejected requests, rounded up to whole seconds. Defaults to one second.
+	RetryAfter time.Duration
+}
+
+var errConcurrencyQueueFull = errors.New("queue full")
+
+// concurrencyLimiter admits up to a max number of concurrent requests, queuing excess requests up to a bound.
+type concurrencyLimiter struct {
+	slots     chan struct{}
+	queued    atomic.Int64
+	maxQueued int64
+}
+
+func newConcurrencyLimiter(options ConcurrencyOptions) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		slots:     make(chan struct{}, options.MaxConcurrent),
+		maxQueued: int64(options.MaxQueued),
+	}
+}
+
+// acquire waits for a slot, failing with errConcurrencyQueueFull if the queue is full or with ctx's error if ctx is
+// done before a slot is available. Callers must call release once done with an acquired slot.
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if l.queued.Add(1) > l.maxQueued {
+		l.queued.Add(-1)
+		return errConcurrencyQueueFull
+	}
+	defer l.queued.Add(-1)
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
+
+// limitConcurrency wraps an [http.Handler], admitting requests per [HandlerOptions.Concurrency].
+func (h *httpHandler) limitConcurrency(handler http.Handler) http.Handler {
+	limiter := newConcurrencyLimiter(*h.options.Concurrency)
+	retryAfter := h.options.Concurrency.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultConcurrencyRetryAfter
+	}
+	retryAfterSeconds := strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if err := limiter.acquire(request.Context()); err != nil {
+			message := "too many concurrent requests"
+			if !errors.Is(err, errConcurrencyQueueFull) {
+				// The request context is done, most likely the caller is gone. Respond anyway, the context may have
+				// been done for another reason, e.g. a server side timeout.
+				message = "request context done while waiting for a concurrency slot"
+			}
+			h.writeFailure(writer, &HandlerError{
+				StatusCode: http.StatusServiceUnavailable,
+				Failure:    &Failure{Message: message},
+				Header:     http.Header{"Retry-After": []string{retryAfterSeconds}},
+			})
+			return
+		}
+		defer limiter.release()
+		handler.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,120 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type blockingHandler struct {
+	UnimplementedHandler
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (h *blockingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	h.started <- struct{}{}
+	<-h.unblock
+	return NewOperationResponseSync("done")
+}
+
+func TestConcurrency(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}, 10), unblock: make(chan struct{})}
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:     handler,
+		Concurrency: &ConcurrencyOptions{MaxConcurrent: 1, MaxQueued: 1, RetryAfter: time.Millisecond * 1500},
+	})
+	serve := func() *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		httpHandler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+		return writer
+	}
+	// Probe with a canceled request, which leaves the queue immediately if admitted to it.
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	probe := func() string {
+		writer := httptest.NewRecorder()
+		httpHandler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil).WithContext(canceledCtx))
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+		return writer.Body.String()
+	}
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve().Code
+		}()
+		if i == 0 {
+			<-handler.started
+		}
+	}
+	// Wait for the second request to be queued.
+	require.Eventually(t, func() bool {
+		return probe() == `{"message":"too many concurrent requests"}`
+	}, time.Second, time.Millisecond*10)
+
+	// The queue is full, the next request is rejected.
+	writer := serve()
+	require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	require.Equal(t, "2", writer.Header().Get("Retry-After"))
+	require.Len(t, handler.started, 0)
+
+	// Releasing the first request admits the queued one.
+	handler.unblock <- struct{}{}
+	<-handler.started
+	handler.unblock <- struct{}{}
+	wg.Wait()
+	require.Equal(t, http.StatusOK, <-codes)
+	require.Equal(t, http.StatusOK, <-codes)
+}
+
+func TestConcurrency_QueuedRequestCanceled(t *testing.T) {
+	limiter := newConcurrencyLimiter(ConcurrencyOptions{MaxConcurrent: 1, MaxQueued: 1})
+	require.NoError(t, limiter.acquire(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	require.ErrorIs(t, limiter.acquire(ctx), context.DeadlineExceeded)
+	// The canceled request left the queue.
+	require.Equal(t, int64(0), limiter.queued.Load())
+	limiter.release()
+	require.NoError(t, limiter.acquire(context.Background()))
+}
+
+func TestConcurrency_QueuedRequestContextDone(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}, 1), unblock: make(chan struct{})}
+	httpHandler := NewHTTPHandler(HandlerOptions{
+		Handler:     handler,
+		Concurrency: &ConcurrencyOptions{MaxConcurrent: 1, MaxQueued: 1},
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		httpHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/foo", nil))
+	}()
+	<-handler.started
+
+	// Never responded to with an empty successful response.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	writer := httptest.NewRecorder()
+	httpHandler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil).WithContext(ctx))
+	require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	require.Equal(t, `{"message":"request context done while waiting for a concurrency slot"}`, writer.Body.String())
+
+	handler.unblock <- struct{}{}
+	<-done
+}
+
+func TestConcurrency_InvalidOptions(t *testing.T) {
+	require.Panics(t, func() {
+		NewHTTPHandler(HandlerOptions{Handler: &blockingHandler{}, Concurrency: &ConcurrencyOptions{}})
+	})
+}
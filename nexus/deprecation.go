@@ -0,0 +1,80 @@
+package nexus
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers for signaling deprecation of an operation, see the IETF HTTP API deprecation and sunset (RFC 8594) headers.
+const (
+	headerDeprecation = "Deprecation"
+	headerSunset      = "Sunset"
+)
+
+// OperationDeprecation marks an operation as deprecated, see [HandlerOptions.DeprecatedOperations].
+type OperationDeprecation struct {
+	// A human readable message, e.g. pointing callers to the operation to migrate to. Delivered as a warning, see
+	// [ResponseWarnings]. Optional.
+	Message string
+	// Time at which the operation is expected to stop being served. Delivered in the Sunset header. Optional.
+	Sunset time.Time
+}
+
+// setDeprecationHeaders sets the deprecation headers on a response for the given operation if it is deprecated.
+func (h *httpHandler) setDeprecationHeaders(header http.Header, operation string) {
+	deprecation, ok := h.deprecatedOperations[operation]
+	if !ok {
+		return
+	}
+	header.Set(headerDeprecation, "true")
+	if !deprecation.Sunset.IsZero() {
+		header.Set(headerSunset, deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Message != "" {
+		header.Add(headerWarning, formatWarning(deprecation.Message))
+	}
+}
+
+// ResponseDeprecation reports whether a response is for an operation marked as deprecated by the handler, along with
+// the time the operation is expected to stop being served, if announced. The deprecation message, if any, is included
+// in the response's warnings, see [ResponseWarnings].
+func ResponseDeprecation(response *http.Response) (deprecated bool, sunset time.Time) {
+	value := response.Header.Get(headerDeprecation)
+	if value == "" || strings.EqualFold(value, "false") {
+		return false, time.Time{}
+	}
+	if value := response.Header.Get(headerSunset); value != "" {
+		// Malformed values are ignored.
+		sunset, _ = http.ParseTime(value)
+	}
+	return true, sunset
+}
+
+// warnOnDeprecation wraps an HTTP caller, logging a warning the first time a response indicates that an operation is
+// deprecated.
+func warnOnDeprecation(logger *slog.Logger, caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	var warned sync.Map
+	return func(request *http.Request) (*http.Response, error) {
+		response, err := caller(request)
+		if err != nil {
+			return response, err
+		}
+		if deprecated, sunset := ResponseDeprecation(response); deprecated {
+			operation, _ := request.Context().Value(operationContextKey{}).(string)
+			if _, loaded := warned.LoadOrStore(operation, struct{}{}); !loaded {
+				attrs := []any{"operation", operation}
+				if !sunset.IsZero() {
+					attrs = append(attrs, "sunset", sunset)
+				}
+				if warnings := ResponseWarnings(response); len(warnings) > 0 {
+					attrs = append(attrs, "warnings", warnings)
+				}
+				logger.Warn("operation is deprecated", attrs...)
+			}
+		}
+		return response, nil
+	}
+}
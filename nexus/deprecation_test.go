@@ -0,0 +1,99 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type asyncResultHandler struct {
+	asyncHandler
+}
+
+func (h *asyncResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return NewOperationResponseSync(true)
+}
+
+func TestDeprecatedOperations(t *testing.T) {
+	sunset := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+	var logs bytes.Buffer
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler: &asyncResultHandler{},
+		DeprecatedOperations: map[string]OperationDeprecation{
+			"foo": {Message: "use bar instead", Sunset: sunset},
+		},
+	}, ClientOptions{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, 1, strings.Count(logs.String(), "operation is deprecated"))
+	require.Contains(t, logs.String(), "operation=foo")
+	require.Contains(t, logs.String(), "use bar instead")
+
+	response, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	deprecated, gotSunset := ResponseDeprecation(response)
+	require.True(t, deprecated)
+	require.True(t, sunset.Equal(gotSunset))
+	require.Equal(t, []string{"use bar instead"}, ResponseWarnings(response))
+	// Only logged once per operation.
+	require.Equal(t, 1, strings.Count(logs.String(), "operation is deprecated"))
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "baz"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	response, err = result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	deprecated, _ = ResponseDeprecation(response)
+	require.False(t, deprecated)
+	require.Equal(t, 1, strings.Count(logs.String(), "operation is deprecated"))
+}
+
+func TestDeprecatedOperations_Failures(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler: &UnimplementedHandler{},
+		DeprecatedOperations: map[string]OperationDeprecation{
+			"foo": {},
+		},
+	})
+	for _, request := range []*http.Request{
+		httptest.NewRequest("POST", "/foo", nil),
+		httptest.NewRequest("GET", "/foo/id", nil),
+		httptest.NewRequest("GET", "/foo/id/result", nil),
+		httptest.NewRequest("POST", "/foo/id/cancel", nil),
+		httptest.NewRequest("GET", "/foo/id/events", nil),
+	} {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		require.Equal(t, http.StatusNotImplemented, writer.Code)
+		require.Equal(t, "true", writer.Header().Get(headerDeprecation))
+		require.Empty(t, writer.Header().Get(headerSunset))
+		require.Empty(t, writer.Header().Values(headerWarning))
+	}
+}
+
+func TestDeprecatedOperations_CaseInsensitive(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:                   &UnimplementedHandler{},
+		CaseInsensitiveOperations: true,
+		DeprecatedOperations: map[string]OperationDeprecation{
+			"Foo": {},
+		},
+	})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/FOO", nil))
+	require.Equal(t, "true", writer.Header().Get(headerDeprecation))
+}
@@ -0,0 +1,140 @@
+package nexus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Header for conveying a digest of a request body, see RFC 3230.
+const headerDigest = "Digest"
+
+const digestAlgorithmSHA256 = "sha-256"
+
+// formatSHA256Digest formats a Digest header value for the given body.
+func formatSHA256Digest(sum []byte) string {
+	return digestAlgorithmSHA256 + "=" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// parseSHA256Digest extracts the sha-256 checksum from a Digest header value.
+// The header may contain multiple comma separated digests, digests with unsupported algorithms are ignored.
+func parseSHA256Digest(value string) ([]byte, error) {
+	for _, digest := range strings.Split(value, ",") {
+		algorithm, encoded, found := strings.Cut(strings.TrimSpace(digest), "=")
+		if !found || !strings.EqualFold(algorithm, digestAlgorithmSHA256) {
+			continue
+		}
+		sum, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("invalid %s digest: %q", digestAlgorithmSHA256, encoded)
+		}
+		return sum, nil
+	}
+	return nil, fmt.Errorf("no supported digest algorithm in: %q", value)
+}
+
+// digestVerifyingReader streams the underlying reader through a hash and compares the result to an expected checksum
+// once the underlying reader is exhausted.
+type digestVerifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+}
+
+func newDigestVerifyingReader(body io.ReadCloser, expected []byte) *digestVerifyingReader {
+	return &digestVerifyingReader{ReadCloser: body, hash: sha256.New(), expected: expected}
+}
+
+func (r *digestVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if sum := r.hash.Sum(nil); !bytes.Equal(sum, r.expected) {
+			return n, newBadRequestError("request body digest mismatch, expected %s, got %s", formatSHA256Digest(r.expected), formatSHA256Digest(sum))
+		}
+	}
+	return n, err
+}
+
+// verifyRequestBodyDigest wraps the request body with a reader that fails with a bad request [HandlerError] if the
+// body does not match the request's Digest header. Requests without a Digest header are left as is.
+func verifyRequestBodyDigest(request *http.Request) error {
+	value := request.Header.Get(headerDigest)
+	if value == "" {
+		return nil
+	}
+	expected, err := parseSHA256Digest(value)
+	if err != nil {
+		return newBadRequestError("invalid %s header: %v", headerDigest, err)
+	}
+	request.Body = newDigestVerifyingReader(request.Body, expected)
+	return nil
+}
+
+// ErrResultDigestMismatch is returned from reading the body of a successful operation response when the body does not
+// match the digest sent by the handler, see [ClientOptions.VerifyResultDigest].
+var ErrResultDigestMismatch = errors.New("nexus: result digest mismatch")
+
+// resultDigestWriter computes the digest of a result as it is written, see [HandlerOptions.SendResultDigest].
+type resultDigestWriter struct {
+	hash hash.Hash
+}
+
+// prepare declares the Digest trailer and returns a reader that hashes the result as it's read from source.
+func (w *resultDigestWriter) prepare(header http.Header, source io.Reader) io.Reader {
+	// HTTP/1.1 trailers require chunked encoding.
+	header.Del("Content-Length")
+	header.Add("Trailer", headerDigest)
+	w.hash = sha256.New()
+	return io.TeeReader(source, w.hash)
+}
+
+// finish sets the Digest trailer once the result has been written in its entirety.
+func (w *resultDigestWriter) finish(header http.Header) {
+	header.Set(headerDigest, formatSHA256Digest(w.hash.Sum(nil)))
+}
+
+// resultDigestVerifyingReader verifies the body of a successful operation response against the digest sent by the
+// handler in the Digest trailer, or header, once the body is exhausted.
+type resultDigestVerifyingReader struct {
+	io.ReadCloser
+	response *http.Response
+	hash     hash.Hash
+}
+
+func (r *resultDigestVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		// Trailers are populated once the body is read to EOF.
+		value := r.response.Trailer.Get(headerDigest)
+		if value == "" {
+			value = r.response.Header.Get(headerDigest)
+		}
+		if value == "" {
+			// The handler did not send a digest.
+			return n, err
+		}
+		expected, parseErr := parseSHA256Digest(value)
+		if parseErr != nil {
+			return n, fmt.Errorf("%w: %w", ErrResultDigestMismatch, parseErr)
+		}
+		if sum := r.hash.Sum(nil); !bytes.Equal(sum, expected) {
+			return n, fmt.Errorf("%w: expected %s, got %s", ErrResultDigestMismatch, formatSHA256Digest(expected), formatSHA256Digest(sum))
+		}
+	}
+	return n, err
+}
+
+// applyResultDigestVerification wraps the body of a successful response per [ClientOptions.VerifyResultDigest].
+func (c *Client) applyResultDigestVerification(response *http.Response) {
+	if c.options.VerifyResultDigest {
+		response.Body = &resultDigestVerifyingReader{ReadCloser: response.Body, response: response, hash: sha256.New()}
+	}
+}
@@ -0,0 +1,164 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type bodyReadingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *bodyReadingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	b, err := io.ReadAll(request.HTTPRequest.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &OperationResponseSync{Body: bytes.NewReader(b)}, nil
+}
+
+func TestBodyDigest_Verified(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}, VerifyBodyDigest: true}, ClientOptions{SendBodyDigest: true})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Body:      bytes.NewReader([]byte("input")),
+	})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte("input"), body)
+}
+
+func TestBodyDigest_Mismatch(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}, VerifyBodyDigest: true}, ClientOptions{})
+	defer teardown()
+
+	sum := sha256.Sum256([]byte("something else"))
+	_, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Header:    http.Header{headerDigest: []string{formatSHA256Digest(sum[:])}},
+		Body:      bytes.NewReader([]byte("input")),
+	})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	require.Contains(t, unexpectedResponseError.Failure.Message, "digest mismatch")
+}
+
+func TestBodyDigest_InvalidHeader(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}, VerifyBodyDigest: true}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Header:    http.Header{headerDigest: []string{"md5=abc"}},
+		Body:      bytes.NewReader([]byte("input")),
+	})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+}
+
+type compressedResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *compressedResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	response, err := NewOperationResponseSync(strings.Repeat("result", 100))
+	if err != nil {
+		return nil, err
+	}
+	response.Compress = true
+	return response, nil
+}
+
+// corruptingReader flips the first byte read, simulating corruption by an intermediary.
+type corruptingReader struct {
+	io.ReadCloser
+	corrupted bool
+}
+
+func (r *corruptingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !r.corrupted {
+		p[0] ^= 0xff
+		r.corrupted = true
+	}
+	return n, err
+}
+
+func TestResultDigest_Verified(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}, SendResultDigest: true}, ClientOptions{VerifyResultDigest: true})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Body: bytes.NewReader([]byte("input"))})
+	require.NoError(t, err)
+	response := result.Successful
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte("input"), body)
+	sum := sha256.Sum256([]byte("input"))
+	require.Equal(t, formatSHA256Digest(sum[:]), response.Trailer.Get(headerDigest))
+}
+
+func TestResultDigest_Compressed(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &compressedResultHandler{}, SendResultDigest: true}, ClientOptions{VerifyResultDigest: true})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, `"`+strings.Repeat("result", 100)+`"`, string(body))
+	require.NotEmpty(t, response.Trailer.Get(headerDigest))
+}
+
+func TestResultDigest_Mismatch(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}, SendResultDigest: true}, ClientOptions{
+		VerifyResultDigest: true,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			response.Body = &corruptingReader{ReadCloser: response.Body}
+			return response, nil
+		},
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Body: bytes.NewReader([]byte("input"))})
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	_, err = io.ReadAll(result.Successful.Body)
+	require.ErrorIs(t, err, ErrResultDigestMismatch)
+}
+
+func TestResultDigest_NotSent(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}}, ClientOptions{VerifyResultDigest: true})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Body: bytes.NewReader([]byte("input"))})
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	body, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte("input"), body)
+	require.Empty(t, result.Successful.Trailer.Get(headerDigest))
+}
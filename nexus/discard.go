@@ -0,0 +1,34 @@
+package nexus
+
+import (
+	"io"
+	"net/http"
+)
+
+// Max number of bytes to drain from a discarded response body. Reading larger bodies to EOF costs more than
+// establishing a new connection.
+const maxDiscardBytes = 256 << 10
+
+// DiscardResponse drains and closes the body of a response whose content the caller intends to ignore, e.g. the result
+// of a successful operation started for its side effects, allowing the underlying connection to be reused. Closing a
+// body without reading it to EOF closes the connection instead.
+//
+// Bodies larger than 256 KiB are closed without being read in their entirety. Returns the error from reading the body,
+// if any.
+func DiscardResponse(response *http.Response) error {
+	_, err := io.CopyN(io.Discard, response.Body, maxDiscardBytes)
+	if err == io.EOF {
+		err = nil
+	}
+	response.Body.Close()
+	return err
+}
+
+// Discard drains and closes the body of a successful result the caller intends to ignore, see [DiscardResponse]. It is
+// a no-op for results of asynchronous operations, their response bodies are consumed by [Client.StartOperation].
+func (r *StartOperationResult) Discard() error {
+	if r.Successful == nil {
+		return nil
+	}
+	return DiscardResponse(r.Successful)
+}
@@ -0,0 +1,81 @@
+package nexus
+
+import (
+	"context"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type syncOrAsyncHandler struct {
+	UnimplementedHandler
+}
+
+func (h *syncOrAsyncHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "async" {
+		return &OperationResponseAsync{OperationID: "a/sync"}, nil
+	}
+	return NewOperationResponseSync(strings.Repeat("x", 1024))
+}
+
+// connectionTracker records whether each request of a client was sent on a reused connection.
+type connectionTracker struct {
+	mu     sync.Mutex
+	reused []bool
+}
+
+func (c *connectionTracker) context(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.reused = append(c.reused, info.Reused)
+		},
+	})
+}
+
+func (c *connectionTracker) take() []bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reused := c.reused
+	c.reused = nil
+	return reused
+}
+
+func TestDiscard(t *testing.T) {
+	ctx, client, teardown := setup(t, &syncOrAsyncHandler{})
+	defer teardown()
+	tracker := &connectionTracker{}
+	ctx = tracker.context(ctx)
+
+	// Establish a connection.
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "async"})
+	require.NoError(t, err)
+	require.NoError(t, result.Discard())
+	tracker.take()
+
+	// Async response bodies are consumed by the client, the connection is reused.
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "async"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "async"})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true}, tracker.take())
+
+	// Discarded sync results free up the connection.
+	for i := 0; i < 2; i++ {
+		result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "sync"})
+		require.NoError(t, err)
+		require.NotNil(t, result.Successful)
+		require.NoError(t, result.Discard())
+	}
+	require.Equal(t, []bool{true, true}, tracker.take())
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "sync"})
+	require.NoError(t, err)
+	require.NoError(t, DiscardResponse(result.Successful))
+	require.Equal(t, []bool{true}, tracker.take())
+}
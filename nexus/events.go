@@ -0,0 +1,171 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// OperationEvent is an event emitted by an operation during its execution, e.g. a log line of a build or a step of a
+// migration. Events are streamed separately from the operation's final result.
+type OperationEvent struct {
+	// Time the event was emitted at.
+	Time time.Time `json:"time"`
+	// Type of the event, interpretation is up to the handler and caller, e.g. "log" or "progress". Optional.
+	Type string `json:"type,omitempty"`
+	// A human readable message. Optional.
+	Message string `json:"message,omitempty"`
+	// Additional JSON serializable structured data. Optional.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Set by [OperationHandle.StreamEvents] on a final event delivered before closing the channel if the stream failed,
+	// e.g. when the connection dropped or an event couldn't be decoded, in which case the other fields are zero. Not
+	// set by handlers.
+	Err error `json:"-"`
+}
+
+// StreamOperationEventsRequest is input for Handler.StreamOperationEvents.
+type StreamOperationEventsRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
+	// Operation name.
+	Operation string
+	// Operation ID as originally generated by a Handler.
+	OperationID string
+	// The original HTTP request.
+	HTTPRequest *http.Request
+}
+
+func (h *httpHandler) streamOperationEvents(writer http.ResponseWriter, request *http.Request) {
+	parsed, err := h.parseOperationPath(request, true, "events")
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	h.setDeprecationHeaders(writer.Header(), parsed.operation)
+	handlerRequest := &StreamOperationEventsRequest{
+		Service:     parsed.service,
+		Operation:   parsed.operation,
+		OperationID: parsed.operationID,
+		HTTPRequest: request,
+	}
+
+	ctx := request.Context()
+	events, err := h.options.Handler.StreamOperationEvents(ctx, handlerRequest)
+	err = h.checkUnimplemented(err, parsed.operation, "StreamOperationEvents")
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+
+	controller := http.NewResponseController(writer)
+	writer.Header().Set(headerContentType, contentTypeNDJSON)
+	writer.WriteHeader(http.StatusOK)
+	// Flush the headers to let the client know the stream has started, flushing is best effort since not all writers
+	// support it.
+	_ = controller.Flush()
+	encoder := json.NewEncoder(writer)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(event); err != nil {
+				h.logger.Error("failed to write operation event", "error", err)
+				return
+			}
+			_ = controller.Flush()
+		}
+	}
+}
+
+// StreamOperationEventsOptions are options for [OperationHandle.StreamEvents].
+type StreamOperationEventsOptions struct {
+	// Header to attach to the HTTP request. Optional.
+	Header http.Header
+}
+
+// StreamEvents streams events emitted by an operation during its execution, issuing a network request to the service
+// handler.
+//
+// The returned channel is closed when the handler ends the stream, the stream fails, or ctx is canceled. Cancel ctx to
+// stop streaming and release the underlying connection. A stream that fails ends with an event with Err set,
+// distinguishing it from a stream the handler ended.
+//
+// Streams are long lived, [ClientOptions.OperationTimeout] does not apply to them.
+//
+// Fails with an [UnexpectedResponseError] if the handler does not support streaming events, with the response status
+// set to 501.
+//
+// The call is reported to [ClientOptions.MetricsHandler] once the stream is established, its duration does not include
+// reading events.
+func (h *OperationHandle) StreamEvents(ctx context.Context, options StreamOperationEventsOptions) (<-chan OperationEvent, error) {
+	ctx, record := h.client.recordCall(ctx, h.Operation, ClientMethodStreamEvents)
+	events, err := h.streamEvents(ctx, options)
+	record(ClientCallOutcomeSuccessful, err)
+	return events, h.client.mapError(err)
+}
+
+func (h *OperationHandle) streamEvents(ctx context.Context, options StreamOperationEventsOptions) (<-chan OperationEvent, error) {
+	url := h.operationURL("events")
+	if err := h.client.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := h.client.newRequest(ctx, h.Operation, "GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if options.Header != nil {
+		request.Header = options.Header.Clone()
+	}
+
+	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
+	response, err := h.client.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		body, err := readAndReplaceBody(response)
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	}
+
+	events := make(chan OperationEvent)
+	go func() {
+		defer close(events)
+		defer response.Body.Close()
+		decoder := json.NewDecoder(response.Body)
+		for {
+			var event OperationEvent
+			if err := decoder.Decode(&event); err != nil {
+				if errors.Is(err, io.EOF) || ctx.Err() != nil {
+					return
+				}
+				event = OperationEvent{Err: h.client.mapError(fmt.Errorf("failed to read event stream: %w", err))}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+				}
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,99 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type eventsHandler struct {
+	UnimplementedHandler
+}
+
+func (h *eventsHandler) StreamOperationEvents(ctx context.Context, request *StreamOperationEventsRequest) (<-chan OperationEvent, error) {
+	if request.OperationID != "build" {
+		return nil, &HandlerError{StatusCode: http.StatusNotFound, Failure: &Failure{Message: "operation not found"}}
+	}
+	events := make(chan OperationEvent)
+	go func() {
+		defer close(events)
+		for _, message := range []string{"compiling", "linking", "done"} {
+			select {
+			case events <- OperationEvent{Time: time.Unix(0, 0).UTC(), Type: "log", Message: message}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func TestStreamEvents(t *testing.T) {
+	ctx, client, teardown := setup(t, &eventsHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "build")
+	require.NoError(t, err)
+	events, err := handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.NoError(t, err)
+	var messages []string
+	for event := range events {
+		require.NoError(t, event.Err)
+		require.Equal(t, "log", event.Type)
+		require.Equal(t, time.Unix(0, 0).UTC(), event.Time)
+		messages = append(messages, event.Message)
+	}
+	require.Equal(t, []string{"compiling", "linking", "done"}, messages)
+
+	handle, err = client.NewHandle("foo", "unknown")
+	require.NoError(t, err)
+	_, err = handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotFound, unexpectedError.Response.StatusCode)
+	require.Equal(t, "operation not found", unexpectedError.Failure.Message)
+}
+
+func TestStreamEvents_NotImplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &UnimplementedHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	_, err = handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedError.Response.StatusCode)
+}
+
+func TestStreamEvents_Failure(t *testing.T) {
+	// The stream is cut off mid event.
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(headerContentType, contentTypeNDJSON)
+		_, _ = writer.Write([]byte(`{"message":"compiling"}` + "\n" + `{"message":"link`))
+	}))
+	defer server.Close()
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL})
+	require.NoError(t, err)
+	handle, err := client.NewHandle("foo", "build")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	events, err := handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.NoError(t, err)
+	var received []OperationEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	require.Len(t, received, 2)
+	require.Equal(t, "compiling", received[0].Message)
+	require.NoError(t, received[0].Err)
+	require.ErrorIs(t, received[1].Err, io.ErrUnexpectedEOF)
+	require.Empty(t, received[1].Message)
+}
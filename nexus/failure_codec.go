@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// A FailureCodec serializes [Failure] structs in failure responses, e.g. as protobuf google.rpc.Status messages for
+// services that use protobuf end to end.
+//
+// Failures are serialized as JSON by default, see [HandlerOptions.FailureCodec] and [ClientOptions.FailureCodecs].
+type FailureCodec interface {
+	// ContentType returns the media type set in the Content-Type header of responses carrying failures serialized by
+	// this codec, e.g. application/json.
+	ContentType() string
+	// Marshal serializes a failure.
+	Marshal(*Failure) ([]byte, error)
+	// Unmarshal deserializes a failure.
+	Unmarshal([]byte, *Failure) error
+}
+
+type jsonFailureCodec struct{}
+
+func (jsonFailureCodec) ContentType() string {
+	return contentTypeJSON
+}
+
+func (jsonFailureCodec) Marshal(failure *Failure) ([]byte, error) {
+	return json.Marshal(failure)
+}
+
+func (jsonFailureCodec) Unmarshal(b []byte, failure *Failure) error {
+	return json.Unmarshal(b, failure)
+}
+
+// failureCodecForResponse returns the codec matching the media type of a response's Content-Type header, falling back
+// to JSON. Returns false if no codec matches.
+func (c *Client) failureCodecForResponse(response *http.Response) (FailureCodec, bool) {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get(headerContentType))
+	if err != nil {
+		return nil, false
+	}
+	for _, codec := range c.options.FailureCodecs {
+		if codec.ContentType() == mediaType {
+			return codec, true
+		}
+	}
+	if mediaType == contentTypeJSON {
+		return jsonFailureCodec{}, true
+	}
+	return nil, false
+}
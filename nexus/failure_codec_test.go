@@ -0,0 +1,73 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// textFailureCodec serializes failures as plain text messages, standing in for a custom (e.g. protobuf) codec.
+type textFailureCodec struct{}
+
+func (textFailureCodec) ContentType() string {
+	return "text/x-failure"
+}
+
+func (textFailureCodec) Marshal(failure *Failure) ([]byte, error) {
+	return []byte("failure: " + failure.Message), nil
+}
+
+func (textFailureCodec) Unmarshal(b []byte, failure *Failure) error {
+	message, found := strings.CutPrefix(string(b), "failure: ")
+	if !found {
+		return errors.New("invalid failure")
+	}
+	failure.Message = message
+	return nil
+}
+
+type failingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *failingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "bad" {
+		return nil, newBadRequestError("bad input")
+	}
+	return nil, &UnsuccessfulOperationError{State: OperationStateFailed, Failure: Failure{Message: "operation failed"}}
+}
+
+func TestFailureCodec(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &failingHandler{}, FailureCodec: textFailureCodec{}}, ClientOptions{
+		FailureCodecs: []FailureCodec{textFailureCodec{}},
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateFailed, unsuccessfulError.State)
+	require.Equal(t, "operation failed", unsuccessfulError.Failure.Message)
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "bad"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+	require.Equal(t, "text/x-failure", unexpectedError.Response.Header.Get(headerContentType))
+	require.Equal(t, "bad input", unexpectedError.Failure.Message)
+}
+
+func TestFailureCodec_ClientWithoutCodec(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &failingHandler{}, FailureCodec: textFailureCodec{}}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, `invalid response content type: "text/x-failure"`, unexpectedError.Message)
+	require.Nil(t, unexpectedError.Failure)
+}
@@ -2,8 +2,11 @@ package nexus
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -67,3 +70,98 @@ func TestGetInfoHandleFromClientNoHeader(t *testing.T) {
 	require.Equal(t, handle.ID, info.ID)
 	require.Equal(t, OperationStateCanceled, info.State)
 }
+
+type progressInfoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *progressInfoHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	progress := 0.5
+	return &OperationInfo{
+		ID:       request.OperationID,
+		State:    OperationStateRunning,
+		Progress: &progress,
+		Message:  "halfway there",
+	}, nil
+}
+
+func TestGetInfo_Progress(t *testing.T) {
+	ctx, client, teardown := setup(t, &progressInfoHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	require.NotNil(t, info.Progress)
+	require.Equal(t, 0.5, *info.Progress)
+	require.Equal(t, "halfway there", info.Message)
+}
+
+func TestGetInfo_NoProgress(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithInfoHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("escape/me", "needs /URL/ escaping")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Nil(t, info.Progress)
+	require.Empty(t, info.Message)
+}
+
+type advancingProgressHandler struct {
+	UnimplementedHandler
+	polls atomic.Int32
+}
+
+func (h *advancingProgressHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	progress := float64(h.polls.Add(1)) / 4
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning, Progress: &progress}, nil
+}
+
+func TestWaitUntil(t *testing.T) {
+	handler := &advancingProgressHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	info, err := handle.WaitUntil(ctx, func(info *OperationInfo) bool {
+		return info.Progress != nil && *info.Progress >= 0.75
+	}, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 0.75, *info.Progress)
+	require.Equal(t, int32(3), handler.polls.Load())
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	_, err = handle.WaitUntil(timeoutCtx, func(info *OperationInfo) bool { return false }, time.Millisecond*10)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitUntil_ErrorMapper(t *testing.T) {
+	var mapped []error
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &deadlineHandler{deadline: time.Now().Add(time.Millisecond * 100)}}, ClientOptions{
+		ErrorMapper: func(err error) error {
+			mapped = append(mapped, err)
+			return fmt.Errorf("mapped: %w", err)
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	_, err = handle.WaitUntil(canceledCtx, func(info *OperationInfo) bool {
+		cancel()
+		return false
+	}, time.Hour)
+	require.EqualError(t, err, "mapped: context canceled")
+
+	_, err = handle.WaitUntil(ctx, func(info *OperationInfo) bool { return false }, time.Millisecond*10)
+	require.ErrorIs(t, err, ErrOperationDeadlineExceeded)
+	require.EqualError(t, err, "mapped: "+ErrOperationDeadlineExceeded.Error())
+	require.Len(t, mapped, 2)
+}
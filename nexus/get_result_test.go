@@ -5,6 +5,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,9 +16,18 @@ type asyncWithResultHandler struct {
 	UnimplementedHandler
 	timesToBlock int
 	resultError  error
+	mu           sync.Mutex
 	requests     []*GetOperationResultRequest
 }
 
+// recordedRequests returns the get result requests received so far, requests may still be in flight after a client
+// gives up on them.
+func (h *asyncWithResultHandler) recordedRequests() []*GetOperationResultRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*GetOperationResultRequest(nil), h.requests...)
+}
+
 func (h *asyncWithResultHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
 	return &OperationResponseAsync{
 		OperationID: "a/sync",
@@ -35,7 +45,10 @@ func (h *asyncWithResultHandler) getResult(request *GetOperationResultRequest) (
 }
 
 func (h *asyncWithResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	h.mu.Lock()
 	h.requests = append(h.requests, request)
+	block := len(h.requests) <= h.timesToBlock
+	h.mu.Unlock()
 
 	if request.HTTPRequest.Header.Get("User-Agent") != userAgent {
 		return nil, newBadRequestError("invalid 'User-Agent' header: %q", request.HTTPRequest.Header.Get("User-Agent"))
@@ -52,12 +65,16 @@ func (h *asyncWithResultHandler) GetOperationResult(ctx context.Context, request
 			return nil, newBadRequestError("context deadline unset")
 		}
 		timeout := time.Until(deadline)
-		diff := (getResultMaxTimeout - timeout).Abs()
+		expectedTimeout := getResultMaxTimeout
+		if requestTimeout, err := time.ParseDuration(request.HTTPRequest.Header.Get(headerRequestTimeout)); err == nil && requestTimeout > 0 && requestTimeout < expectedTimeout {
+			expectedTimeout = requestTimeout
+		}
+		diff := (expectedTimeout - timeout).Abs()
 		if diff > time.Millisecond*100 {
 			return nil, newBadRequestError("context deadline invalid, timeout: %v", timeout)
 		}
 	}
-	if len(h.requests) <= h.timesToBlock {
+	if block {
 		ctx, cancel := context.WithTimeout(ctx, request.Wait)
 		defer cancel()
 		<-ctx.Done()
@@ -160,3 +177,174 @@ func TestPeekResult_Canceled(t *testing.T) {
 	require.ErrorAs(t, err, &unsuccessfulOperationError)
 	require.Equal(t, OperationStateCanceled, unsuccessfulOperationError.State)
 }
+
+func TestWaitResult_TimeoutSource(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithResultHandler{timesToBlock: 1000})
+	defer teardown()
+
+	type testcase struct {
+		name           string
+		requestTimeout string
+		expectedSource string
+	}
+	cases := []testcase{
+		{
+			name:           "server",
+			expectedSource: timeoutSourceServer,
+		},
+		{
+			name:           "server timeout shorter than requested",
+			requestTimeout: "10s",
+			expectedSource: timeoutSourceServer,
+		},
+		{
+			name:           "invalid requested timeout ignored",
+			requestTimeout: "invalid",
+			expectedSource: timeoutSourceServer,
+		},
+		{
+			name:           "zero requested timeout ignored",
+			requestTimeout: "0s",
+			expectedSource: timeoutSourceServer,
+		},
+		{
+			name:           "negative requested timeout ignored",
+			requestTimeout: "-1s",
+			expectedSource: timeoutSourceServer,
+		},
+		{
+			name:           "client",
+			requestTimeout: "100ms",
+			expectedSource: timeoutSourceClient,
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			url := client.serviceBaseURL.JoinPath("foo", "a%2Fsync", "result").String() + "?wait=1s"
+			request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+			require.NoError(t, err)
+			request.Header.Set(headerUserAgent, userAgent)
+			if c.requestTimeout != "" {
+				request.Header.Set(headerRequestTimeout, c.requestTimeout)
+			}
+			response, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			_, err = io.ReadAll(response.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusRequestTimeout, response.StatusCode)
+			require.Equal(t, c.expectedSource, response.Header.Get(headerTimeoutSource))
+		})
+	}
+}
+
+func TestWaitResult_ClientTimeoutSourceStopsPolling(t *testing.T) {
+	handler := asyncWithResultHandler{timesToBlock: 1000}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &handler}, ClientOptions{
+		// Detach requests from the call's context for the handler's timeout response to win the race against the
+		// client's deadline.
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return http.DefaultClient.Do(request.WithContext(context.WithoutCancel(request.Context())))
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+
+	// The handler times out the first poll at the requested timeout, the client should not issue another poll request.
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*200)
+	defer cancel()
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+	require.ErrorIs(t, err, ErrRequestTimeoutExceeded)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	requests := handler.recordedRequests()
+	require.Equal(t, 1, len(requests))
+	requestTimeout, err := time.ParseDuration(requests[0].HTTPRequest.Header.Get(headerRequestTimeout))
+	require.NoError(t, err)
+	require.InDelta(t, time.Millisecond*200, requestTimeout, float64(time.Millisecond*50))
+}
+
+type partialResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *partialResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	response, err := NewOperationResponseSync([]string{"first page"})
+	if err != nil {
+		return nil, err
+	}
+	response.Partial = request.OperationID == "partial"
+	return response, nil
+}
+
+func TestGetResult_Partial(t *testing.T) {
+	ctx, client, teardown := setup(t, &partialResultHandler{})
+	defer teardown()
+
+	for _, id := range []string{"partial", "complete"} {
+		handle, err := client.NewHandle("foo", id)
+		require.NoError(t, err)
+		response, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Second})
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, id == "partial", IsPartialResult(response))
+	}
+}
+
+type deadlineHandler struct {
+	UnimplementedHandler
+	deadline time.Time
+}
+
+func (h *deadlineHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return &OperationResponseAsync{OperationID: "a/sync", Deadline: h.deadline}, nil
+}
+
+func (h *deadlineHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	if request.Wait > 0 {
+		<-ctx.Done()
+	}
+	return nil, ErrOperationStillRunning
+}
+
+func (h *deadlineHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning, Deadline: &h.deadline}, nil
+}
+
+func TestGetResult_OperationDeadline(t *testing.T) {
+	for _, offset := range []time.Duration{time.Millisecond * 200, -time.Second} {
+		deadline := time.Now().Add(offset).UTC()
+		ctx, client, teardown := setup(t, &deadlineHandler{deadline: deadline})
+		defer teardown()
+
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+		require.NoError(t, err)
+		handle := result.Pending
+		reported, ok := handle.Deadline()
+		require.True(t, ok)
+		require.True(t, deadline.Equal(reported))
+
+		// Without waiting the handler's response is returned as is.
+		_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+		require.ErrorIs(t, err, ErrOperationStillRunning)
+
+		_, err = handle.GetResult(ctx, GetOperationResultOptions{Wait: time.Minute})
+		require.ErrorIs(t, err, ErrOperationDeadlineExceeded)
+		require.False(t, time.Now().Before(deadline))
+	}
+}
+
+func TestWaitUntil_OperationDeadline(t *testing.T) {
+	ctx, client, teardown := setup(t, &deadlineHandler{deadline: time.Now().Add(time.Millisecond * 100)})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	_, ok := handle.Deadline()
+	require.False(t, ok)
+	_, err = handle.WaitUntil(ctx, func(info *OperationInfo) bool { return false }, time.Millisecond*20)
+	require.ErrorIs(t, err, ErrOperationDeadlineExceeded)
+}
@@ -13,11 +13,53 @@ const getResultContextPadding = time.Second * 5
 
 // An OperationHandle is used to cancel operations and get their result and status.
 type OperationHandle struct {
+	// Name of the service hosting this handle's operation. Empty unless the service was specified when starting the
+	// operation or in [ClientOptions.Service].
+	Service string
 	// Name of the Operation this handle represents.
 	Operation string
 	// Handler generated ID for this handle's operation.
 	ID     string
 	client *Client
+	// Location of the operation resource as reported by the handler in the start operation response, if any.
+	location *url.URL
+	// Base URL of the service instance the operation was started on when balancing across
+	// [ClientOptions.ServiceBaseURLs], nil otherwise.
+	baseURL *url.URL
+	// Deadline of the operation as reported by the handler in the start operation response, if any.
+	deadline time.Time
+}
+
+// Deadline returns the time after which the handler gives up on this handle's operation, as reported when the
+// operation was started, see [OperationInfo.Deadline]. Returns false if no deadline was reported.
+func (h *OperationHandle) Deadline() (time.Time, bool) {
+	return h.deadline, !h.deadline.IsZero()
+}
+
+// deadlinePassed returns true if the operation's deadline is known and has passed.
+func (h *OperationHandle) deadlinePassed() bool {
+	return !h.deadline.IsZero() && !time.Now().Before(h.deadline)
+}
+
+// operationURL constructs the URL for this handle's operation, pinned to the service instance the operation was started
+// on, if any.
+func (h *OperationHandle) operationURL(elem ...string) *url.URL {
+	if h.baseURL != nil {
+		return joinOperationURL(h.baseURL, h.Service, h.Operation, append([]string{h.ID}, elem...)...)
+	}
+	return h.client.operationURL(h.Service, h.Operation, append([]string{h.ID}, elem...)...)
+}
+
+// URL returns the URL of the operation resource represented by this handle.
+//
+// For handles obtained from [Client.StartOperation], this is the Location reported by the handler when available.
+// Otherwise, the URL is constructed from the client's configuration.
+func (h *OperationHandle) URL() *url.URL {
+	if h.location != nil {
+		u := *h.location
+		return &u
+	}
+	return h.operationURL()
 }
 
 // GetOperationInfoOptions are options for [OperationHandle.GetInfo].
@@ -28,8 +70,20 @@ type GetOperationInfoOptions struct {
 
 // GetInfo gets operation information, issuing a network request to the service handler.
 func (h *OperationHandle) GetInfo(ctx context.Context, options GetOperationInfoOptions) (*OperationInfo, error) {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID))
-	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation)
+	defer cancel()
+	ctx, record := h.client.recordCall(ctx, h.Operation, ClientMethodGetOperationInfo)
+	info, err := h.getInfo(ctx, options)
+	record(ClientCallOutcomeSuccessful, err)
+	return info, h.client.mapError(err)
+}
+
+func (h *OperationHandle) getInfo(ctx context.Context, options GetOperationInfoOptions) (*OperationInfo, error) {
+	url := h.operationURL()
+	if err := h.client.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := h.client.newRequest(ctx, h.Operation, "GET", url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +92,8 @@ func (h *OperationHandle) GetInfo(ctx context.Context, options GetOperationInfoO
 	}
 
 	request.Header.Set(headerUserAgent, userAgent)
-	response, err := h.client.options.HTTPCaller(request)
+	setBaggageHeader(ctx, request.Header)
+	response, err := h.client.sendIdempotentRequest(request)
 	if err != nil {
 		return nil, err
 	}
@@ -50,10 +105,52 @@ func (h *OperationHandle) GetInfo(ctx context.Context, options GetOperationInfoO
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, h.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
 	}
 
-	return operationInfoFromResponse(response, body)
+	return h.client.operationInfoFromResponse(response, body)
+}
+
+// Default polling interval for [OperationHandle.WaitUntil].
+const defaultWaitUntilInterval = time.Second
+
+// WaitUntil polls for operation information via [OperationHandle.GetInfo] until predicate returns true, returning the
+// information that satisfied the predicate.
+//
+// The first poll is issued immediately, subsequent polls are issued every interval, which defaults to one second if
+// not positive. Returns ctx's error if ctx is done before the predicate is satisfied, and any error from polling.
+// Returns [ErrOperationDeadlineExceeded] once the operation's deadline, as reported in [OperationInfo.Deadline], passes
+// before the predicate is satisfied. All errors are translated with [ClientOptions.ErrorMapper], polling errors only
+// once.
+func (h *OperationHandle) WaitUntil(ctx context.Context, predicate func(*OperationInfo) bool, interval time.Duration) (*OperationInfo, error) {
+	if interval <= 0 {
+		interval = defaultWaitUntilInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		info, err := h.GetInfo(ctx, GetOperationInfoOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if predicate(info) {
+			return info, nil
+		}
+		if info.Deadline != nil && !time.Now().Before(*info.Deadline) {
+			return nil, h.client.mapError(ErrOperationDeadlineExceeded)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, h.client.mapError(ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsPartialResult returns true if a result response, e.g. from [OperationHandle.GetResult], carries a partial result as
+// indicated by the handler via [OperationResponseSync.Partial].
+func IsPartialResult(response *http.Response) bool {
+	return response.Header.Get(headerResultPartial) == "true"
 }
 
 // GetOperationResultOptions are Options for [OperationHandle.GetResult].
@@ -62,6 +159,13 @@ type GetOperationResultOptions struct {
 	Header http.Header
 	// Duration to wait for operation completion. Zero or negative value implies no wait.
 	Wait time.Duration
+	// Opaque token of the result page to get, as returned by [NextPageToken]. Empty for the first page. Optional.
+	PageToken string
+	// Maximum number of times to resume reading the result body after a read failure, e.g. due to a dropped
+	// connection. Reading is resumed from the last byte read by issuing a request with a Range header. Only applies to
+	// results served with a known length by handlers that support range requests, as indicated by the Accept-Ranges
+	// header, see [OperationResponseSync]. Optional, zero disables resuming.
+	MaxResumes int
 }
 
 // GetResult gets the result of an operation, issuing a network request to the service handler.
@@ -73,16 +177,37 @@ type GetOperationResultOptions struct {
 // to long poll for the result issuing one or more requests until the provided wait period exceeds, in which case (nil,
 // [ErrOperationStillRunning]) is returned.
 //
-// The wait time is capped to the deadline of the provided context. Make sure to handle both context deadline errors and
-// [ErrOperationStillRunning].
+// The wait time is capped to the deadline of the provided context, which is sent to the handler as the requested
+// timeout of long poll requests. Make sure to handle both context deadline errors and [ErrOperationStillRunning]. When
+// the handler times out a request at the requested timeout, [ErrRequestTimeoutExceeded] is returned, which matches
+// [context.DeadlineExceeded], when it times out at its own max wait time, the client polls again.
 //
 // Note that the wait period is enforced by the server and may not be respected if the server is misbehaving. Set the
 // context deadline to the max allowed wait period to ensure this call returns in a timely fashion.
 //
+// When the handler reported a deadline for the operation when it was started, see [OperationHandle.Deadline], waiting
+// stops once the deadline passes, returning (nil, [ErrOperationDeadlineExceeded]).
+//
 // ⚠️ If a response is returned, its body must be read in its entirety and closed to free up the underlying connection.
 func (h *OperationHandle) GetResult(ctx context.Context, options GetOperationResultOptions) (*http.Response, error) {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID), "result")
-	request, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation)
+	ctx, record := h.client.recordCall(ctx, h.Operation, ClientMethodGetOperationResult)
+	response, err := h.getResult(ctx, options)
+	if err == nil {
+		cancelOnClose(response, cancel)
+	} else {
+		cancel()
+	}
+	record(ClientCallOutcomeSuccessful, err)
+	return response, h.client.mapError(err)
+}
+
+func (h *OperationHandle) getResult(ctx context.Context, options GetOperationResultOptions) (*http.Response, error) {
+	url := h.operationURL("result")
+	if err := h.client.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := h.client.newRequest(ctx, h.Operation, "GET", url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -90,46 +215,76 @@ func (h *OperationHandle) GetResult(ctx context.Context, options GetOperationRes
 		request.Header = options.Header.Clone()
 	}
 	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
+	if options.PageToken != "" {
+		q := request.URL.Query()
+		q.Set(queryPageToken, options.PageToken)
+		request.URL.RawQuery = q.Encode()
+	}
+	// Preserve any query params set by the URL transformer across poll requests.
+	baseQuery := request.URL.RawQuery
 
 	startTime := time.Now()
 	wait := options.Wait
 	for {
+		if wait > 0 && !h.deadline.IsZero() {
+			// Don't wait past the operation's deadline but give the handler some buffer to report the outcome of an
+			// operation that times out.
+			wait = min(wait, time.Until(h.deadline)+getResultContextPadding)
+		}
+		request.Header.Del(headerRequestTimeout)
 		if wait > 0 {
 			if deadline, set := ctx.Deadline(); set {
 				// Ensure we don't wait longer than the deadline but give some buffer prevent racing between wait and
 				// context deadline.
 				wait = min(wait, time.Until(deadline)+getResultContextPadding)
+				// Let the handler time out the request at the deadline rather than wait for its own max wait time.
+				if timeout := time.Until(deadline); timeout >= time.Millisecond {
+					request.Header.Set(headerRequestTimeout, fmt.Sprintf("%dms", timeout.Milliseconds()))
+				}
 			}
 
+			request.URL.RawQuery = baseQuery
 			q := request.URL.Query()
 			q.Set(queryWait, fmt.Sprintf("%dms", wait.Milliseconds()))
 			request.URL.RawQuery = q.Encode()
 		} else {
 			// We may reuse the request object multiple times and will need to reset the query when wait becomes 0 or
 			// negative.
-			request.URL.RawQuery = ""
+			request.URL.RawQuery = baseQuery
 		}
 
 		response, err := h.sendGetOperationRequest(ctx, request)
 		if err != nil {
+			if options.Wait > 0 && h.deadlinePassed() && (errors.Is(err, errOperationWaitTimeout) || errors.Is(err, ErrOperationStillRunning)) {
+				return nil, ErrOperationDeadlineExceeded
+			}
 			if wait > 0 && errors.Is(err, errOperationWaitTimeout) {
 				// TODO: Backoff a bit in case the server is continually returning timeouts due to some LB configuration
 				// issue to avoid blowing it up with repeated calls.
 				wait = options.Wait - time.Since(startTime)
 				continue
 			}
+			return nil, err
+		}
+		if options.MaxResumes > 0 {
+			// The operation has completed, no need to wait when resuming.
+			request.URL.RawQuery = baseQuery
+			h.makeResumable(request, response, options.MaxResumes)
 		}
-		return response, err
+		return response, nil
 	}
 }
 
 func (h *OperationHandle) sendGetOperationRequest(ctx context.Context, request *http.Request) (*http.Response, error) {
-	response, err := h.client.options.HTTPCaller(request)
+	response, err := h.client.sendIdempotentRequest(request)
 	if err != nil {
 		return nil, err
 	}
 
 	if response.StatusCode == http.StatusOK {
+		h.client.applyResponseBodyIdleTimeout(response)
+		h.client.applyResultDigestVerification(response)
 		return response, nil
 	}
 
@@ -141,15 +296,18 @@ func (h *OperationHandle) sendGetOperationRequest(ctx context.Context, request *
 
 	switch response.StatusCode {
 	case http.StatusRequestTimeout:
+		if response.Header.Get(headerTimeoutSource) == timeoutSourceClient {
+			return nil, ErrRequestTimeoutExceeded
+		}
 		return nil, errOperationWaitTimeout
 	case statusOperationRunning:
 		return nil, ErrOperationStillRunning
 	case statusOperationFailed:
-		state, err := getUnsuccessfulStateFromHeader(response, body)
+		state, err := h.client.getUnsuccessfulStateFromHeader(response, body)
 		if err != nil {
 			return nil, err
 		}
-		failure, err := failureFromResponse(response, body)
+		failure, err := h.client.failureFromResponse(response, body)
 		if err != nil {
 			return nil, err
 		}
@@ -158,7 +316,7 @@ func (h *OperationHandle) sendGetOperationRequest(ctx context.Context, request *
 			Failure: failure,
 		}
 	default:
-		return nil, newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+		return nil, h.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
 	}
 }
 
@@ -170,10 +328,24 @@ type CancelOperationOptions struct {
 
 // Cancel requests to cancel an asynchronous operation.
 //
-// Cancelation is asynchronous and may be not be respected by the operation's implementation.
+// Cancelation is asynchronous and may be not be respected by the operation's implementation. Both 202 Accepted and
+// 204 No Content responses, the latter indicating that the operation was canceled synchronously, are considered
+// successful.
 func (h *OperationHandle) Cancel(ctx context.Context, options CancelOperationOptions) error {
-	url := h.client.serviceBaseURL.JoinPath(url.PathEscape(h.Operation), url.PathEscape(h.ID), "cancel")
-	request, err := http.NewRequestWithContext(ctx, "POST", url.String(), nil)
+	ctx, cancel := h.client.withOperationTimeout(ctx, h.Operation)
+	defer cancel()
+	ctx, record := h.client.recordCall(ctx, h.Operation, ClientMethodCancelOperation)
+	err := h.cancel(ctx, options)
+	record(ClientCallOutcomeSuccessful, err)
+	return h.client.mapError(err)
+}
+
+func (h *OperationHandle) cancel(ctx context.Context, options CancelOperationOptions) error {
+	url := h.operationURL("cancel")
+	if err := h.client.transformURL(url); err != nil {
+		return err
+	}
+	request, err := h.client.newRequest(ctx, h.Operation, "POST", url.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -182,6 +354,7 @@ func (h *OperationHandle) Cancel(ctx context.Context, options CancelOperationOpt
 	}
 
 	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
 	response, err := h.client.options.HTTPCaller(request)
 	if err != nil {
 		return err
@@ -193,8 +366,8 @@ func (h *OperationHandle) Cancel(ctx context.Context, options CancelOperationOpt
 		return err
 	}
 
-	if response.StatusCode != http.StatusAccepted {
-		return newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	if response.StatusCode != http.StatusAccepted && response.StatusCode != http.StatusNoContent {
+		return h.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
 	}
 	return nil
 }
@@ -0,0 +1,59 @@
+package nexus
+
+import (
+	"net/http"
+	"time"
+)
+
+// Header reporting the time spent by the handler processing a request, see [HandlerOptions.ReportHandlerDuration].
+const headerHandlerDuration = "Nexus-Handler-Duration"
+
+// withHandlerDuration wraps an [http.Handler], setting the Nexus-Handler-Duration header to the time elapsed since the
+// request was received when the response headers are written.
+func withHandlerDuration(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		handler.ServeHTTP(&handlerDurationWriter{ResponseWriter: writer, start: time.Now()}, request)
+	})
+}
+
+// handlerDurationWriter sets the Nexus-Handler-Duration header before the response headers are written.
+type handlerDurationWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *handlerDurationWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set(headerHandlerDuration, time.Since(w.start).String())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *handlerDurationWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying writer for [http.ResponseController].
+func (w *handlerDurationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ServerProcessingTime returns the time the handler spent processing the request of a response, up to writing the
+// response headers, as reported by handlers with [HandlerOptions.ReportHandlerDuration] enabled. Subtract it from the
+// total request latency to estimate the network overhead. Returns false if the handler did not report it.
+func ServerProcessingTime(response *http.Response) (time.Duration, bool) {
+	value := response.Header.Get(headerHandlerDuration)
+	if value == "" {
+		return 0, false
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, false
+	}
+	return duration, true
+}
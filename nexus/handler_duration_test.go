@@ -0,0 +1,88 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type slowHandler struct {
+	UnimplementedHandler
+}
+
+func (h *slowHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	time.Sleep(time.Millisecond * 50)
+	return &OperationResponseAsync{OperationID: "a/sync"}, nil
+}
+
+func (h *slowHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	time.Sleep(time.Millisecond * 50)
+	return NewOperationResponseSync("done")
+}
+
+func TestReportHandlerDuration(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &slowHandler{}, ReportHandlerDuration: true}, ClientOptions{})
+	defer teardown()
+
+	start := time.Now()
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	total := time.Since(start)
+	require.NotNil(t, result.Pending)
+	require.GreaterOrEqual(t, result.ServerProcessingTime, time.Millisecond*50)
+	require.Less(t, result.ServerProcessingTime, total)
+
+	response, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	response.Body.Close()
+	duration, ok := ServerProcessingTime(response)
+	require.True(t, ok)
+	require.GreaterOrEqual(t, duration, time.Millisecond*50)
+
+	// Failures are timed too.
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	_, ok = ServerProcessingTime(unexpectedError.Response)
+	require.True(t, ok)
+}
+
+func TestReportHandlerDuration_Disabled(t *testing.T) {
+	ctx, client, teardown := setup(t, &slowHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.Zero(t, result.ServerProcessingTime)
+	response, err := result.Pending.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	response.Body.Close()
+	_, ok := ServerProcessingTime(response)
+	require.False(t, ok)
+}
+
+func TestReportHandlerDuration_StreamEvents(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &eventsHandler{}, ReportHandlerDuration: true}, ClientOptions{})
+	defer teardown()
+
+	// Streaming responses are flushed through the wrapped writer.
+	handle, err := client.NewHandle("foo", "build")
+	require.NoError(t, err)
+	events, err := handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.NoError(t, err)
+	var count int
+	for range events {
+		count++
+	}
+	require.Equal(t, 3, count)
+}
+
+func TestServerProcessingTime_Invalid(t *testing.T) {
+	for _, value := range []string{"abc", "-1s", "10"} {
+		_, ok := ServerProcessingTime(&http.Response{Header: http.Header{headerHandlerDuration: []string{value}}})
+		require.False(t, ok, value)
+	}
+}
@@ -82,7 +82,11 @@ func (h *myHandler) peekOperation(ctx context.Context) (*MyResult, error) {
 func (h *myHandler) authorize(ctx context.Context, request *http.Request) error {
 	// Authorization for demo purposes
 	if request.Header.Get("Authorization") != "Bearer top-secret" {
-		return &nexus.HandlerError{StatusCode: http.StatusUnauthorized, Failure: &nexus.Failure{Message: "Unauthorized"}}
+		return &nexus.HandlerError{
+			StatusCode: http.StatusUnauthorized,
+			Failure:    &nexus.Failure{Message: "Unauthorized"},
+			Header:     http.Header{"WWW-Authenticate": []string{"Bearer"}},
+		}
 	}
 	return nil
 }
@@ -0,0 +1,94 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Header carrying the time a caller is willing to wait for an operation, an alternative to Request-Timeout, see
+// [HandlerRequestMetrics.RequestedTimeout].
+const headerOperationTimeout = "Nexus-Operation-Timeout"
+
+// HandlerRequestMetrics describes a single request served by a handler, see [HandlerMetricsHandler].
+type HandlerRequestMetrics struct {
+	// Name of the operation the request targets. Empty if the request failed before the operation was determined.
+	Operation string
+	// Name of the [Handler] method serving the request, e.g. "StartOperation".
+	Method string
+	// Status code of the response.
+	StatusCode int
+	// Time spent serving the request, up to writing the entire response.
+	Duration time.Duration
+	// Timeout requested by the caller in the Request-Timeout or Nexus-Operation-Timeout header, zero if not set.
+	RequestedTimeout time.Duration
+	// Whether serving the request took longer than the caller's requested timeout, i.e. the caller's deadline was
+	// missed. Count these to track SLA breaches per operation. Long polls for results that end once the requested
+	// timeout elapses are not considered missed.
+	DeadlineMissed bool
+}
+
+// HandlerMetricsHandler receives per request metrics from a handler, see [HandlerOptions.MetricsHandler]. Implement it
+// to adapt the handler to a metrics backend, e.g. OpenTelemetry.
+//
+// Implementations must be safe for concurrent use.
+type HandlerMetricsHandler interface {
+	// RecordRequest is invoked once a request is served.
+	RecordRequest(HandlerRequestMetrics)
+}
+
+type requestMetricsKey struct{}
+
+// requestMetrics collects details of a request that are only known once it's parsed.
+type requestMetrics struct {
+	operation string
+}
+
+// setRequestMetricsOperation records the operation of a request started with [httpHandler.recordRequestMetrics].
+func setRequestMetricsOperation(request *http.Request, operation string) {
+	if metrics, ok := request.Context().Value(requestMetricsKey{}).(*requestMetrics); ok {
+		metrics.operation = operation
+	}
+}
+
+// requestedTimeout returns the timeout requested by the caller of a request, preferring Request-Timeout over
+// Nexus-Operation-Timeout. Invalid values are ignored.
+func requestedTimeout(header http.Header) time.Duration {
+	for _, name := range []string{headerRequestTimeout, headerOperationTimeout} {
+		if value := header.Get(name); value != "" {
+			if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+				return timeout
+			}
+		}
+	}
+	return 0
+}
+
+// recordRequestMetrics is a route middleware reporting [HandlerRequestMetrics] to [HandlerOptions.MetricsHandler].
+func (h *httpHandler) recordRequestMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		timeout := requestedTimeout(request.Header)
+		metrics := &requestMetrics{}
+		recorder := &statusRecordingWriter{ResponseWriter: writer, statusCode: http.StatusOK}
+		handler.ServeHTTP(recorder, request.WithContext(context.WithValue(request.Context(), requestMetricsKey{}, metrics)))
+		duration := time.Since(start)
+		var method string
+		if route := mux.CurrentRoute(request); route != nil {
+			method = route.GetName()
+		}
+		// Long polls for operation results wait up to the requested timeout, ending with a 408 response once it
+		// elapses. That's the expected outcome of a poll, not a missed deadline.
+		pollTimedOut := recorder.statusCode == http.StatusRequestTimeout && recorder.Header().Get(headerTimeoutSource) == timeoutSourceClient
+		h.options.MetricsHandler.RecordRequest(HandlerRequestMetrics{
+			Operation:        metrics.operation,
+			Method:           method,
+			StatusCode:       recorder.statusCode,
+			Duration:         duration,
+			RequestedTimeout: timeout,
+			DeadlineMissed:   timeout > 0 && duration > timeout && !pollTimedOut,
+		})
+	})
+}
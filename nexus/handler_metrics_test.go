@@ -0,0 +1,137 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHandlerMetrics records the metrics of served requests.
+type recordingHandlerMetrics struct {
+	mu       sync.Mutex
+	requests []HandlerRequestMetrics
+}
+
+func (h *recordingHandlerMetrics) RecordRequest(metrics HandlerRequestMetrics) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, metrics)
+}
+
+// recorded waits for n requests to be recorded, metrics are recorded after the response is sent.
+func (h *recordingHandlerMetrics) recorded(t *testing.T, n int) []HandlerRequestMetrics {
+	var requests []HandlerRequestMetrics
+	require.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		requests = append([]HandlerRequestMetrics(nil), h.requests...)
+		return len(requests) == n
+	}, time.Second, time.Millisecond)
+	return requests
+}
+
+// deadlinesMissed counts requests that missed their deadline per operation.
+func (h *recordingHandlerMetrics) deadlinesMissed() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts := map[string]int{}
+	for _, request := range h.requests {
+		if request.DeadlineMissed {
+			counts[request.Operation]++
+		}
+	}
+	return counts
+}
+
+// sleepingHandler completes the "slow" operation after a delay and other operations immediately.
+type sleepingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *sleepingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "slow" {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return NewOperationResponseSync(request.Operation)
+}
+
+func TestHandlerMetrics_DeadlineMissed(t *testing.T) {
+	metrics := &recordingHandlerMetrics{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &sleepingHandler{}, MetricsHandler: metrics}, ClientOptions{})
+	defer teardown()
+
+	start := func(operation string, header http.Header) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation, Header: header})
+		require.NoError(t, err)
+		result.Successful.Body.Close()
+	}
+	start("slow", http.Header{headerRequestTimeout: []string{"50ms"}})
+	start("slow", http.Header{headerOperationTimeout: []string{"50ms"}})
+	start("slow", http.Header{headerRequestTimeout: []string{"1m"}})
+	start("slow", nil)
+	start("fast", http.Header{headerRequestTimeout: []string{"1m"}})
+
+	requests := metrics.recorded(t, 5)
+	require.Equal(t, map[string]int{"slow": 2}, metrics.deadlinesMissed())
+	require.Equal(t, "slow", requests[0].Operation)
+	require.Equal(t, "StartOperation", requests[0].Method)
+	require.Equal(t, http.StatusOK, requests[0].StatusCode)
+	require.Equal(t, 50*time.Millisecond, requests[0].RequestedTimeout)
+	require.GreaterOrEqual(t, requests[0].Duration, 100*time.Millisecond)
+	require.Equal(t, 50*time.Millisecond, requests[1].RequestedTimeout)
+	require.Equal(t, time.Minute, requests[2].RequestedTimeout)
+	require.False(t, requests[2].DeadlineMissed)
+	require.Zero(t, requests[3].RequestedTimeout)
+	require.False(t, requests[3].DeadlineMissed)
+	require.Equal(t, "fast", requests[4].Operation)
+	require.False(t, requests[4].DeadlineMissed)
+}
+
+func TestHandlerMetrics_Failures(t *testing.T) {
+	metrics := &recordingHandlerMetrics{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &UnimplementedHandler{}, MetricsHandler: metrics}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "abc")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{Header: http.Header{headerRequestTimeout: []string{"invalid"}}})
+	require.Error(t, err)
+
+	requests := metrics.recorded(t, 1)
+	require.Equal(t, "foo", requests[0].Operation)
+	require.Equal(t, "GetOperationInfo", requests[0].Method)
+	require.Equal(t, http.StatusNotImplemented, requests[0].StatusCode)
+	require.Zero(t, requests[0].RequestedTimeout)
+}
+
+func TestHandlerMetrics_LongPollTimeout(t *testing.T) {
+	metrics := &recordingHandlerMetrics{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:        &asyncWithResultHandler{timesToBlock: 1000},
+		MetricsHandler: metrics,
+	}, ClientOptions{})
+	defer teardown()
+
+	// Polls until the server enforced Request-Timeout elapses and responds with a 408.
+	url := client.serviceBaseURL.JoinPath("foo", "a%2Fsync", "result").String() + "?wait=1s"
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	require.NoError(t, err)
+	request.Header.Set(headerUserAgent, userAgent)
+	request.Header.Set(headerRequestTimeout, "50ms")
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, http.StatusRequestTimeout, response.StatusCode)
+	require.Equal(t, timeoutSourceClient, response.Header.Get(headerTimeoutSource))
+
+	requests := metrics.recorded(t, 1)
+	require.Equal(t, "GetOperationResult", requests[0].Method)
+	require.Equal(t, http.StatusRequestTimeout, requests[0].StatusCode)
+	require.Equal(t, 50*time.Millisecond, requests[0].RequestedTimeout)
+	require.Greater(t, requests[0].Duration, 50*time.Millisecond)
+	require.False(t, requests[0].DeadlineMissed)
+}
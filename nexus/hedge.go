@@ -0,0 +1,122 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// hedgeAttempts is the max number of concurrent attempts issued for a hedged request.
+const hedgeAttempts = 2
+
+type hedgeResult struct {
+	index    int
+	response *http.Response
+	err      error
+}
+
+// sendIdempotentRequest sends a GET request, hedging it if [ClientOptions.HedgeDelay] is set: if no response arrives
+// within the delay, a second identical request is issued and the first successful response wins. Responses with a 5xx
+// status code don't win, if all attempts fail the last failure is returned. The losing requests are canceled and
+// their response bodies, if any, are drained and closed.
+//
+// Long poll requests, identified by the wait query param, are never hedged since they are expected to block.
+func (c *Client) sendIdempotentRequest(request *http.Request) (*http.Response, error) {
+	if c.options.HedgeDelay <= 0 || request.Method != "GET" || request.URL.Query().Has(queryWait) {
+		return c.options.HTTPCaller(request)
+	}
+
+	results := make(chan hedgeResult, hedgeAttempts)
+	var cancels []context.CancelFunc
+	send := func() {
+		ctx, cancel := context.WithCancel(request.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			response, err := c.options.HTTPCaller(request.Clone(ctx))
+			results <- hedgeResult{index, response, err}
+		}()
+	}
+
+	send()
+	inFlight := 1
+	timer := time.NewTimer(c.options.HedgeDelay)
+	defer timer.Stop()
+
+	// The last failed attempt, returned if all attempts fail.
+	var failure *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < hedgeAttempts && c.retryBudget.allowRetry() {
+				send()
+				inFlight++
+			}
+		case result := <-results:
+			inFlight--
+			if result.err != nil || result.response.StatusCode >= http.StatusInternalServerError {
+				if failure != nil {
+					discardHedgeResult(*failure)
+					cancels[failure.index]()
+				}
+				failure = &result
+				if inFlight > 0 {
+					continue
+				}
+				if failure.err != nil {
+					cancels[failure.index]()
+					return nil, failure.err
+				}
+				result.response.Body = &cancelOnCloseBody{ReadCloser: result.response.Body, cancel: cancels[result.index]}
+				return result.response, nil
+			}
+			if failure != nil {
+				discardHedgeResult(*failure)
+				cancels[failure.index]()
+			}
+			// Cancel the losing attempts and discard their results in the background.
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			go discardHedgeResults(results, inFlight)
+			// Release the winner's context once its body is closed.
+			result.response.Body = &cancelOnCloseBody{ReadCloser: result.response.Body, cancel: cancels[result.index]}
+			return result.response, nil
+		}
+	}
+}
+
+// discardHedgeResults drains and closes the response bodies of losing attempts.
+func discardHedgeResults(results <-chan hedgeResult, count int) {
+	for i := 0; i < count; i++ {
+		discardHedgeResult(<-results)
+	}
+}
+
+// discardHedgeResult drains and closes the response body of a losing attempt, if any.
+func discardHedgeResult(result hedgeResult) {
+	if result.response != nil {
+		_, _ = io.Copy(io.Discard, result.response.Body)
+		result.response.Body.Close()
+	}
+}
+
+// cancelOnClose cancels a request's context once its response body is closed.
+func cancelOnClose(response *http.Response, cancel context.CancelFunc) {
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+}
+
+// cancelOnCloseBody cancels a request's context once its response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
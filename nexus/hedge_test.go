@@ -0,0 +1,105 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type slowFirstInfoHandler struct {
+	UnimplementedHandler
+	calls atomic.Int32
+}
+
+func (h *slowFirstInfoHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	if h.calls.Add(1) == 1 {
+		select {
+		case <-time.After(time.Second * 2):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func TestHedging(t *testing.T) {
+	handler := &slowFirstInfoHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: time.Millisecond * 50})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	startTime := time.Now()
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "bar", info.ID)
+	require.Less(t, time.Since(startTime), time.Second)
+	require.Equal(t, int32(2), handler.calls.Load())
+}
+
+func TestHedging_Disabled(t *testing.T) {
+	handler := &slowFirstInfoHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*200)
+	defer cancel()
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int32(1), handler.calls.Load())
+}
+
+// slowSuccessFastFailureHandler responds to the first info request slowly, and to subsequent requests immediately with
+// a 500 status code. When failSlow is set, the first request fails too.
+type slowSuccessFastFailureHandler struct {
+	UnimplementedHandler
+	calls    atomic.Int32
+	failSlow bool
+}
+
+func (h *slowSuccessFastFailureHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	if h.calls.Add(1) > 1 {
+		return nil, &HandlerError{StatusCode: http.StatusInternalServerError, Failure: &Failure{Message: "hedged request failed"}}
+	}
+	select {
+	case <-time.After(time.Millisecond * 300):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if h.failSlow {
+		return nil, &HandlerError{StatusCode: http.StatusInternalServerError, Failure: &Failure{Message: "first request failed"}}
+	}
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func TestHedging_FailedResponsesDontWin(t *testing.T) {
+	handler := &slowSuccessFastFailureHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: time.Millisecond * 50})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "bar", info.ID)
+	require.Equal(t, int32(2), handler.calls.Load())
+
+	// All attempts fail, the last failure is returned.
+	handler = &slowSuccessFastFailureHandler{failSlow: true}
+	ctx, client, teardown = setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{HedgeDelay: time.Millisecond * 50})
+	defer teardown()
+	handle, err = client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "first request failed", unexpectedResponseError.Failure.Message)
+	require.Equal(t, int32(2), handler.calls.Load())
+}
@@ -0,0 +1,48 @@
+package nexus
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBodyIdleTimeout is returned when reading a successful operation response body stalls for longer than
+// [ClientOptions.ResponseBodyIdleTimeout].
+var ErrBodyIdleTimeout = errors.New("response body idle timeout")
+
+// idleTimeoutReader fails reads that do not complete within a timeout by closing the underlying body, unblocking the
+// pending read. The timer only runs while a read is in progress, time spent by the caller between reads is not counted.
+type idleTimeoutReader struct {
+	io.ReadCloser
+	timeout  time.Duration
+	timedOut atomic.Bool
+}
+
+func newIdleTimeoutReader(body io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	return &idleTimeoutReader{ReadCloser: body, timeout: timeout}
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	if r.timedOut.Load() {
+		return 0, ErrBodyIdleTimeout
+	}
+	timer := time.AfterFunc(r.timeout, func() {
+		r.timedOut.Store(true)
+		r.ReadCloser.Close()
+	})
+	n, err := r.ReadCloser.Read(p)
+	timer.Stop()
+	if r.timedOut.Load() {
+		return n, ErrBodyIdleTimeout
+	}
+	return n, err
+}
+
+// applyResponseBodyIdleTimeout wraps the body of a successful response per [ClientOptions.ResponseBodyIdleTimeout].
+func (c *Client) applyResponseBodyIdleTimeout(response *http.Response) {
+	if c.options.ResponseBodyIdleTimeout > 0 {
+		response.Body = newIdleTimeoutReader(response.Body, c.options.ResponseBodyIdleTimeout)
+	}
+}
@@ -0,0 +1,60 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleTimeoutReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	reader := newIdleTimeoutReader(pr, time.Millisecond*50)
+	go func() {
+		_, _ = pw.Write([]byte("foo"))
+		// Stall without closing the writer.
+	}()
+
+	b := make([]byte, 3)
+	n, err := reader.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, "foo", string(b[:n]))
+
+	// Time spent between reads is not counted.
+	time.Sleep(time.Millisecond * 100)
+
+	startTime := time.Now()
+	_, err = reader.Read(b)
+	require.ErrorIs(t, err, ErrBodyIdleTimeout)
+	require.GreaterOrEqual(t, time.Since(startTime), time.Millisecond*50)
+	_, err = reader.Read(b)
+	require.ErrorIs(t, err, ErrBodyIdleTimeout)
+}
+
+func TestResponseBodyIdleTimeout(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL:          "http://example.com",
+		ResponseBodyIdleTimeout: time.Millisecond * 50,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			// Body that never produces any data.
+			pr, _ := io.Pipe()
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: pr, Request: request}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	_, err = io.ReadAll(result.Successful.Body)
+	require.ErrorIs(t, err, ErrBodyIdleTimeout)
+
+	handle, err := client.NewHandle("foo", "id")
+	require.NoError(t, err)
+	response, err := handle.GetResult(context.Background(), GetOperationResultOptions{})
+	require.NoError(t, err)
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, ErrBodyIdleTimeout)
+}
@@ -0,0 +1,85 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Defaulter may be implemented by operation inputs to fill in defaults for fields omitted by the caller.
+// [StartOperationRequest.ReadJSON] calls ApplyDefaults after successfully decoding the input.
+type Defaulter interface {
+	ApplyDefaults()
+}
+
+// ReadJSON reads the request body in its entirety and decodes it as JSON into v. The body is closed once read.
+//
+// Fails with a bad request [HandlerError] if the request has a Content-Type header other than application/json or the
+// body could not be decoded, including when the body has fields unknown to v and
+// [HandlerOptions.DisallowUnknownFields] is set.
+//
+// If v is a *[json.RawMessage] or a *[]byte, the raw body is captured as is without parsing, allowing handlers to forward
+// the input verbatim or defer decoding.
+//
+// If v implements [Defaulter], its ApplyDefaults method is called after decoding.
+func (r *StartOperationRequest) ReadJSON(v any) error {
+	body := r.HTTPRequest.Body
+	defer body.Close()
+	if r.HTTPRequest.Header.Get(headerContentType) != "" && !isContentTypeJSON(r.HTTPRequest.Header) {
+		return newBadRequestError("invalid request content type: %q", r.HTTPRequest.Header.Get(headerContentType))
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	switch raw := v.(type) {
+	case *json.RawMessage:
+		*raw = b
+		return nil
+	case *[]byte:
+		*raw = b
+		return nil
+	}
+	if err := r.decoding.decode(b, v); err != nil {
+		return newBadRequestError("failed to decode request body: %v", err)
+	}
+	if defaulter, ok := v.(Defaulter); ok {
+		defaulter.ApplyDefaults()
+	}
+	return nil
+}
+
+// jsonDecodingOptions configures decoding of operation inputs, derived from [HandlerOptions].
+type jsonDecodingOptions struct {
+	disallowUnknownFields bool
+	useNumber             bool
+}
+
+func (h *httpHandler) jsonDecodingOptions() jsonDecodingOptions {
+	return jsonDecodingOptions{
+		disallowUnknownFields: h.options.DisallowUnknownFields,
+		useNumber:             h.options.UseNumber,
+	}
+}
+
+func (o jsonDecodingOptions) decode(b []byte, v any) error {
+	if !o.disallowUnknownFields && !o.useNumber {
+		return json.Unmarshal(b, v)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	if o.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if o.useNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	// Match json.Unmarshal, which rejects trailing data.
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"io"
+	"net/http"
+)
+
+// applyInputMiddleware replaces a start request's body with the body transformed by [HandlerOptions.InputMiddleware],
+// if set.
+func (h *httpHandler) applyInputMiddleware(request *http.Request, operation string) error {
+	if h.options.InputMiddleware == nil {
+		return nil
+	}
+	original := &errorRecordingReader{reader: request.Body}
+	transformed, err := h.options.InputMiddleware(request.Context(), operation, original)
+	if err != nil {
+		request.Body.Close()
+		return newBadRequestError("invalid input: %v", err)
+	}
+	request.Body = &transformedBody{Reader: transformed, original: original, closer: request.Body}
+	// The length of the transformed body is unknown.
+	request.ContentLength = -1
+	request.Header.Del("Content-Length")
+	return nil
+}
+
+// errorRecordingReader records the last error returned from reading a request body, other than io.EOF.
+type errorRecordingReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *errorRecordingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// transformedBody is a request body transformed by [HandlerOptions.InputMiddleware]. Read errors originating in the
+// middleware are reported as bad request errors while errors reading the original body, e.g. due to a client
+// disconnect, are returned as is.
+type transformedBody struct {
+	io.Reader
+	original *errorRecordingReader
+	closer   io.Closer
+}
+
+func (b *transformedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err != nil && err != io.EOF && b.original.err == nil {
+		if _, ok := err.(*HandlerError); !ok {
+			err = newBadRequestError("invalid input: %v", err)
+		}
+	}
+	return n, err
+}
+
+func (b *transformedBody) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.closer.Close()
+}
@@ -0,0 +1,109 @@
+package nexus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type echoInputHandler struct {
+	UnimplementedHandler
+	contentLength int64
+}
+
+func (h *echoInputHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	h.contentLength = request.HTTPRequest.ContentLength
+	var input string
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(input)
+}
+
+// failAfterReader fails after returning the first read of the underlying reader, simulating a corrupt input detected
+// mid-stream.
+type failAfterReader struct {
+	reader io.Reader
+	read   bool
+}
+
+func (r *failAfterReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("corrupt input")
+	}
+	r.read = true
+	return r.reader.Read(p[:1])
+}
+
+func inputMiddleware(ctx context.Context, operation string, body io.Reader) (io.Reader, error) {
+	switch operation {
+	case "compressed":
+		return gzip.NewReader(body)
+	case "corrupt":
+		return &failAfterReader{reader: body}, nil
+	case "reject":
+		return nil, errors.New("unsupported input format")
+	}
+	return body, nil
+}
+
+func TestInputMiddleware(t *testing.T) {
+	handler := &echoInputHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler, InputMiddleware: inputMiddleware}, ClientOptions{})
+	defer teardown()
+
+	start := func(operation string, body io.Reader) (string, error) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation, Body: body})
+		if err != nil {
+			return "", err
+		}
+		defer result.Successful.Body.Close()
+		b, err := io.ReadAll(result.Successful.Body)
+		require.NoError(t, err)
+		return string(b), nil
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(`"abc"`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	body, err := start("compressed", &compressed)
+	require.NoError(t, err)
+	require.Equal(t, `"abc"`, body)
+	require.Equal(t, int64(-1), handler.contentLength)
+
+	body, err = start("plain", strings.NewReader(`"abc"`))
+	require.NoError(t, err)
+	require.Equal(t, `"abc"`, body)
+
+	for _, operation := range []string{"reject", "corrupt"} {
+		_, err = start(operation, strings.NewReader(`"abc"`))
+		var unexpectedError *UnexpectedResponseError
+		require.ErrorAs(t, err, &unexpectedError, operation)
+		require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode, operation)
+		require.Contains(t, unexpectedError.Failure.Message, "invalid input", operation)
+	}
+
+	// Invalid gzip streams are rejected too.
+	_, err = start("compressed", strings.NewReader(`"abc"`))
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+}
+
+func TestInputMiddleware_OriginalBodyErrors(t *testing.T) {
+	original := &errorRecordingReader{reader: &failAfterReader{reader: strings.NewReader("abc")}}
+	body := &transformedBody{Reader: original, original: original, closer: io.NopCloser(nil)}
+	_, err := io.ReadAll(body)
+	require.Error(t, err)
+	var handlerError *HandlerError
+	require.False(t, errors.As(err, &handlerError), "errors reading the original body should be returned as is")
+}
@@ -0,0 +1,210 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type defaultedInput struct {
+	Name    string `json:"name"`
+	Retries int    `json:"retries"`
+}
+
+func (i *defaultedInput) ApplyDefaults() {
+	if i.Retries == 0 {
+		i.Retries = 3
+	}
+}
+
+func newTestStartOperationRequest(body string, contentType string) *StartOperationRequest {
+	request := httptest.NewRequest("POST", "/foo", strings.NewReader(body))
+	if contentType != "" {
+		request.Header.Set(headerContentType, contentType)
+	}
+	return &StartOperationRequest{Operation: "foo", HTTPRequest: request}
+}
+
+func TestReadJSON_Defaults(t *testing.T) {
+	var input defaultedInput
+	require.NoError(t, newTestStartOperationRequest(`{"name":"foo"}`, contentTypeJSON).ReadJSON(&input))
+	require.Equal(t, defaultedInput{Name: "foo", Retries: 3}, input)
+
+	input = defaultedInput{}
+	require.NoError(t, newTestStartOperationRequest(`{"name":"foo","retries":5}`, contentTypeJSON).ReadJSON(&input))
+	require.Equal(t, defaultedInput{Name: "foo", Retries: 5}, input)
+}
+
+func TestReadJSON_Invalid(t *testing.T) {
+	var input defaultedInput
+	var handlerError *HandlerError
+
+	err := newTestStartOperationRequest(`{"name":`, contentTypeJSON).ReadJSON(&input)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, http.StatusBadRequest, handlerError.StatusCode)
+
+	err = newTestStartOperationRequest(`{"name":"foo"}`, "text/plain").ReadJSON(&input)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, http.StatusBadRequest, handlerError.StatusCode)
+}
+
+func TestReadJSON_Raw(t *testing.T) {
+	var raw json.RawMessage
+	require.NoError(t, newTestStartOperationRequest(`{"name": "foo"}`, contentTypeJSON).ReadJSON(&raw))
+	require.Equal(t, json.RawMessage(`{"name": "foo"}`), raw)
+
+	var b []byte
+	require.NoError(t, newTestStartOperationRequest(`[1, 2, 3]`, "").ReadJSON(&b))
+	require.Equal(t, []byte(`[1, 2, 3]`), b)
+
+	var handlerError *HandlerError
+	err := newTestStartOperationRequest(`{}`, "text/plain").ReadJSON(&raw)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, http.StatusBadRequest, handlerError.StatusCode)
+}
+
+func TestReadJSON_DisallowUnknownFields(t *testing.T) {
+	var input defaultedInput
+	require.NoError(t, newTestStartOperationRequest(`{"name":"foo","extra":1}`, contentTypeJSON).ReadJSON(&input))
+	require.Equal(t, "foo", input.Name)
+
+	strict := func(body string) *StartOperationRequest {
+		request := newTestStartOperationRequest(body, contentTypeJSON)
+		request.decoding.disallowUnknownFields = true
+		return request
+	}
+	var handlerError *HandlerError
+	err := strict(`{"name":"foo","extra":1}`).ReadJSON(&input)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, http.StatusBadRequest, handlerError.StatusCode)
+	require.Contains(t, handlerError.Failure.Message, `unknown field "extra"`)
+
+	// Missing fields are allowed and defaults are still applied.
+	input = defaultedInput{}
+	require.NoError(t, strict(`{}`).ReadJSON(&input))
+	require.Equal(t, defaultedInput{Retries: 3}, input)
+
+	err = strict(`{"name":"foo"} {}`).ReadJSON(&input)
+	require.ErrorAs(t, err, &handlerError)
+	require.Equal(t, http.StatusBadRequest, handlerError.StatusCode)
+}
+
+type strictInputHandler struct {
+	UnimplementedHandler
+}
+
+func (h *strictInputHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input defaultedInput
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(input)
+}
+
+func TestDisallowUnknownFieldsOption(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &strictInputHandler{}, DisallowUnknownFields: true})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", strings.NewReader(`{"name":"foo","extra":1}`)))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", strings.NewReader(`{"name":"foo"}`)))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, `{"name":"foo","retries":3}`, writer.Body.String())
+}
+
+func TestReadJSON_UseNumber(t *testing.T) {
+	// 2^53 + 1 cannot be represented as a float64.
+	const body = `{"id":9007199254740993,"nested":[1.5]}`
+
+	var input map[string]any
+	require.NoError(t, newTestStartOperationRequest(body, contentTypeJSON).ReadJSON(&input))
+	require.Equal(t, float64(9007199254740992), input["id"])
+
+	request := newTestStartOperationRequest(body, contentTypeJSON)
+	request.decoding.useNumber = true
+	input = nil
+	require.NoError(t, request.ReadJSON(&input))
+	require.Equal(t, json.Number("9007199254740993"), input["id"])
+	id, err := input["id"].(json.Number).Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(9007199254740993), id)
+	require.Equal(t, []any{json.Number("1.5")}, input["nested"])
+
+	// Typed targets are unaffected.
+	var typed struct {
+		ID int64 `json:"id"`
+	}
+	request = newTestStartOperationRequest(body, contentTypeJSON)
+	request.decoding.useNumber = true
+	require.NoError(t, request.ReadJSON(&typed))
+	require.Equal(t, int64(9007199254740993), typed.ID)
+}
+
+type echoMapInputHandler struct {
+	UnimplementedHandler
+}
+
+func (h *echoMapInputHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input map[string]any
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(input)
+}
+
+func TestUseNumberOption(t *testing.T) {
+	for _, useNumber := range []bool{false, true} {
+		handler := NewHTTPHandler(HandlerOptions{Handler: &echoMapInputHandler{}, UseNumber: useNumber})
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", strings.NewReader(`{"id":9007199254740993}`)))
+		require.Equal(t, http.StatusOK, writer.Code)
+		if useNumber {
+			require.Equal(t, `{"id":9007199254740993}`, writer.Body.String())
+		} else {
+			require.Equal(t, `{"id":9007199254740992}`, writer.Body.String())
+		}
+	}
+}
+
+// disconnectingReader simulates a client disconnecting mid request body.
+type disconnectingReader struct {
+	cancel context.CancelFunc
+}
+
+func (r *disconnectingReader) Read(p []byte) (int, error) {
+	r.cancel()
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestStart_ClientDisconnectDuringBodyRead(t *testing.T) {
+	var logs bytes.Buffer
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler: &strictInputHandler{},
+		Logger:  slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request := httptest.NewRequest("POST", "/foo", &disconnectingReader{cancel: cancel}).WithContext(ctx)
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, request)
+	// The failure is written, never an empty successful response, but not logged as an error.
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+	require.Contains(t, logs.String(), "level=DEBUG msg=\"handler failed\"")
+	require.NotContains(t, logs.String(), "level=ERROR")
+
+	// Read failures while the client is still connected are logged as errors.
+	logs.Reset()
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", &disconnectingReader{cancel: func() {}}))
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+	require.Contains(t, logs.String(), "level=ERROR msg=\"handler failed\"")
+}
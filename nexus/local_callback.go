@@ -0,0 +1,116 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// LocalCompletion is a successful operation completion delivered to a [LocalCallback].
+type LocalCompletion struct {
+	// Header of the completion request, e.g. the result's Content-Type.
+	Header http.Header
+	// Result of the operation.
+	Body []byte
+}
+
+// LocalCallback is a temporary HTTP endpoint, hosted by the calling process on the loopback interface, for receiving
+// the completion of a single asynchronous operation. Intended for testing and local development, where it exercises the
+// callback delivery path end to end without polling.
+//
+// Obtain one via [Client.StartOperationWithLocalCallback] and close it once done.
+type LocalCallback struct {
+	url      string
+	server   *http.Server
+	once     sync.Once
+	received chan localCompletionResult
+}
+
+type localCompletionResult struct {
+	completion *LocalCompletion
+	err        error
+}
+
+// newLocalCallback starts serving a callback endpoint on a random loopback port.
+func newLocalCallback() (*LocalCallback, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	c := &LocalCallback{
+		url:      "http://" + listener.Addr().String() + "/callback",
+		received: make(chan localCompletionResult, 1),
+	}
+	c.server = &http.Server{Handler: NewCompletionHTTPHandler(CompletionHandlerOptions{Handler: c})}
+	go func() {
+		_ = c.server.Serve(listener)
+	}()
+	return c, nil
+}
+
+// URL returns the URL of the callback endpoint.
+func (c *LocalCallback) URL() string {
+	return c.url
+}
+
+// CompleteOperation implements the [CompletionHandler] interface, only the first completion is recorded.
+func (c *LocalCallback) CompleteOperation(ctx context.Context, request *CompletionRequest) error {
+	var result localCompletionResult
+	switch request.State {
+	case OperationStateSucceeded:
+		body, err := io.ReadAll(request.HTTPRequest.Body)
+		if err != nil {
+			return err
+		}
+		result.completion = &LocalCompletion{Header: request.HTTPRequest.Header.Clone(), Body: body}
+	default:
+		result.err = &UnsuccessfulOperationError{State: request.State, Failure: *request.Failure}
+	}
+	c.once.Do(func() {
+		c.received <- result
+	})
+	return nil
+}
+
+// Wait blocks until the operation's completion is delivered to the callback or ctx is done. Returns an
+// [UnsuccessfulOperationError] if the operation failed or was canceled.
+func (c *LocalCallback) Wait(ctx context.Context) (*LocalCompletion, error) {
+	select {
+	case result := <-c.received:
+		// Allow calling Wait again.
+		c.received <- result
+		return result.completion, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops serving the callback endpoint.
+func (c *LocalCallback) Close() error {
+	if err := c.server.Close(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// StartOperationWithLocalCallback starts an operation like [Client.StartOperation], providing the URL of a temporary
+// [LocalCallback] endpoint as the options' CallbackURL. Wait on the returned callback to get the operation's
+// completion, and close it once done.
+//
+// The returned callback is nil if the operation completed synchronously or starting it failed.
+func (c *Client) StartOperationWithLocalCallback(ctx context.Context, options StartOperationOptions) (*StartOperationResult, *LocalCallback, error) {
+	callback, err := newLocalCallback()
+	if err != nil {
+		return nil, nil, err
+	}
+	options.CallbackURL = callback.URL()
+	result, err := c.StartOperation(ctx, options)
+	if err != nil || result.Pending == nil {
+		_ = callback.Close()
+		return result, nil, err
+	}
+	return result, callback, nil
+}
@@ -0,0 +1,73 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type completingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *completingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "sync" {
+		return NewOperationResponseSync("sync")
+	}
+	var completion OperationCompletion
+	if request.Operation == "fail" {
+		completion = &OperationCompletionUnsuccessful{State: OperationStateFailed, Failure: &Failure{Message: "boom"}}
+	} else {
+		var err error
+		completion, err = NewOperationCompletionSuccessful("async")
+		if err != nil {
+			return nil, err
+		}
+	}
+	go func() {
+		completionRequest, err := NewCompletionHTTPRequest(context.Background(), request.CallbackURL, completion)
+		if err != nil {
+			panic(err)
+		}
+		response, err := http.DefaultClient.Do(completionRequest)
+		if err != nil {
+			panic(err)
+		}
+		response.Body.Close()
+	}()
+	return &OperationResponseAsync{OperationID: "id"}, nil
+}
+
+func TestStartOperationWithLocalCallback(t *testing.T) {
+	ctx, client, teardown := setup(t, &completingHandler{})
+	defer teardown()
+
+	result, callback, err := client.StartOperationWithLocalCallback(ctx, StartOperationOptions{Operation: "async"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	defer callback.Close()
+	completion, err := callback.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`"async"`), completion.Body)
+	require.Equal(t, contentTypeJSON, completion.Header.Get(headerContentType))
+	// Wait may be called repeatedly.
+	_, err = callback.Wait(ctx)
+	require.NoError(t, err)
+
+	result, callback, err = client.StartOperationWithLocalCallback(ctx, StartOperationOptions{Operation: "fail"})
+	require.NoError(t, err)
+	defer callback.Close()
+	_, err = callback.Wait(ctx)
+	var unsuccessfulError *UnsuccessfulOperationError
+	require.ErrorAs(t, err, &unsuccessfulError)
+	require.Equal(t, OperationStateFailed, unsuccessfulError.State)
+	require.Equal(t, "boom", unsuccessfulError.Failure.Message)
+
+	result, callback, err = client.StartOperationWithLocalCallback(ctx, StartOperationOptions{Operation: "sync"})
+	require.NoError(t, err)
+	require.Nil(t, callback)
+	require.NotNil(t, result.Successful)
+	result.Successful.Body.Close()
+}
@@ -0,0 +1,121 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Names of the client methods reported in [ClientCallMetrics.Method].
+const (
+	ClientMethodStartOperation     = "StartOperation"
+	ClientMethodGetOperationResult = "GetOperationResult"
+	ClientMethodGetOperationInfo   = "GetOperationInfo"
+	ClientMethodCancelOperation    = "CancelOperation"
+	ClientMethodCancelOperations   = "CancelOperations"
+	ClientMethodLookupOperation    = "LookupOperation"
+	ClientMethodStreamEvents       = "StreamEvents"
+)
+
+// ClientCallOutcome is the outcome of a client call, see [ClientCallMetrics].
+type ClientCallOutcome string
+
+const (
+	// The call completed successfully, e.g. an operation completed synchronously or its result was retrieved.
+	ClientCallOutcomeSuccessful ClientCallOutcome = "successful"
+	// An asynchronous operation was started, including operations accepted without a result.
+	ClientCallOutcomeStarted ClientCallOutcome = "started"
+	// The operation completed as failed or canceled, the call failed with an [UnsuccessfulOperationError].
+	ClientCallOutcomeUnsuccessful ClientCallOutcome = "unsuccessful"
+	// The call failed before getting a response from the handler, e.g. due to a network error.
+	ClientCallOutcomeTransportError ClientCallOutcome = "transport_error"
+	// The call failed for any other reason, e.g. an unexpected response or [ErrOperationStillRunning].
+	ClientCallOutcomeError ClientCallOutcome = "error"
+)
+
+// ClientCallMetrics describes a single call made by a [Client], see [ClientMetricsHandler].
+type ClientCallMetrics struct {
+	// Name of the operation the call targets.
+	Operation string
+	// Name of the client method, one of the ClientMethod constants.
+	Method string
+	// Outcome of the call.
+	Outcome ClientCallOutcome
+	// Total duration of the call, including all of its HTTP requests.
+	Duration time.Duration
+	// Number of HTTP requests issued for the call beyond the first, e.g. for long polling the result of an operation
+	// or hedging.
+	Retries int
+}
+
+// ClientMetricsHandler receives per call metrics from a [Client], see [ClientOptions.MetricsHandler]. Implement it to
+// adapt the client to a metrics backend, e.g. OpenTelemetry.
+//
+// Implementations must be safe for concurrent use.
+type ClientMetricsHandler interface {
+	// RecordCall is invoked once a call completes.
+	RecordCall(ClientCallMetrics)
+}
+
+type callMetricsKey struct{}
+
+// callMetrics tracks the HTTP requests issued for a single client call.
+type callMetrics struct {
+	mu           sync.Mutex
+	requests     int
+	transportErr error
+}
+
+// recordCall starts recording metrics for a call, returning a context to issue the call's requests with and a
+// function to invoke with the call's outcome once it completes. The outcome is used for successful calls and errors
+// are classified.
+func (c *Client) recordCall(ctx context.Context, operation, method string) (context.Context, func(ClientCallOutcome, error)) {
+	if c.options.MetricsHandler == nil {
+		return ctx, func(ClientCallOutcome, error) {}
+	}
+	metrics := &callMetrics{}
+	ctx = context.WithValue(ctx, callMetricsKey{}, metrics)
+	startTime := time.Now()
+	return ctx, func(outcome ClientCallOutcome, err error) {
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+		var unsuccessfulOperationError *UnsuccessfulOperationError
+		switch {
+		case err == nil:
+		case errors.As(err, &unsuccessfulOperationError):
+			outcome = ClientCallOutcomeUnsuccessful
+		case metrics.transportErr != nil && errors.Is(err, metrics.transportErr):
+			outcome = ClientCallOutcomeTransportError
+		default:
+			outcome = ClientCallOutcomeError
+		}
+		c.options.MetricsHandler.RecordCall(ClientCallMetrics{
+			Operation: operation,
+			Method:    method,
+			Outcome:   outcome,
+			Duration:  time.Since(startTime),
+			Retries:   max(metrics.requests-1, 0),
+		})
+	}
+}
+
+// countRequests wraps an HTTP caller, counting requests and recording transport errors for calls started with
+// [Client.recordCall].
+func countRequests(caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		metrics, ok := request.Context().Value(callMetricsKey{}).(*callMetrics)
+		if !ok {
+			return caller(request)
+		}
+		response, err := caller(request)
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+		metrics.requests++
+		if err != nil {
+			metrics.transportErr = err
+		}
+		return response, err
+	}
+}
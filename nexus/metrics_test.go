@@ -0,0 +1,112 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetricsHandler struct {
+	mu    sync.Mutex
+	calls []ClientCallMetrics
+}
+
+func (h *recordingMetricsHandler) RecordCall(metrics ClientCallMetrics) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, metrics)
+}
+
+// last returns the last recorded call with its duration, which is verified to be positive, zeroed out.
+func (h *recordingMetricsHandler) last(t *testing.T) ClientCallMetrics {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	metrics := h.calls[len(h.calls)-1]
+	require.Positive(t, metrics.Duration)
+	metrics.Duration = 0
+	return metrics
+}
+
+func TestClientMetrics(t *testing.T) {
+	metricsHandler := &recordingMetricsHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &unsuccessfulHandler{}}, ClientOptions{MetricsHandler: metricsHandler})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: string(OperationStateFailed)})
+	require.Error(t, err)
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodStartOperation, Outcome: ClientCallOutcomeUnsuccessful}, metricsHandler.last(t))
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodGetOperationInfo, Outcome: ClientCallOutcomeError}, metricsHandler.last(t))
+
+	ctx, client, teardown = setupWithOptions(t, HandlerOptions{Handler: &priorityEchoHandler{}}, ClientOptions{MetricsHandler: metricsHandler})
+	defer teardown()
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	result.Successful.Body.Close()
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodStartOperation, Outcome: ClientCallOutcomeSuccessful}, metricsHandler.last(t))
+
+	ctx, client, teardown = setupWithOptions(t, HandlerOptions{Handler: &asyncWithCancelHandler{}}, ClientOptions{MetricsHandler: metricsHandler})
+	defer teardown()
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "f/o/o"})
+	require.NoError(t, err)
+	require.Equal(t, ClientCallMetrics{Operation: "f/o/o", Method: ClientMethodStartOperation, Outcome: ClientCallOutcomeStarted}, metricsHandler.last(t))
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+	require.Equal(t, ClientCallMetrics{Operation: "f/o/o", Method: ClientMethodCancelOperation, Outcome: ClientCallOutcomeSuccessful}, metricsHandler.last(t))
+}
+
+func TestClientMetrics_TransportError(t *testing.T) {
+	metricsHandler := &recordingMetricsHandler{}
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		MetricsHandler: metricsHandler,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+	})
+	require.NoError(t, err)
+	_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+	require.ErrorContains(t, err, "connection refused")
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodStartOperation, Outcome: ClientCallOutcomeTransportError}, metricsHandler.last(t))
+}
+
+func TestClientMetrics_Retries(t *testing.T) {
+	metricsHandler := &recordingMetricsHandler{}
+	handler := &asyncWithResultHandler{timesToBlock: 2}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{MetricsHandler: metricsHandler})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "a/sync")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Wait: testTimeout})
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodGetOperationResult, Outcome: ClientCallOutcomeSuccessful, Retries: 2}, metricsHandler.last(t))
+}
+
+func TestClientMetrics_StreamEvents(t *testing.T) {
+	metricsHandler := &recordingMetricsHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &eventsHandler{}}, ClientOptions{MetricsHandler: metricsHandler})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "build")
+	require.NoError(t, err)
+	events, err := handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodStreamEvents, Outcome: ClientCallOutcomeSuccessful}, metricsHandler.last(t))
+	for range events {
+	}
+
+	handle, err = client.NewHandle("foo", "unknown")
+	require.NoError(t, err)
+	_, err = handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.Error(t, err)
+	require.Equal(t, ClientCallMetrics{Operation: "foo", Method: ClientMethodStreamEvents, Outcome: ClientCallOutcomeError}, metricsHandler.last(t))
+}
@@ -0,0 +1,21 @@
+package nexus
+
+import "net/http"
+
+// applyMiddleware wraps handler with the given middleware per [HandlerOptions.Middleware], the first middleware being
+// the outermost.
+func applyMiddleware(handler Handler, middleware []func(Handler) Handler) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// applyHTTPMiddleware wraps handler with the given middleware per [HandlerOptions.HTTPMiddleware], the first middleware
+// being the outermost.
+func applyHTTPMiddleware(handler http.Handler, middleware []func(http.Handler) http.Handler) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
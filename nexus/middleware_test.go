@@ -0,0 +1,120 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// callLog records middleware invocations in order.
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	calls := l.calls
+	l.calls = nil
+	return calls
+}
+
+// loggingMiddleware records the operations it sees and rejects the operation named forbidden.
+type loggingMiddleware struct {
+	Handler
+	name string
+	log  *callLog
+}
+
+func (m *loggingMiddleware) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	m.log.add(m.name + ":" + request.Operation)
+	if request.Operation == "forbidden" {
+		return nil, &HandlerError{StatusCode: http.StatusForbidden, Failure: &Failure{Message: m.name + " rejected the operation"}}
+	}
+	return m.Handler.StartOperation(ctx, request)
+}
+
+func (m *loggingMiddleware) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	m.log.add(m.name + ":" + request.Operation + "/" + request.OperationID)
+	return m.Handler.GetOperationInfo(ctx, request)
+}
+
+func newLoggingMiddleware(name string, log *callLog) func(Handler) Handler {
+	return func(handler Handler) Handler {
+		return &loggingMiddleware{Handler: handler, name: name, log: log}
+	}
+}
+
+func newHTTPLoggingMiddleware(name string, log *callLog) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			// Runs before the URL path is parsed.
+			log.add(name + ":" + request.URL.Path + ":" + mux.Vars(request)["operation"])
+			handler.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	log := &callLog{}
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:          &listingHandler{},
+		ExposeOperations: true,
+		Middleware:       []func(Handler) Handler{newLoggingMiddleware("outer", log), newLoggingMiddleware("inner", log)},
+		HTTPMiddleware:   []func(http.Handler) http.Handler{newHTTPLoggingMiddleware("outer", log), newHTTPLoggingMiddleware("inner", log)},
+	})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/charge", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, `"charge"`, writer.Body.String())
+	require.Equal(t, []string{"outer:/charge:", "inner:/charge:", "outer:charge", "inner:charge"}, log.take())
+
+	// Middleware may reject requests before the Handler is invoked.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/forbidden", nil))
+	require.Equal(t, http.StatusForbidden, writer.Code)
+	require.Equal(t, []string{"outer:/forbidden:", "inner:/forbidden:", "outer:forbidden"}, log.take())
+
+	// Middleware that don't override a method are transparent.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/charge/abc", nil))
+	require.Equal(t, http.StatusNotImplemented, writer.Code)
+	require.Equal(t, []string{"outer:/charge/abc:", "inner:/charge/abc:", "outer:charge/abc", "inner:charge/abc"}, log.take())
+
+	// HTTP middleware see requests rejected before reaching the Handler.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("PUT", "/charge", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, writer.Code)
+	require.Equal(t, []string{"outer:/charge:", "inner:/charge:"}, log.take())
+
+	// Operations of the wrapped Handler are listed.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/_operations", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	var operations []OperationDescription
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &operations))
+	require.Equal(t, (&listingHandler{}).ListOperations(), operations)
+}
+
+func TestMiddleware_Validation(t *testing.T) {
+	require.PanicsWithError(t, "nexus: HandlerOptions.Middleware must not contain nil middleware", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, Middleware: []func(Handler) Handler{nil}})
+	})
+	require.PanicsWithError(t, "nexus: HandlerOptions.HTTPMiddleware must not contain nil middleware", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, HTTPMiddleware: []func(http.Handler) http.Handler{nil}})
+	})
+}
@@ -0,0 +1,106 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptedMediaRange is a media range parsed from an Accept header, e.g. "application/*;q=0.5".
+type acceptedMediaRange struct {
+	mediaType string
+	subtype   string
+	quality   float64
+}
+
+// specificity ranks how specific a media range is: 2 for type/subtype, 1 for type/*, and 0 for */*.
+func (r acceptedMediaRange) specificity() int {
+	switch {
+	case r.mediaType == "*":
+		return 0
+	case r.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (r acceptedMediaRange) matches(mediaType, subtype string) bool {
+	return (r.mediaType == "*" || r.mediaType == mediaType) && (r.subtype == "*" || r.subtype == subtype)
+}
+
+// parseAccept parses the media ranges of an Accept header, skipping malformed entries.
+func parseAccept(header string) []acceptedMediaRange {
+	var ranges []acceptedMediaRange
+	for _, entry := range strings.Split(header, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		mediaType, subtype, ok := strings.Cut(mediaRange, "/")
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptedMediaRange{mediaType: mediaType, subtype: subtype, quality: quality})
+	}
+	return ranges
+}
+
+// NegotiateContentType picks the content type to respond with out of the available types per the request's Accept
+// header, honoring quality values and wildcards (*/* and type/*). The quality of each available type is taken from
+// the most specific media range matching it. Ties are broken by the order of available, list types in order of
+// preference.
+//
+// Returns the first available type if the request has no Accept header. Fails with a 406 [HandlerError] whose
+// failure lists the available types if none are acceptable, handlers may return it as is:
+//
+//	contentType, err := nexus.NegotiateContentType(request.HTTPRequest, []string{"application/json", "application/x-protobuf"})
+//	if err != nil {
+//		return nil, err
+//	}
+func NegotiateContentType(request *http.Request, available []string) (string, error) {
+	accept := strings.Join(request.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" && len(available) > 0 {
+		return available[0], nil
+	}
+	ranges := parseAccept(accept)
+	best, bestQuality := "", 0.0
+	for _, contentType := range available {
+		mediaType, subtype, _ := strings.Cut(contentType, "/")
+		specificity, quality := -1, 0.0
+		for _, r := range ranges {
+			if r.matches(mediaType, subtype) && r.specificity() > specificity {
+				specificity, quality = r.specificity(), r.quality
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = contentType, quality
+		}
+	}
+	if best == "" {
+		return "", newNotAcceptableError(available)
+	}
+	return best, nil
+}
+
+// newNotAcceptableError creates a 406 [HandlerError] listing the available content types in its failure details.
+func newNotAcceptableError(available []string) *HandlerError {
+	failure := &Failure{Message: fmt.Sprintf("none of the available content types are acceptable: %s", strings.Join(available, ", "))}
+	if details, err := json.Marshal(map[string][]string{"available": available}); err == nil {
+		failure.Details = details
+	}
+	return &HandlerError{
+		StatusCode: http.StatusNotAcceptable,
+		Failure:    failure,
+	}
+}
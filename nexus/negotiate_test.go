@@ -0,0 +1,88 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	available := []string{"application/json", "application/x-protobuf"}
+	cases := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: "application/json"},
+		{accept: "application/x-protobuf", expected: "application/x-protobuf"},
+		{accept: "application/json;q=0.8, application/x-protobuf;q=0.9", expected: "application/x-protobuf"},
+		{accept: "application/json;q=0.9, application/x-protobuf;q=0.8", expected: "application/json"},
+		{accept: "*/*", expected: "application/json"},
+		{accept: "application/*", expected: "application/json"},
+		// The most specific range determines the quality.
+		{accept: "application/*;q=0.5, application/json;q=0.1", expected: "application/x-protobuf"},
+		{accept: "*/*;q=0.1, application/x-protobuf", expected: "application/x-protobuf"},
+		// q=0 excludes a type.
+		{accept: "*/*, application/json;q=0", expected: "application/x-protobuf"},
+		// Malformed entries are skipped.
+		{accept: "invalid, application/x-protobuf;q=2, application/json;q=0.3", expected: "application/json"},
+	}
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/", nil)
+			if c.accept != "" {
+				request.Header.Set("Accept", c.accept)
+			}
+			contentType, err := NegotiateContentType(request, available)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, contentType)
+		})
+	}
+}
+
+func TestNegotiateContentType_NotAcceptable(t *testing.T) {
+	for _, accept := range []string{"text/plain", "text/*", "application/json;q=0, application/x-protobuf;q=0"} {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept", accept)
+		_, err := NegotiateContentType(request, []string{"application/json", "application/x-protobuf"})
+		var handlerError *HandlerError
+		require.ErrorAs(t, err, &handlerError)
+		require.Equal(t, http.StatusNotAcceptable, handlerError.StatusCode)
+		var details map[string][]string
+		require.NoError(t, json.Unmarshal(handlerError.Failure.Details, &details))
+		require.Equal(t, []string{"application/json", "application/x-protobuf"}, details["available"])
+	}
+}
+
+type negotiatingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *negotiatingHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	contentType, err := NegotiateContentType(request.HTTPRequest, []string{"application/json", "text/plain"})
+	if err != nil {
+		return nil, err
+	}
+	return &OperationResponseSync{Header: http.Header{headerContentType: []string{contentType}}}, nil
+}
+
+func TestNegotiateContentType_Handler(t *testing.T) {
+	ctx, client, teardown := setup(t, &negotiatingHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Header: http.Header{"Accept": []string{"text/*;q=0.9, */*;q=0.1"}}})
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, "text/plain", response.Header.Get(headerContentType))
+
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Header: http.Header{"Accept": []string{"image/png"}}})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotAcceptable, unexpectedError.Response.StatusCode)
+	require.Contains(t, unexpectedError.Failure.Message, "application/json, text/plain")
+}
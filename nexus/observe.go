@@ -0,0 +1,129 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Default for [ClientOptions.ObservedBodyLimit].
+const defaultObservedBodyLimit = 4096
+
+// ObservedRequest describes a request issued by a [Client], see [ClientOptions.OnRequest].
+type ObservedRequest struct {
+	// Name of the operation the request targets.
+	Operation string
+	Method    string
+	URL       *url.URL
+	Header    http.Header
+	// Prefix of the request body, capped at [ClientOptions.ObservedBodyLimit] bytes.
+	Body []byte
+}
+
+// ObservedResponse describes a response received by a [Client], see [ClientOptions.OnResponse].
+type ObservedResponse struct {
+	// Name of the operation the request targets.
+	Operation  string
+	Method     string
+	URL        *url.URL
+	StatusCode int
+	Header     http.Header
+	// Prefix of the response body read by the client or caller, capped at [ClientOptions.ObservedBodyLimit] bytes.
+	Body []byte
+}
+
+type operationContextKey struct{}
+
+// newRequest creates a request for the given operation, recording the operation name in the request's context for
+// request and response hooks and for attributing deprecation warnings.
+func (c *Client) newRequest(ctx context.Context, operation, method, url string, body io.Reader) (*http.Request, error) {
+	ctx = context.WithValue(ctx, operationContextKey{}, operation)
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// cappedBuffer records up to limit bytes written to it, discarding the rest.
+type cappedBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+	limit  int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if remaining := b.limit - b.buffer.Len(); remaining > 0 {
+		b.buffer.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buffer.Bytes())
+}
+
+// observedBody tees reads from a body into a capped buffer and invokes a callback once closed.
+type observedBody struct {
+	io.Reader
+	closer  io.Closer
+	once    sync.Once
+	onClose func()
+}
+
+func (b *observedBody) Close() error {
+	err := b.closer.Close()
+	b.once.Do(b.onClose)
+	return err
+}
+
+// observeHTTPCaller wraps an HTTP caller, invoking the configured request and response hooks. The request hook is invoked once
+// the caller returns, by which time the request body has been sent. The response hook is invoked once the response
+// body is closed.
+func observeHTTPCaller(options ClientOptions, caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	limit := options.ObservedBodyLimit
+	if limit <= 0 {
+		limit = defaultObservedBodyLimit
+	}
+	return func(request *http.Request) (*http.Response, error) {
+		operation, _ := request.Context().Value(operationContextKey{}).(string)
+		requestBody := &cappedBuffer{limit: limit}
+		if options.OnRequest != nil && request.Body != nil && request.Body != http.NoBody {
+			body := request.Body
+			request.Body = &observedBody{Reader: io.TeeReader(body, requestBody), closer: body, onClose: func() {}}
+		}
+		response, err := caller(request)
+		if options.OnRequest != nil {
+			options.OnRequest(ObservedRequest{
+				Operation: operation,
+				Method:    request.Method,
+				URL:       request.URL,
+				Header:    request.Header,
+				Body:      requestBody.Bytes(),
+			})
+		}
+		if err != nil || options.OnResponse == nil {
+			return response, err
+		}
+		responseBody := &cappedBuffer{limit: limit}
+		body := response.Body
+		response.Body = &observedBody{
+			Reader: io.TeeReader(body, responseBody),
+			closer: body,
+			onClose: func() {
+				options.OnResponse(ObservedResponse{
+					Operation:  operation,
+					Method:     request.Method,
+					URL:        request.URL,
+					StatusCode: response.StatusCode,
+					Header:     response.Header,
+					Body:       responseBody.Bytes(),
+				})
+			},
+		}
+		return response, nil
+	}
+}
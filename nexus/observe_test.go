@@ -0,0 +1,78 @@
+package nexus
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHooks(t *testing.T) {
+	var requests []ObservedRequest
+	var responses []ObservedResponse
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}}, ClientOptions{
+		ObservedBodyLimit: 4,
+		OnRequest: func(request ObservedRequest) {
+			requests = append(requests, request)
+		},
+		OnResponse: func(response ObservedResponse) {
+			responses = append(responses, response)
+		},
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Body:      bytes.NewReader([]byte("input")),
+	})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	// The caller reads the full body, only the observed copy is capped.
+	require.Equal(t, []byte("input"), body)
+	require.Len(t, responses, 0)
+	require.NoError(t, response.Body.Close())
+	require.NoError(t, response.Body.Close())
+
+	require.Len(t, requests, 1)
+	require.Equal(t, "foo", requests[0].Operation)
+	require.Equal(t, "POST", requests[0].Method)
+	require.Equal(t, userAgent, requests[0].Header.Get(headerUserAgent))
+	require.Equal(t, []byte("inpu"), requests[0].Body)
+
+	require.Len(t, responses, 1)
+	require.Equal(t, "foo", responses[0].Operation)
+	require.Equal(t, "POST", responses[0].Method)
+	require.Equal(t, http.StatusOK, responses[0].StatusCode)
+	require.Equal(t, []byte("inpu"), responses[0].Body)
+}
+
+func TestObserveHooks_HandleRequests(t *testing.T) {
+	var operations []string
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &asyncWithCancelHandler{}}, ClientOptions{
+		OnResponse: func(response ObservedResponse) {
+			operations = append(operations, response.Operation+" "+response.Method)
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("f/o/o", "a/sync")
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	require.Equal(t, []string{"f/o/o POST"}, operations)
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	n, err = b.Write([]byte("defg"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, []byte("abcde"), b.Bytes())
+}
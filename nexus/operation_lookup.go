@@ -0,0 +1,199 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Query parameter of operation lookup requests, identifying the start request to look up the operation of.
+const queryRequestID = "request_id"
+
+// ErrOperationNotFound is returned from [Client.LookupOperation] when the handler has no record of an operation started
+// by the given request.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// ErrOperationCompletedSynchronously is returned from [Client.LookupOperation] when the start operation request
+// completed the operation synchronously. The result is not recoverable, and retrying the request would run the
+// operation again.
+var ErrOperationCompletedSynchronously = errors.New("operation completed synchronously")
+
+// LookupOperationRequest is input for Handler.LookupOperation.
+type LookupOperationRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
+	// Operation name.
+	Operation string
+	// Request ID of the start operation request to look up the operation of, see [StartOperationRequest.RequestID].
+	RequestID string
+	// The original HTTP request.
+	HTTPRequest *http.Request
+}
+
+func (h *httpHandler) lookupOperation(writer http.ResponseWriter, request *http.Request) {
+	parsed, err := h.parseOperationPath(request, false, "")
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	requestID := request.URL.Query().Get(queryRequestID)
+	if requestID == "" {
+		h.writeFailure(writer, newBadRequestError("empty %s query parameter", queryRequestID))
+		return
+	}
+	handlerRequest := &LookupOperationRequest{
+		Service:     parsed.service,
+		Operation:   parsed.operation,
+		RequestID:   requestID,
+		HTTPRequest: request,
+	}
+	info, err := h.options.Handler.LookupOperation(request.Context(), handlerRequest)
+	err = h.checkUnimplemented(err, parsed.operation, "LookupOperation")
+	if err == nil && info == nil {
+		err = &HandlerError{StatusCode: http.StatusNotFound, Failure: &Failure{Message: "no operation started by request"}}
+	}
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	bytes, err := json.Marshal(info)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal operation info: %w", err))
+		return
+	}
+	writer.Header().Set(headerContentType, contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// LookupOperationOptions are options for [Client.LookupOperation].
+type LookupOperationOptions struct {
+	// Name of the service hosting the operation. Optional, defaults to [ClientOptions.Service].
+	Service string
+	// Name of the operation.
+	Operation string
+	// Request ID of the start operation request, see [StartOperationOptions.RequestID].
+	RequestID string
+	// Header to attach to the HTTP request. Optional.
+	Header http.Header
+}
+
+// LookupOperation gets a handle to the asynchronous operation started by the start operation request with the given
+// request ID, e.g. to recover an operation whose start response was lost. Fails with [ErrOperationNotFound] if the
+// handler has no record of the request, with [ErrOperationCompletedSynchronously] if the request completed the
+// operation synchronously, and with an [UnexpectedResponseError] if the handler does not support looking up
+// operations, with the response status set to 501.
+//
+// See [ClientOptions.RecoverStartTimeouts] for looking up operations automatically.
+func (c *Client) LookupOperation(ctx context.Context, options LookupOperationOptions) (*OperationHandle, error) {
+	ctx, cancel := c.withOperationTimeout(ctx, options.Operation)
+	defer cancel()
+	ctx, record := c.recordCall(ctx, options.Operation, ClientMethodLookupOperation)
+	handle, err := c.lookupOperation(ctx, c.baseURL(), options)
+	record(ClientCallOutcomeSuccessful, err)
+	return handle, c.mapError(err)
+}
+
+func (c *Client) lookupOperation(ctx context.Context, baseURL *url.URL, options LookupOperationOptions) (*OperationHandle, error) {
+	if options.Operation == "" {
+		return nil, errEmptyOperationName
+	}
+	if options.RequestID == "" {
+		return nil, errors.New("empty request ID")
+	}
+	if options.Service == "" {
+		options.Service = c.options.Service
+	}
+	url := joinOperationURL(baseURL, options.Service, options.Operation)
+	q := url.Query()
+	q.Set(queryRequestID, options.RequestID)
+	url.RawQuery = q.Encode()
+	if err := c.transformURL(url); err != nil {
+		return nil, err
+	}
+	request, err := c.newRequest(ctx, options.Operation, "GET", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if options.Header != nil {
+		request.Header = options.Header.Clone()
+	}
+	request.Header.Set(headerUserAgent, userAgent)
+	setBaggageHeader(ctx, request.Header)
+	response, err := c.sendIdempotentRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do this once here and make sure it doesn't leak.
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrOperationNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, c.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	}
+	info, err := c.operationInfoFromResponse(response, body)
+	if err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		switch info.State {
+		case OperationStateSucceeded, OperationStateFailed, OperationStateCanceled:
+			return nil, fmt.Errorf("%w: %s", ErrOperationCompletedSynchronously, info.State)
+		}
+		return nil, c.newUnexpectedResponseError("empty operation ID in response info", response, body)
+	}
+	handle := &OperationHandle{
+		Service:   options.Service,
+		Operation: options.Operation,
+		ID:        info.ID,
+		client:    c,
+	}
+	if c.balancer != nil {
+		handle.baseURL = baseURL
+	}
+	if info.Deadline != nil {
+		handle.deadline = *info.Deadline
+	}
+	return handle, nil
+}
+
+// isTransportTimeout returns true if err is a timeout of the transport, e.g. waiting for response headers, rather than
+// ctx being done.
+func isTransportTimeout(ctx context.Context, err error) bool {
+	var netErr net.Error
+	return ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// recoverStart looks up the operation started by a start request that timed out, see
+// [ClientOptions.RecoverStartTimeouts]. Returns the timeout error, annotated with the outcome of the lookup, if the
+// operation can't be recovered.
+func (c *Client) recoverStart(ctx context.Context, baseURL *url.URL, options StartOperationOptions, timeoutErr error) (*StartOperationResult, error) {
+	handle, err := c.lookupOperation(ctx, baseURL, LookupOperationOptions{
+		Service:   options.Service,
+		Operation: options.Operation,
+		RequestID: options.RequestID,
+		Header:    options.Header,
+	})
+	if err != nil {
+		if errors.Is(err, ErrOperationNotFound) {
+			return nil, fmt.Errorf("%w (not started: %w)", timeoutErr, err)
+		}
+		if errors.Is(err, ErrOperationCompletedSynchronously) {
+			return nil, fmt.Errorf("%w (result lost: %w)", timeoutErr, err)
+		}
+		return nil, fmt.Errorf("%w (failed to recover: %w)", timeoutErr, err)
+	}
+	c.options.Logger.Info("recovered operation after start request timed out", "operation", options.Operation, "requestID", options.RequestID, "operationID", handle.ID)
+	return &StartOperationResult{Pending: handle}, nil
+}
@@ -0,0 +1,154 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// lookupHandler starts asynchronous operations, except for the "sync" operation which completes synchronously,
+// recording the operation started by each request.
+type lookupHandler struct {
+	UnimplementedHandler
+	mu      sync.Mutex
+	started map[string]string
+}
+
+func (h *lookupHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if request.Operation == "sync" {
+		h.started[request.Operation+"/"+request.RequestID] = ""
+		return NewOperationResponseSync("done")
+	}
+	id := "op-" + request.RequestID
+	h.started[request.Operation+"/"+request.RequestID] = id
+	return &OperationResponseAsync{OperationID: id}, nil
+}
+
+func (h *lookupHandler) LookupOperation(ctx context.Context, request *LookupOperationRequest) (*OperationInfo, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id, ok := h.started[request.Operation+"/"+request.RequestID]
+	if !ok {
+		return nil, nil
+	}
+	if id == "" {
+		return &OperationInfo{State: OperationStateSucceeded}, nil
+	}
+	return &OperationInfo{ID: id, State: OperationStateRunning}, nil
+}
+
+// transportTimeoutError simulates a transport level timeout, e.g. waiting for response headers.
+type transportTimeoutError struct{}
+
+func (transportTimeoutError) Error() string   { return "timeout awaiting response headers" }
+func (transportTimeoutError) Timeout() bool   { return true }
+func (transportTimeoutError) Temporary() bool { return true }
+
+func TestLookupOperation(t *testing.T) {
+	ctx, client, teardown := setup(t, &lookupHandler{started: map[string]string{}})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: "abc"})
+	require.NoError(t, err)
+	require.Equal(t, "op-abc", result.Pending.ID)
+
+	handle, err := client.LookupOperation(ctx, LookupOperationOptions{Operation: "foo", RequestID: "abc"})
+	require.NoError(t, err)
+	require.Equal(t, "foo", handle.Operation)
+	require.Equal(t, "op-abc", handle.ID)
+
+	_, err = client.LookupOperation(ctx, LookupOperationOptions{Operation: "foo", RequestID: "def"})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+	_, err = client.LookupOperation(ctx, LookupOperationOptions{Operation: "bar", RequestID: "abc"})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "sync", RequestID: "abc"})
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Body.Close())
+	_, err = client.LookupOperation(ctx, LookupOperationOptions{Operation: "sync", RequestID: "abc"})
+	require.ErrorIs(t, err, ErrOperationCompletedSynchronously)
+	require.False(t, errors.Is(err, ErrOperationNotFound))
+	require.ErrorContains(t, err, "succeeded")
+}
+
+func TestLookupOperation_Unimplemented(t *testing.T) {
+	ctx, client, teardown := setup(t, &UnimplementedHandler{})
+	defer teardown()
+
+	_, err := client.LookupOperation(ctx, LookupOperationOptions{Operation: "foo", RequestID: "abc"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestRecoverStartTimeouts(t *testing.T) {
+	// Start requests for the "lost" operation time out before reaching the handler, other start requests time out
+	// after the handler started the operation.
+	timingOutCaller := func(request *http.Request) (*http.Response, error) {
+		if request.Method != "POST" {
+			return http.DefaultClient.Do(request)
+		}
+		if request.URL.Path != "/lost" {
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			response.Body.Close()
+		}
+		return nil, &transportTimeoutError{}
+	}
+	handler := &lookupHandler{started: map[string]string{}}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{
+		HTTPCaller:           timingOutCaller,
+		RecoverStartTimeouts: true,
+	})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: "abc"})
+	require.NoError(t, err)
+	require.Equal(t, "op-abc", result.Pending.ID)
+	require.Equal(t, "foo", result.Pending.Operation)
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "lost"})
+	require.ErrorAs(t, err, new(*transportTimeoutError))
+	require.ErrorIs(t, err, ErrOperationNotFound)
+
+	// The operation ran synchronously, its result is lost and it must not be retried.
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "sync"})
+	require.ErrorAs(t, err, new(*transportTimeoutError))
+	require.ErrorIs(t, err, ErrOperationCompletedSynchronously)
+	require.False(t, errors.Is(err, ErrOperationNotFound))
+
+	// Disabled by default.
+	_, client, teardown = setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{HTTPCaller: timingOutCaller})
+	defer teardown()
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: "def"})
+	require.ErrorAs(t, err, new(*transportTimeoutError))
+	require.False(t, errors.Is(err, ErrOperationNotFound))
+}
+
+func TestRecoverStartTimeouts_LookupFailure(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &UnimplementedHandler{}}, ClientOptions{
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			if request.Method == "POST" {
+				return nil, &transportTimeoutError{}
+			}
+			return http.DefaultClient.Do(request)
+		},
+		RecoverStartTimeouts: true,
+	})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.ErrorAs(t, err, new(*transportTimeoutError))
+	require.ErrorContains(t, err, "failed to recover")
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+}
@@ -0,0 +1,39 @@
+package nexus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Max length of operation names accepted by [DefaultOperationNameValidator], in bytes.
+const maxOperationNameLength = 256
+
+// reservedOperationName is the path segment of the bulk cancelation endpoint, starting an operation with this name would
+// be shadowed by the endpoint.
+var reservedOperationName = strings.TrimPrefix(cancelOperationsPath, "/")
+
+// checkReservedOperationName fails if operation is reserved for an endpoint of the handler.
+func checkReservedOperationName(operation string) error {
+	if operation == reservedOperationName {
+		return fmt.Errorf("operation name %q is reserved", operation)
+	}
+	return nil
+}
+
+// DefaultOperationNameValidator is the default [HandlerOptions.OperationNameValidator]. It accepts names of up to 256
+// bytes consisting of printable ASCII characters, including space. The name "_cancel" is reserved for the
+// handler's bulk cancelation endpoint.
+func DefaultOperationNameValidator(operation string) error {
+	if err := checkReservedOperationName(operation); err != nil {
+		return err
+	}
+	if len(operation) > maxOperationNameLength {
+		return fmt.Errorf("length %d exceeds max length of %d", len(operation), maxOperationNameLength)
+	}
+	for i := 0; i < len(operation); i++ {
+		if c := operation[i]; c < 0x20 || c > 0x7e {
+			return fmt.Errorf("non printable ASCII character at index %d", i)
+		}
+	}
+	return nil
+}
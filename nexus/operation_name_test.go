@@ -0,0 +1,75 @@
+package nexus
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationNameValidation(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &operationEchoHandler{}})
+	overlong := strings.Repeat("a", maxOperationNameLength+1)
+	cases := []struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{method: "POST", path: "/" + strings.Repeat("a", maxOperationNameLength), expectedStatus: http.StatusOK},
+		{method: "POST", path: "/with%20space", expectedStatus: http.StatusOK},
+		{method: "POST", path: "/" + overlong, expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"invalid operation name: length 257 exceeds max length of 256"}`},
+		{method: "POST", path: "/foo%0Abar", expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"invalid operation name: non printable ASCII character at index 3"}`},
+		{method: "POST", path: "/foo%1B%5B31m", expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"invalid operation name: non printable ASCII character at index 3"}`},
+		{method: "POST", path: "/caf%C3%A9", expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"invalid operation name: non printable ASCII character at index 3"}`},
+		{method: "GET", path: "/foo%00/abc", expectedStatus: http.StatusBadRequest},
+		{method: "GET", path: "/foo%7F/abc/result", expectedStatus: http.StatusBadRequest},
+		{method: "POST", path: "/" + overlong + "/abc/cancel", expectedStatus: http.StatusBadRequest},
+		// The name of the bulk cancelation endpoint is reserved.
+		{method: "GET", path: "/_cancel/abc", expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"invalid operation name: operation name \"_cancel\" is reserved"}`},
+	}
+	for _, c := range cases {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(c.method, c.path, strings.NewReader("{}")))
+		require.Equal(t, c.expectedStatus, writer.Code, c.path)
+		if c.expectedBody != "" {
+			require.JSONEq(t, c.expectedBody, writer.Body.String(), c.path)
+		}
+	}
+}
+
+func TestOperationNameValidation_CaseInsensitive(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &operationEchoHandler{}, CaseInsensitiveOperations: true})
+	// Names are lowercased before validation, reserved names are rejected in any case.
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/_CANCEL", strings.NewReader("{}")))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.JSONEq(t, `{"message":"invalid operation name: operation name \"_cancel\" is reserved"}`, writer.Body.String())
+}
+
+func TestOperationNameValidator(t *testing.T) {
+	pattern := regexp.MustCompile(`^\p{L}+$`)
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler: &operationEchoHandler{},
+		OperationNameValidator: func(operation string) error {
+			if !pattern.MatchString(operation) {
+				return errors.New("must consist of letters")
+			}
+			return nil
+		},
+	})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/caf%C3%A9", strings.NewReader("{}")))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, `"café:{}"`, writer.Body.String())
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo1", strings.NewReader("{}")))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.JSONEq(t, `{"message":"invalid operation name: must consist of letters"}`, writer.Body.String())
+}
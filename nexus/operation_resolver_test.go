@@ -0,0 +1,117 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// operationEchoHandler responds with the operation name and ID it was called with.
+type operationEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *operationEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input json.RawMessage
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(request.Operation + ":" + string(input))
+}
+
+func (h *operationEchoHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return NewOperationResponseSync(request.Operation + "/" + request.OperationID)
+}
+
+func (h *operationEchoHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	return &OperationInfo{ID: request.Operation + "/" + request.OperationID, State: OperationStateRunning}, nil
+}
+
+func (h *operationEchoHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	if request.Operation != "charge" {
+		return errors.New("unexpected operation")
+	}
+	return nil
+}
+
+func headerOperationResolver(request *http.Request) (string, error) {
+	switch operation := request.Header.Get("X-Operation"); operation {
+	case "":
+		return "", errors.New("missing X-Operation header")
+	case "hidden":
+		return "", &HandlerError{StatusCode: http.StatusNotFound, Failure: &Failure{Message: "not found"}}
+	default:
+		return operation, nil
+	}
+}
+
+func TestOperationResolver(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:                   &operationEchoHandler{},
+		OperationResolver:         headerOperationResolver,
+		CaseInsensitiveOperations: true,
+	})
+	cases := []struct {
+		method         string
+		path           string
+		operation      string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{method: "POST", path: "/gateway", operation: "Charge", expectedStatus: http.StatusOK, expectedBody: `"charge:{}"`},
+		{method: "GET", path: "/gateway/abc/result", operation: "charge", expectedStatus: http.StatusOK, expectedBody: `"charge/abc"`},
+		{method: "GET", path: "/gateway/abc", operation: "charge", expectedStatus: http.StatusOK, expectedBody: `{"id":"charge/abc","state":"running"}`},
+		{method: "POST", path: "/gateway/abc/cancel", operation: "charge", expectedStatus: http.StatusAccepted},
+		{method: "POST", path: "/charge", expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"failed to resolve operation: missing X-Operation header"}`},
+		{method: "POST", path: "/gateway", operation: "hidden", expectedStatus: http.StatusNotFound, expectedBody: `{"message":"not found"}`},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest(c.method, c.path, bytes.NewReader([]byte("{}")))
+		if c.operation != "" {
+			request.Header.Set("X-Operation", c.operation)
+		}
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		require.Equal(t, c.expectedStatus, writer.Code, c.path)
+		if c.expectedBody != "" {
+			require.JSONEq(t, c.expectedBody, writer.Body.String(), c.path)
+		}
+	}
+}
+
+func TestOperationResolver_Body(t *testing.T) {
+	// Resolves the operation from an envelope in the request body, replacing the body with the envelope's input.
+	resolver := func(request *http.Request) (string, error) {
+		if request.Method != "POST" {
+			return "", errors.New("unsupported method")
+		}
+		var envelope struct {
+			Operation string          `json:"operation"`
+			Input     json.RawMessage `json:"input"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&envelope); err != nil {
+			return "", err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(envelope.Input))
+		request.ContentLength = int64(len(envelope.Input))
+		return envelope.Operation, nil
+	}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &operationEchoHandler{}, OperationResolver: resolver}, ClientOptions{})
+	defer teardown()
+
+	options, err := NewStartOperationOptions("gateway", map[string]any{"operation": "charge", "input": 3})
+	require.NoError(t, err)
+	result, err := client.StartOperation(ctx, options)
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	b, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, `"charge:3"`, string(b))
+}
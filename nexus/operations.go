@@ -0,0 +1,45 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Path of the opt-in operation introspection endpoint, see [HandlerOptions.ExposeOperations].
+const operationsPath = "/_operations"
+
+// OperationDescription describes an operation registered with a [Handler], for tooling and documentation generation.
+type OperationDescription struct {
+	// Service of the operation, see [HandlerOptions.ServiceRouting]. Empty for operations without a service.
+	Service string `json:"service,omitempty"`
+	// Name of the operation.
+	Name string `json:"name"`
+	// Name of the operation's input type. Optional.
+	InputType string `json:"inputType,omitempty"`
+	// Name of the operation's output type. Optional.
+	OutputType string `json:"outputType,omitempty"`
+}
+
+// OperationLister may be implemented by a [Handler] to describe the operations it handles.
+// See [HandlerOptions.ExposeOperations].
+type OperationLister interface {
+	ListOperations() []OperationDescription
+}
+
+func (h *httpHandler) listOperations(writer http.ResponseWriter, request *http.Request) {
+	// Validated to implement OperationLister in NewHTTPHandler.
+	operations := h.operationLister.ListOperations()
+	if operations == nil {
+		operations = []OperationDescription{}
+	}
+	bytes, err := json.Marshal(operations)
+	if err != nil {
+		h.writeFailure(writer, fmt.Errorf("failed to marshal operations: %w", err))
+		return
+	}
+	writer.Header().Set(headerContentType, contentTypeJSON)
+	if _, err := writer.Write(bytes); err != nil {
+		h.logger.Error("failed to write response body", "error", err)
+	}
+}
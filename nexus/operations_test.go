@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type listingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *listingHandler) ListOperations() []OperationDescription {
+	return []OperationDescription{
+		{Name: "_operations"},
+		{Name: "charge", InputType: "ChargeInput", OutputType: "ChargeOutput"},
+	}
+}
+
+func (h *listingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(request.Operation)
+}
+
+func TestExposeOperations(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &listingHandler{}, ExposeOperations: true})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/_operations", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, contentTypeJSON, writer.Header().Get(headerContentType))
+	var operations []OperationDescription
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &operations))
+	require.Equal(t, (&listingHandler{}).ListOperations(), operations)
+
+	// An operation named _operations may still be started.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/_operations", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, `"_operations"`, writer.Body.String())
+
+	// The endpoint is opt-in.
+	writer = httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: &listingHandler{}}).ServeHTTP(writer, httptest.NewRequest("GET", "/_operations", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, writer.Code)
+
+	require.PanicsWithError(t, "nexus: HandlerOptions.ExposeOperations requires a Handler that implements OperationLister", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, ExposeOperations: true})
+	})
+}
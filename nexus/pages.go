@@ -0,0 +1,72 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrNoMorePages is returned by [ResultPageIterator.Next] when called after the last page was returned.
+var ErrNoMorePages = errors.New("no more pages")
+
+// NextPageToken returns the token for fetching the next page of a paginated result response, e.g. from
+// [OperationHandle.GetResult], as set by the handler via [OperationResponseSync.NextPageToken]. Returns an empty string
+// if the response carries the last page.
+func NextPageToken(response *http.Response) string {
+	return response.Header.Get(headerNextPageToken)
+}
+
+// NextPage gets the result page identified by token, as returned by [NextPageToken], issuing a network request to the
+// service handler. It is a shorthand for calling [OperationHandle.GetResult] with
+// [GetOperationResultOptions.PageToken] set.
+//
+// ⚠️ If a response is returned, its body must be read in its entirety and closed to free up the underlying connection.
+func (h *OperationHandle) NextPage(ctx context.Context, token string) (*http.Response, error) {
+	return h.GetResult(ctx, GetOperationResultOptions{PageToken: token})
+}
+
+// ResultPageIterator iterates over the pages of an operation's paginated result. Obtain one with
+// [OperationHandle.ResultPages].
+//
+//	pages := handle.ResultPages(nexus.GetOperationResultOptions{})
+//	for pages.HasNext() {
+//		response, err := pages.Next(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		// Read and close response.Body.
+//	}
+type ResultPageIterator struct {
+	handle  *OperationHandle
+	options GetOperationResultOptions
+	done    bool
+}
+
+// ResultPages returns an iterator over the pages of the operation's result, starting with the page identified by
+// options.PageToken, or the first page if empty. Options are applied to every page request.
+func (h *OperationHandle) ResultPages(options GetOperationResultOptions) *ResultPageIterator {
+	return &ResultPageIterator{handle: h, options: options}
+}
+
+// HasNext returns true if there are more pages to get.
+func (it *ResultPageIterator) HasNext() bool {
+	return !it.done
+}
+
+// Next gets the next page of the result via [OperationHandle.GetResult]. Iteration ends once a page without a next
+// page token is returned or an error occurs, errors are returned as is.
+//
+// ⚠️ If a response is returned, its body must be read in its entirety and closed to free up the underlying connection.
+func (it *ResultPageIterator) Next(ctx context.Context) (*http.Response, error) {
+	if it.done {
+		return nil, ErrNoMorePages
+	}
+	response, err := it.handle.GetResult(ctx, it.options)
+	if err != nil {
+		it.done = true
+		return nil, err
+	}
+	it.options.PageToken = NextPageToken(response)
+	it.done = it.options.PageToken == ""
+	return response, nil
+}
@@ -0,0 +1,90 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type paginatedResultHandler struct {
+	UnimplementedHandler
+	pages []string
+}
+
+func (h *paginatedResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	index := 0
+	if request.PageToken != "" {
+		var err error
+		if index, err = strconv.Atoi(request.PageToken); err != nil || index < 0 || index >= len(h.pages) {
+			return nil, newBadRequestError("invalid page token: %q", request.PageToken)
+		}
+	}
+	response, err := NewOperationResponseSync(h.pages[index])
+	if err != nil {
+		return nil, err
+	}
+	if index+1 < len(h.pages) {
+		response.NextPageToken = strconv.Itoa(index + 1)
+	}
+	return response, nil
+}
+
+func readPage(t *testing.T, body io.ReadCloser) string {
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestNextPage(t *testing.T) {
+	ctx, client, teardown := setup(t, &paginatedResultHandler{pages: []string{"a", "b"}})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.Equal(t, `"a"`, readPage(t, response.Body))
+	token := NextPageToken(response)
+	require.Equal(t, "1", token)
+
+	response, err = handle.NextPage(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, `"b"`, readPage(t, response.Body))
+	require.Equal(t, "", NextPageToken(response))
+
+	_, err = handle.NextPage(ctx, "5")
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+}
+
+func TestResultPages(t *testing.T) {
+	ctx, client, teardown := setup(t, &paginatedResultHandler{pages: []string{"a", "b", "c"}})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	var pages []string
+	it := handle.ResultPages(GetOperationResultOptions{})
+	for it.HasNext() {
+		response, err := it.Next(ctx)
+		require.NoError(t, err)
+		pages = append(pages, readPage(t, response.Body))
+	}
+	require.Equal(t, []string{`"a"`, `"b"`, `"c"`}, pages)
+	_, err = it.Next(ctx)
+	require.ErrorIs(t, err, ErrNoMorePages)
+
+	// Iteration may start from an arbitrary page.
+	pages = nil
+	it = handle.ResultPages(GetOperationResultOptions{PageToken: "2"})
+	for it.HasNext() {
+		response, err := it.Next(ctx)
+		require.NoError(t, err)
+		pages = append(pages, readPage(t, response.Body))
+	}
+	require.Equal(t, []string{`"c"`}, pages)
+}
@@ -0,0 +1,74 @@
+package nexus
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers conveying quota information on responses to requests rejected with a [QuotaExceededError].
+const (
+	headerRateLimitLimit     = "Nexus-RateLimit-Limit"
+	headerRateLimitRemaining = "Nexus-RateLimit-Remaining"
+	headerRateLimitReset     = "Nexus-RateLimit-Reset"
+)
+
+// QuotaExceededError reports that a caller has exhausted its quota.
+//
+// Return a QuotaExceededError from a [Handler] method to fail the request with a 429 status code. The quota information
+// is sent in Nexus-RateLimit-* response headers, along with a Retry-After header when ResetAt is set, and parsed back by
+// the client into a *QuotaExceededError that can be extracted from the returned error with [errors.As].
+type QuotaExceededError struct {
+	// Max number of requests, or units of any other resource, allowed in the quota window.
+	Limit int
+	// Number of requests remaining in the current quota window, typically zero.
+	Remaining int
+	// Time at which the quota resets. Optional.
+	ResetAt time.Time
+}
+
+// Error implements the error interface.
+func (e *QuotaExceededError) Error() string {
+	message := fmt.Sprintf("quota exceeded: %d of %d remaining", e.Remaining, e.Limit)
+	if !e.ResetAt.IsZero() {
+		message += fmt.Sprintf(", resets at %s", e.ResetAt.UTC().Format(time.RFC3339))
+	}
+	return message
+}
+
+// setHeaders sets the quota headers on a response.
+func (e *QuotaExceededError) setHeaders(header http.Header, now time.Time) {
+	header.Set(headerRateLimitLimit, strconv.Itoa(e.Limit))
+	header.Set(headerRateLimitRemaining, strconv.Itoa(e.Remaining))
+	if !e.ResetAt.IsZero() {
+		header.Set(headerRateLimitReset, e.ResetAt.UTC().Format(time.RFC3339))
+		// Rounded up to whole seconds.
+		retryAfter := int64(math.Ceil(max(e.ResetAt.Sub(now), 0).Seconds()))
+		header.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	}
+}
+
+// quotaExceededErrorFromResponse parses a [QuotaExceededError] from the headers of a 429 response, returning nil if
+// the response is missing the quota headers or they are malformed.
+func quotaExceededErrorFromResponse(response *http.Response) *QuotaExceededError {
+	if response.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	limit, err := strconv.Atoi(response.Header.Get(headerRateLimitLimit))
+	if err != nil {
+		return nil
+	}
+	remaining, err := strconv.Atoi(response.Header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return nil
+	}
+	quotaError := &QuotaExceededError{Limit: limit, Remaining: remaining}
+	if value := response.Header.Get(headerRateLimitReset); value != "" {
+		if quotaError.ResetAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil
+		}
+	}
+	return quotaError
+}
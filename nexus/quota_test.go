@@ -0,0 +1,97 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type quotaHandler struct {
+	UnimplementedHandler
+	resetAt time.Time
+}
+
+func (h *quotaHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, &QuotaExceededError{Limit: 100, Remaining: 0, ResetAt: h.resetAt}
+}
+
+func TestQuotaExceededError_Headers(t *testing.T) {
+	now := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cases := []*QuotaExceededError{
+		{Limit: 100, Remaining: 0, ResetAt: now.Add(time.Millisecond * 1500)},
+		{Limit: 5, Remaining: 2},
+	}
+	for _, quotaError := range cases {
+		t.Run(quotaError.Error(), func(t *testing.T) {
+			response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			quotaError.setHeaders(response.Header, now)
+			parsed := quotaExceededErrorFromResponse(response)
+			require.NotNil(t, parsed)
+			require.Equal(t, quotaError.Limit, parsed.Limit)
+			require.Equal(t, quotaError.Remaining, parsed.Remaining)
+			// The reset time is sent with second precision.
+			require.Equal(t, quotaError.ResetAt.Truncate(time.Second), parsed.ResetAt)
+			if quotaError.ResetAt.IsZero() {
+				require.Empty(t, response.Header.Get("Retry-After"))
+			} else {
+				require.Equal(t, "2", response.Header.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestQuotaExceededError_MalformedHeaders(t *testing.T) {
+	for _, header := range []http.Header{
+		{},
+		{headerRateLimitLimit: []string{"100"}},
+		{headerRateLimitLimit: []string{"a"}, headerRateLimitRemaining: []string{"0"}},
+		{headerRateLimitLimit: []string{"100"}, headerRateLimitRemaining: []string{"0"}, headerRateLimitReset: []string{"tomorrow"}},
+	} {
+		response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}
+		require.Nil(t, quotaExceededErrorFromResponse(response))
+	}
+	// Only 429 responses carry quota information.
+	response := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{
+		headerRateLimitLimit:     []string{"100"},
+		headerRateLimitRemaining: []string{"0"},
+	}}
+	require.Nil(t, quotaExceededErrorFromResponse(response))
+}
+
+func TestQuotaExceededError_RoundTrip(t *testing.T) {
+	resetAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	ctx, client, teardown := setup(t, &quotaHandler{resetAt: resetAt})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusTooManyRequests, unexpectedError.Response.StatusCode)
+	var quotaError *QuotaExceededError
+	require.ErrorAs(t, err, &quotaError)
+	require.Equal(t, 100, quotaError.Limit)
+	require.Equal(t, 0, quotaError.Remaining)
+	require.True(t, resetAt.Equal(quotaError.ResetAt))
+	require.Contains(t, unexpectedError.Failure.Message, "quota exceeded: 0 of 100 remaining")
+}
+
+func TestQuotaExceededError_Wrapped(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &wrappedQuotaHandler{}})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusTooManyRequests, writer.Code)
+	require.Equal(t, "10", writer.Header().Get(headerRateLimitLimit))
+}
+
+type wrappedQuotaHandler struct {
+	UnimplementedHandler
+}
+
+func (h *wrappedQuotaHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, errors.Join(errors.New("tenant acme"), &QuotaExceededError{Limit: 10})
+}
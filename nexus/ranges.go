@@ -0,0 +1,222 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerRange        = "Range"
+	headerContentRange = "Content-Range"
+	headerAcceptRanges = "Accept-Ranges"
+)
+
+const rangeUnitBytes = "bytes"
+
+// byteRange is an inclusive range of bytes in a response body.
+type byteRange struct {
+	start, end int64
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseByteRange parses the value of a Range header for a body of the given size.
+//
+// Only a single range in bytes is supported. Returns ok false for headers that should be ignored, in which case the
+// entire body is served, as allowed by RFC 9110, and errRangeNotSatisfiable for ranges that don't overlap the body.
+func parseByteRange(value string, size int64) (r byteRange, ok bool, err error) {
+	spec, found := strings.CutPrefix(value, rangeUnitBytes+"=")
+	if !found || strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return byteRange{}, false, nil
+	}
+	if first == "" {
+		// Suffix range, e.g. "bytes=-500" for the last 500 bytes.
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return byteRange{}, false, nil
+		}
+		if suffix == 0 {
+			return byteRange{}, true, errRangeNotSatisfiable
+		}
+		return byteRange{start: max(size-suffix, 0), end: size - 1}, true, nil
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return byteRange{}, true, errRangeNotSatisfiable
+	}
+	return byteRange{start: start, end: end}, true, nil
+}
+
+// parseContentRange parses the value of a Content-Range header of a 206 response, e.g. "bytes 100-199/1000".
+func parseContentRange(value string) (r byteRange, size int64, err error) {
+	spec, found := strings.CutPrefix(value, rangeUnitBytes+" ")
+	if !found {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	rangeSpec, sizeSpec, found := strings.Cut(spec, "/")
+	if !found {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	first, last, found := strings.Cut(rangeSpec, "-")
+	if !found {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	if r.start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	if r.end, err = strconv.ParseInt(last, 10, 64); err != nil {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	if size, err = strconv.ParseInt(sizeSpec, 10, 64); err != nil {
+		return byteRange{}, 0, fmt.Errorf("invalid %s header: %q", headerContentRange, value)
+	}
+	return r, size, nil
+}
+
+// applyRange serves the range requested in the request's Range header for a seekable body of known length, returning
+// the reader to copy the response body from. Returns an error if the response has been written without the body
+// instead, e.g. when the range is not satisfiable.
+func (r *OperationResponseSync) applyRange(writer http.ResponseWriter, request *http.Request, handler *httpHandler, body io.ReadSeeker) (io.Reader, error) {
+	header := writer.Header()
+	header.Set(headerAcceptRanges, rangeUnitBytes)
+	value := request.Header.Get(headerRange)
+	if value == "" {
+		return body, nil
+	}
+	rng, ok, err := parseByteRange(value, r.ContentLength)
+	if !ok {
+		return body, nil
+	}
+	if err != nil {
+		header.Del("Content-Length")
+		header.Set(headerContentRange, fmt.Sprintf("%s */%d", rangeUnitBytes, r.ContentLength))
+		writer.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return nil, err
+	}
+	if _, err := body.Seek(rng.start, io.SeekStart); err != nil {
+		handler.logger.Error("failed to seek response body", "error", err)
+		header.Del("Content-Length")
+		header.Del(headerAcceptRanges)
+		handler.writeFailure(writer, err)
+		return nil, err
+	}
+	length := rng.end - rng.start + 1
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	header.Set(headerContentRange, fmt.Sprintf("%s %d-%d/%d", rangeUnitBytes, rng.start, rng.end, r.ContentLength))
+	writer.WriteHeader(http.StatusPartialContent)
+	return io.LimitReader(body, length), nil
+}
+
+// makeResumable replaces the body of a result response with one that resumes reading from the last byte read when
+// reading fails, e.g. due to a dropped connection, by issuing requests with a Range header. Responses from handlers that
+// don't support range requests, or that don't specify the length of the body, are left as is.
+func (h *OperationHandle) makeResumable(request *http.Request, response *http.Response, maxResumes int) {
+	if response.Header.Get(headerAcceptRanges) != rangeUnitBytes || response.ContentLength <= 0 {
+		return
+	}
+	response.Body = &resumableBody{
+		handle:     h,
+		request:    request,
+		body:       response.Body,
+		size:       response.ContentLength,
+		maxResumes: maxResumes,
+	}
+}
+
+// resumableBody reads a result response body, transparently resuming the download on read failures.
+type resumableBody struct {
+	handle     *OperationHandle
+	request    *http.Request
+	body       io.ReadCloser
+	offset     int64
+	size       int64
+	resumes    int
+	maxResumes int
+	// Error from the last failed attempt to resume, returned from all subsequent reads.
+	err error
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	for {
+		n, err := b.body.Read(p)
+		b.offset += int64(n)
+		if err == nil || err == io.EOF || !b.canResume(err) {
+			return n, err
+		}
+		if !b.handle.client.retryBudget.allowRetry() {
+			b.err = fmt.Errorf("%w (not resumed: %w)", err, ErrRetryBudgetExhausted)
+			return n, b.err
+		}
+		b.resumes++
+		if resumeErr := b.resume(); resumeErr != nil {
+			b.err = fmt.Errorf("%w (failed to resume: %w)", err, resumeErr)
+			return n, b.err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (b *resumableBody) canResume(err error) bool {
+	return b.resumes < b.maxResumes && b.offset < b.size && b.request.Context().Err() == nil &&
+		!errors.Is(err, context.Canceled)
+}
+
+func (b *resumableBody) resume() error {
+	b.body.Close()
+	request := b.request.Clone(b.request.Context())
+	request.Header.Set(headerRange, fmt.Sprintf("%s=%d-", rangeUnitBytes, b.offset))
+	response, err := b.handle.client.options.HTTPCaller(request)
+	if err != nil {
+		b.body = http.NoBody
+		return err
+	}
+	if response.StatusCode != http.StatusPartialContent {
+		body, err := readAndReplaceBody(response)
+		b.body = http.NoBody
+		if err != nil {
+			return err
+		}
+		return b.handle.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	}
+	rng, size, err := parseContentRange(response.Header.Get(headerContentRange))
+	if err == nil && (rng.start != b.offset || size != b.size) {
+		err = fmt.Errorf("%s header does not match the requested range: %q", headerContentRange, response.Header.Get(headerContentRange))
+	}
+	if err != nil {
+		response.Body.Close()
+		b.body = http.NoBody
+		return err
+	}
+	b.handle.client.applyResponseBodyIdleTimeout(response)
+	b.body = response.Body
+	return nil
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,183 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const rangeTestResult = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+type seekableResultHandler struct {
+	UnimplementedHandler
+	// Hide the body's Seek method to simulate a handler that doesn't support range requests.
+	unseekable bool
+}
+
+func (h *seekableResultHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	var body io.Reader = strings.NewReader(rangeTestResult)
+	if h.unseekable {
+		body = io.MultiReader(body)
+	}
+	return &OperationResponseSync{
+		Body:          body,
+		ContentLength: int64(len(rangeTestResult)),
+	}, nil
+}
+
+func TestParseByteRange(t *testing.T) {
+	cases := []struct {
+		value string
+		ok    bool
+		err   error
+		rng   byteRange
+	}{
+		{value: "bytes=0-9", ok: true, rng: byteRange{0, 9}},
+		{value: "bytes=10-", ok: true, rng: byteRange{10, 35}},
+		{value: "bytes=30-100", ok: true, rng: byteRange{30, 35}},
+		{value: "bytes=-6", ok: true, rng: byteRange{30, 35}},
+		{value: "bytes=-100", ok: true, rng: byteRange{0, 35}},
+		{value: "bytes=36-", ok: true, err: errRangeNotSatisfiable},
+		{value: "bytes=-0", ok: true, err: errRangeNotSatisfiable},
+		{value: "bytes=0-1,5-6", ok: false},
+		{value: "bytes=5-1", ok: false},
+		{value: "items=0-1", ok: false},
+		{value: "bytes=a-b", ok: false},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			rng, ok, err := parseByteRange(c.value, int64(len(rangeTestResult)))
+			require.Equal(t, c.ok, ok)
+			require.ErrorIs(t, err, c.err)
+			if c.err == nil {
+				require.Equal(t, c.rng, rng)
+			}
+		})
+	}
+}
+
+func TestRangeRequest(t *testing.T) {
+	ctx, client, teardown := setup(t, &seekableResultHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	url := handle.URL().String() + "/result"
+
+	get := func(rangeHeader string) (*http.Response, string) {
+		request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		require.NoError(t, err)
+		if rangeHeader != "" {
+			request.Header.Set(headerRange, rangeHeader)
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response, string(body)
+	}
+
+	response, body := get("")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "bytes", response.Header.Get(headerAcceptRanges))
+	require.Equal(t, rangeTestResult, body)
+
+	response, body = get("bytes=10-19")
+	require.Equal(t, http.StatusPartialContent, response.StatusCode)
+	require.Equal(t, "bytes 10-19/36", response.Header.Get(headerContentRange))
+	require.Equal(t, int64(10), response.ContentLength)
+	require.Equal(t, "abcdefghij", body)
+
+	response, _ = get("bytes=100-")
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, response.StatusCode)
+	require.Equal(t, "bytes */36", response.Header.Get(headerContentRange))
+
+	// Multiple ranges are not supported, the entire result is served.
+	response, body = get("bytes=0-1,5-6")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, rangeTestResult, body)
+}
+
+// failingReader fails with io.ErrUnexpectedEOF after reading limit bytes, simulating a dropped connection.
+type failingReader struct {
+	io.ReadCloser
+	limit int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.limit <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n, err := r.ReadCloser.Read(p[:min(len(p), r.limit)])
+	r.limit -= n
+	return n, err
+}
+
+func TestGetResult_Resume(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []string
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &seekableResultHandler{}}, ClientOptions{
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ranges = append(ranges, request.Header.Get(headerRange))
+			// Drop the connection after 10 bytes on every response.
+			response.Body = &failingReader{ReadCloser: response.Body, limit: 10}
+			return response, nil
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 3})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, rangeTestResult, string(body))
+	require.Equal(t, []string{"", "bytes=10-", "bytes=20-", "bytes=30-"}, ranges)
+
+	// Reading fails once resumes are exhausted.
+	ranges = nil
+	response, err = handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 1})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Equal(t, rangeTestResult[:20], string(body))
+	require.Equal(t, []string{"", "bytes=10-"}, ranges)
+}
+
+func TestGetResult_ResumeUnsupported(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &seekableResultHandler{unseekable: true}}, ClientOptions{
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			response.Body = &failingReader{ReadCloser: response.Body, limit: 1}
+			return response, nil
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 3})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Empty(t, response.Header.Get(headerAcceptRanges))
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
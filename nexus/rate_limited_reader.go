@@ -0,0 +1,57 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// RateLimitedReader wraps an [io.Reader], limiting the rate at which it is read. Handlers ingesting large request
+// bodies may wrap [StartOperationRequest.HTTPRequest] Body with it to consume the input at their own pace: the
+// server stops reading from the connection while the handler is throttled, and TCP flow control propagates the
+// backpressure to the caller once the connection's buffers fill up.
+//
+//	body := nexus.NewRateLimitedReader(ctx, request.HTTPRequest.Body, 1<<20) // 1 MiB/s
+//	_, err := io.Copy(sink, body)
+type RateLimitedReader struct {
+	ctx            context.Context
+	reader         io.Reader
+	bytesPerSecond int
+	start          time.Time
+	read           int64
+}
+
+// NewRateLimitedReader creates a [RateLimitedReader] reading from reader at no more than bytesPerSecond on average.
+// Reads block while throttled, failing with ctx's error if ctx is done first.
+//
+// Panics if bytesPerSecond is not positive.
+func NewRateLimitedReader(ctx context.Context, reader io.Reader, bytesPerSecond int) *RateLimitedReader {
+	if bytesPerSecond <= 0 {
+		panic("nexus: bytesPerSecond must be positive")
+	}
+	return &RateLimitedReader{ctx: ctx, reader: reader, bytesPerSecond: bytesPerSecond}
+}
+
+// Read implements [io.Reader]. Each read is capped at bytesPerSecond bytes.
+func (r *RateLimitedReader) Read(p []byte) (int, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	// Wait until the bytes read so far are within the allowed rate.
+	due := r.start.Add(time.Duration(r.read) * time.Second / time.Duration(r.bytesPerSecond))
+	if wait := time.Until(due); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return 0, r.ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if len(p) > r.bytesPerSecond {
+		p = p[:r.bytesPerSecond]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
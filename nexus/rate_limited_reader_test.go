@@ -0,0 +1,83 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	reader := NewRateLimitedReader(context.Background(), bytes.NewReader(make([]byte, 1000)), 4000)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), n)
+	// The body is read at once, the subsequent read waits for the rate to catch up.
+	require.GreaterOrEqual(t, time.Since(start), time.Millisecond*200)
+}
+
+func TestRateLimitedReader_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	reader := NewRateLimitedReader(ctx, bytes.NewReader(make([]byte, 1000)), 10)
+	_, err := io.Copy(io.Discard, reader)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type slowIngestionHandler struct {
+	UnimplementedHandler
+	read atomic.Int64
+}
+
+func (h *slowIngestionHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	n, err := io.Copy(io.Discard, NewRateLimitedReader(ctx, request.HTTPRequest.Body, 1<<20))
+	h.read.Add(n)
+	if err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(n)
+}
+
+// countingReader produces size zero bytes, counting the bytes read from it.
+type countingReader struct {
+	size int64
+	read atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	remaining := r.size - r.read.Load()
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(p)), remaining))
+	clear(p[:n])
+	r.read.Add(int64(n))
+	return n, nil
+}
+
+func TestRateLimitedReader_Backpressure(t *testing.T) {
+	handler := &slowIngestionHandler{}
+	ctx, client, teardown := setup(t, handler)
+	defer teardown()
+
+	body := &countingReader{size: 256 << 20}
+	startCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.StartOperation(startCtx, StartOperationOptions{Operation: "foo", Body: body})
+		done <- err
+	}()
+
+	time.Sleep(time.Millisecond * 500)
+	// The client is throttled by the handler: it sent no more than what the handler consumed plus what fits in the
+	// connection's buffers, far less than the entire body.
+	require.Less(t, body.read.Load(), body.size/2)
+	require.Greater(t, body.read.Load(), int64(0))
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
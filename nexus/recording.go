@@ -0,0 +1,219 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Placeholders for values that change between runs, substituted when recording interactions.
+const (
+	maskedRequestID = "<request-id>"
+	maskedCallback  = "<callback>"
+)
+
+// ErrReplayMismatch indicates that a request made against a [ReplayTransport] does not match the next recorded
+// interaction.
+var ErrReplayMismatch = errors.New("request does not match recorded interaction")
+
+// RecordedRequest is the normalized form of a request in a [RecordedInteraction].
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// Path and query of the request URL. The host is omitted since it typically changes between runs, e.g. when
+	// recording against an [net/http/httptest.Server].
+	URI    string      `json:"uri"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response in a [RecordedInteraction].
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// RecordedInteraction is a request and the response it got, as recorded by a [RecordingTransport].
+type RecordedInteraction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// normalizeRequest records a request, masking values that change between runs: the request ID generated by the
+// client and callback URLs, which typically point at ephemeral endpoints.
+func normalizeRequest(request *http.Request, body []byte) RecordedRequest {
+	header := request.Header.Clone()
+	if header.Get(headerRequestID) != "" {
+		header.Set(headerRequestID, maskedRequestID)
+	}
+	if values := header.Values(headerCallback); len(values) > 0 {
+		header.Del(headerCallback)
+		for range values {
+			header.Add(headerCallback, maskedCallback)
+		}
+	}
+	u := *request.URL
+	if query := u.Query(); query.Has(queryCallbackURL) {
+		query.Set(queryCallbackURL, maskedCallback)
+		u.RawQuery = query.Encode()
+	}
+	return RecordedRequest{
+		Method: request.Method,
+		URI:    u.RequestURI(),
+		Header: header,
+		Body:   body,
+	}
+}
+
+// readRequestBody reads and closes a request's body.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+	defer request.Body.Close()
+	return io.ReadAll(request.Body)
+}
+
+// RecordingTransport is an [http.RoundTripper] that records the interactions made through it, for replaying them
+// later with a [ReplayTransport], e.g. for deterministic tests. Wire it into a [Client] via [ClientOptions.HTTPCaller]:
+//
+//	recorder := &nexus.RecordingTransport{}
+//	client, err := nexus.NewClient(nexus.ClientOptions{
+//		ServiceBaseURL: url,
+//		HTTPCaller:     (&http.Client{Transport: recorder}).Do,
+//	})
+//
+// Request and response bodies are buffered in memory, it is not meant for production use.
+type RecordingTransport struct {
+	// Transport to issue requests with. Defaults to [http.DefaultTransport].
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []RecordedInteraction
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	requestBody, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+	outgoing := request
+	if requestBody != nil {
+		// Round trippers must not modify the request, send a copy with the buffered body.
+		outgoing = request.Clone(request.Context())
+		outgoing.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err := transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readAndReplaceBody(response)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interactions = append(t.interactions, RecordedInteraction{
+		Request: normalizeRequest(request, requestBody),
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+			Body:       responseBody,
+		},
+	})
+	return response, nil
+}
+
+// Interactions returns the interactions recorded so far, in order.
+func (t *RecordingTransport) Interactions() []RecordedInteraction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]RecordedInteraction(nil), t.interactions...)
+}
+
+// Save writes the interactions recorded so far to a JSON file at path, to be loaded with [LoadReplayTransport].
+func (t *RecordingTransport) Save(path string) error {
+	b, err := json.MarshalIndent(t.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// ReplayTransport is an [http.RoundTripper] that replays interactions recorded by a [RecordingTransport] in order,
+// without issuing network requests.
+//
+// Each request must match the next recorded interaction's method, path, query, and body, after masking request IDs and
+// callback URLs. Otherwise, RoundTrip fails with [ErrReplayMismatch].
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions []RecordedInteraction
+	next         int
+}
+
+// NewReplayTransport creates a [ReplayTransport] replaying the given interactions.
+func NewReplayTransport(interactions []RecordedInteraction) *ReplayTransport {
+	return &ReplayTransport{interactions: interactions}
+}
+
+// LoadReplayTransport creates a [ReplayTransport] replaying the interactions saved to path with
+// [RecordingTransport.Save].
+func LoadReplayTransport(path string) (*ReplayTransport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []RecordedInteraction
+	if err := json.Unmarshal(b, &interactions); err != nil {
+		return nil, err
+	}
+	return NewReplayTransport(interactions), nil
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *ReplayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+	recorded := normalizeRequest(request, body)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next >= len(t.interactions) {
+		return nil, fmt.Errorf("%w: no interactions left for %s %s", ErrReplayMismatch, recorded.Method, recorded.URI)
+	}
+	interaction := t.interactions[t.next]
+	if interaction.Request.Method != recorded.Method || interaction.Request.URI != recorded.URI || !bytes.Equal(interaction.Request.Body, recorded.Body) {
+		return nil, fmt.Errorf("%w: expected %s %s, got %s %s", ErrReplayMismatch, interaction.Request.Method, interaction.Request.URI, recorded.Method, recorded.URI)
+	}
+	t.next++
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       request,
+	}, nil
+}
+
+// Done returns true if all recorded interactions were replayed.
+func (t *ReplayTransport) Done() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.next == len(t.interactions)
+}
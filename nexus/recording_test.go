@@ -0,0 +1,90 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runRecordedInteractions(ctx context.Context, t *testing.T, client *Client, callbackURL string) {
+	result, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation:   "foo",
+		CallbackURL: callbackURL,
+		Body:        bytes.NewReader([]byte("input")),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Successful)
+	body, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Body.Close())
+	require.Equal(t, []byte("input"), body)
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedError.Response.StatusCode)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	recorder := &RecordingTransport{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}}, ClientOptions{
+		HTTPCaller: (&http.Client{Transport: recorder}).Do,
+	})
+	defer teardown()
+	runRecordedInteractions(ctx, t, client, "http://localhost:1234/callback")
+
+	interactions := recorder.Interactions()
+	require.Len(t, interactions, 2)
+	require.Equal(t, "/foo?callback=%3Ccallback%3E", interactions[0].Request.URI)
+	require.Equal(t, maskedRequestID, interactions[0].Request.Header.Get(headerRequestID))
+	require.Equal(t, []byte("input"), interactions[0].Request.Body)
+	require.Equal(t, http.StatusOK, interactions[0].Response.StatusCode)
+	require.Equal(t, []byte("input"), interactions[0].Response.Body)
+
+	path := filepath.Join(t.TempDir(), "interactions.json")
+	require.NoError(t, recorder.Save(path))
+	replayer, err := LoadReplayTransport(path)
+	require.NoError(t, err)
+
+	// Replay against a different host with a different callback URL, without a server.
+	client, err = NewClient(ClientOptions{
+		ServiceBaseURL: "http://replay.example.com",
+		HTTPCaller:     (&http.Client{Transport: replayer}).Do,
+	})
+	require.NoError(t, err)
+	runRecordedInteractions(ctx, t, client, "http://localhost:5678/callback")
+	require.True(t, replayer.Done())
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.ErrorIs(t, err, ErrReplayMismatch)
+}
+
+func TestReplay_Mismatch(t *testing.T) {
+	replayer := NewReplayTransport([]RecordedInteraction{{
+		Request:  RecordedRequest{Method: "POST", URI: "/foo", Body: []byte("input")},
+		Response: RecordedResponse{StatusCode: http.StatusOK},
+	}})
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://replay.example.com",
+		HTTPCaller:     (&http.Client{Transport: replayer}).Do,
+	})
+	require.NoError(t, err)
+
+	_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo", Body: bytes.NewReader([]byte("other"))})
+	require.ErrorIs(t, err, ErrReplayMismatch)
+	_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "bar", Body: bytes.NewReader([]byte("input"))})
+	require.ErrorIs(t, err, ErrReplayMismatch)
+	require.False(t, replayer.Done())
+
+	result, err := client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo", Body: bytes.NewReader([]byte("input"))})
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Body.Close())
+	require.True(t, replayer.Done())
+}
@@ -0,0 +1,57 @@
+package nexus
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// recoverPanics wraps a route handler, responding to requests whose handling panics with a generic internal server
+// error instead of dropping the connection, see [HandlerOptions.DisablePanicRecovery]. The panic and its stack trace
+// are logged but never sent to the client.
+func (h *httpHandler) recoverPanics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		tracker := &headerTrackingWriter{ResponseWriter: writer}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// Deliberately aborted response, see http.ErrAbortHandler.
+				panic(recovered)
+			}
+			h.logger.Error("panic while handling request", "method", request.Method, "path", request.URL.Path, "panic", recovered, "stack", string(debug.Stack()))
+			if tracker.wroteHeader {
+				// Too late to respond with a failure, abort the response to ensure that the client does not mistake a
+				// partially written response for a complete one.
+				panic(http.ErrAbortHandler)
+			}
+			h.writeFailure(tracker, &HandlerError{
+				StatusCode: http.StatusInternalServerError,
+				Failure:    &Failure{Message: "internal server error"},
+			})
+		}()
+		handler.ServeHTTP(tracker, request)
+	})
+}
+
+// headerTrackingWriter records whether the response headers were written.
+type headerTrackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *headerTrackingWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *headerTrackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying writer for [http.ResponseController].
+func (w *headerTrackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
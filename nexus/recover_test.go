@@ -0,0 +1,86 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// panickingReader panics once its underlying reader is exhausted.
+type panickingReader struct {
+	reader io.Reader
+}
+
+func (r *panickingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		panic("result source exploded")
+	}
+	return n, err
+}
+
+type panickingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *panickingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	switch request.Operation {
+	case "abort":
+		panic(http.ErrAbortHandler)
+	case "mid-body":
+		return &OperationResponseSync{Body: &panickingReader{reader: strings.NewReader(chunkedResult)}}, nil
+	}
+	panic("secret: handler exploded")
+}
+
+func (h *panickingHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	panic("secret: handler exploded")
+}
+
+func TestRecoverPanics(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &panickingHandler{}})
+	for _, request := range []*http.Request{
+		httptest.NewRequest("POST", "/foo", nil),
+		httptest.NewRequest("GET", "/foo/bar/result", nil),
+	} {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+		require.Equal(t, http.StatusInternalServerError, writer.Code)
+		// The panic is not leaked to the client.
+		require.JSONEq(t, `{"message":"internal server error"}`, writer.Body.String())
+	}
+
+	// Deliberately aborted responses are left as is.
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/abort", nil))
+	})
+}
+
+func TestRecoverPanics_AfterHeadersWritten(t *testing.T) {
+	ctx, client, teardown := setup(t, &panickingHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "mid-body"})
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	// The response is aborted rather than being mistaken for a complete one.
+	_, err = io.ReadAll(result.Successful.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedResponseError.Response.StatusCode)
+}
+
+func TestDisablePanicRecovery(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &panickingHandler{}, DisablePanicRecovery: true})
+	require.PanicsWithValue(t, "secret: handler exploded", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/foo", nil))
+	})
+}
@@ -0,0 +1,287 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// OperationHandler handles requests for a single operation, see [ServiceRegistry].
+type OperationHandler interface {
+	// Start handles requests for starting the operation, see [Handler.StartOperation].
+	Start(context.Context, *StartOperationRequest) (OperationResponse, error)
+	// GetResult handles requests to get the result of the operation, see [Handler.GetOperationResult].
+	GetResult(context.Context, *GetOperationResultRequest) (*OperationResponseSync, error)
+	// GetInfo handles requests to get information about the operation, see [Handler.GetOperationInfo].
+	GetInfo(context.Context, *GetOperationInfoRequest) (*OperationInfo, error)
+	// Cancel handles requests to cancel the operation, see [Handler.CancelOperation].
+	Cancel(context.Context, *CancelOperationRequest) error
+	mustEmbedUnimplementedOperationHandler()
+}
+
+// UnimplementedOperationHandler must be embedded into any [OperationHandler] implementation for future compatibility.
+// It implements all methods on the [OperationHandler] interface, responding with a 501 status code if they are not
+// implemented by the embedding type, e.g. for operations that always complete synchronously.
+type UnimplementedOperationHandler struct{}
+
+func (h *UnimplementedOperationHandler) mustEmbedUnimplementedOperationHandler() {}
+
+// Start implements the OperationHandler interface.
+func (h *UnimplementedOperationHandler) Start(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, newUnimplementedError()
+}
+
+// GetResult implements the OperationHandler interface.
+func (h *UnimplementedOperationHandler) GetResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return nil, newUnimplementedError()
+}
+
+// GetInfo implements the OperationHandler interface.
+func (h *UnimplementedOperationHandler) GetInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	return nil, newUnimplementedError()
+}
+
+// Cancel implements the OperationHandler interface.
+func (h *UnimplementedOperationHandler) Cancel(ctx context.Context, request *CancelOperationRequest) error {
+	return newUnimplementedError()
+}
+
+// OperationEventStreamer may be implemented by an [OperationHandler] to stream events emitted by the operation, see
+// [Handler.StreamOperationEvents]. Requests to stream events of registered operations that don't implement it are
+// responded to with a 501 status code.
+type OperationEventStreamer interface {
+	StreamEvents(context.Context, *StreamOperationEventsRequest) (<-chan OperationEvent, error)
+}
+
+// OperationLookuper may be implemented by an [OperationHandler] to look up operations started by a start request, see
+// [Handler.LookupOperation]. Lookup requests for registered operations that don't implement it are responded to with a
+// 501 status code.
+type OperationLookuper interface {
+	Lookup(context.Context, *LookupOperationRequest) (*OperationInfo, error)
+}
+
+// OperationTypeDescriber may be implemented by an [OperationHandler] to describe the types of its input and output,
+// e.g. for tooling and documentation generation, see [ServiceRegistry.ListOperations].
+type OperationTypeDescriber interface {
+	// OperationTypes returns the names of the operation's input and output types, either may be empty.
+	OperationTypes() (inputType, outputType string)
+}
+
+// operationHandlerAdapter adapts an [OperationHandler] to the [Handler] interface, allowing it to be wrapped with
+// middleware, see [ServiceRegistry.Register].
+type operationHandlerAdapter struct {
+	UnimplementedHandler
+	handler OperationHandler
+}
+
+// StartOperation implements the Handler interface.
+func (a *operationHandlerAdapter) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return a.handler.Start(ctx, request)
+}
+
+// GetOperationResult implements the Handler interface.
+func (a *operationHandlerAdapter) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return a.handler.GetResult(ctx, request)
+}
+
+// GetOperationInfo implements the Handler interface.
+func (a *operationHandlerAdapter) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	return a.handler.GetInfo(ctx, request)
+}
+
+// CancelOperation implements the Handler interface.
+func (a *operationHandlerAdapter) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	return a.handler.Cancel(ctx, request)
+}
+
+// StreamOperationEvents implements the Handler interface.
+func (a *operationHandlerAdapter) StreamOperationEvents(ctx context.Context, request *StreamOperationEventsRequest) (<-chan OperationEvent, error) {
+	streamer, ok := a.handler.(OperationEventStreamer)
+	if !ok {
+		return nil, newUnimplementedError()
+	}
+	return streamer.StreamEvents(ctx, request)
+}
+
+// LookupOperation implements the Handler interface.
+func (a *operationHandlerAdapter) LookupOperation(ctx context.Context, request *LookupOperationRequest) (*OperationInfo, error) {
+	lookuper, ok := a.handler.(OperationLookuper)
+	if !ok {
+		return nil, newUnimplementedError()
+	}
+	return lookuper.Lookup(ctx, request)
+}
+
+// ServiceRegistry is a [Handler] that dispatches requests to [OperationHandler]s registered by operation name, keeping
+// the logic of each operation cohesive. Requests for operations that aren't registered fail with a 404 status code.
+//
+// The registry implements [OperationLister], listing the registered operations and the types of operations implementing
+// [OperationTypeDescriber]. Event streaming and lookup requests are
+// dispatched to operations implementing [OperationEventStreamer] and [OperationLookuper] respectively. Handler methods
+// that aren't specific to a single operation, e.g. CancelOperations, are not implemented.
+//
+// With [HandlerOptions.ServiceRouting] enabled, operations are dispatched by service and operation name, register them
+// with [ServiceRegistry.RegisterService].
+//
+// When [HandlerOptions.CaseInsensitiveOperations] is enabled, service and operation names are lowercased before
+// dispatching, register operations by their lowercase names.
+type ServiceRegistry struct {
+	UnimplementedHandler
+	mu         sync.RWMutex
+	operations map[operationKey]*registeredOperation
+}
+
+type registeredOperation struct {
+	// The operation's handler, adapted to the Handler interface and wrapped with its middleware.
+	handler     Handler
+	description OperationDescription
+}
+
+// operationKey identifies a registered operation, service is empty for operations registered without one.
+type operationKey struct {
+	service   string
+	operation string
+}
+
+// NewServiceRegistry constructs an empty [ServiceRegistry].
+func NewServiceRegistry() *ServiceRegistry {
+	return &ServiceRegistry{operations: make(map[operationKey]*registeredOperation)}
+}
+
+// Register registers the handler of an operation. Fails if the name is empty, reserved, see
+// [DefaultOperationNameValidator], or the operation is already registered.
+//
+// The given middleware only wraps this operation, e.g. to apply stricter authorization to admin operations, the first
+// middleware being the outermost. Requests pass through [HandlerOptions.Middleware] before being dispatched by the
+// registry to the operation's middleware.
+//
+// Operations registered without a service only handle requests to handlers without [HandlerOptions.ServiceRouting].
+func (r *ServiceRegistry) Register(operation string, handler OperationHandler, middleware ...func(Handler) Handler) error {
+	return r.RegisterService("", operation, handler, middleware...)
+}
+
+// RegisterService registers the handler of an operation of a service, see [HandlerOptions.ServiceRouting]. Operations
+// with the same name may be registered for different services. See [ServiceRegistry.Register].
+func (r *ServiceRegistry) RegisterService(service, operation string, handler OperationHandler, middleware ...func(Handler) Handler) error {
+	if operation == "" {
+		return errEmptyOperationName
+	}
+	if err := checkReservedOperationName(operation); err != nil {
+		return err
+	}
+	if handler == nil {
+		return errors.New("nil operation handler")
+	}
+	for _, m := range middleware {
+		if m == nil {
+			return errors.New("nil middleware")
+		}
+	}
+	key := operationKey{service: service, operation: operation}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.operations[key]; ok {
+		if service != "" {
+			return fmt.Errorf("operation %q of service %q already registered", operation, service)
+		}
+		return fmt.Errorf("operation %q already registered", operation)
+	}
+	description := OperationDescription{Service: service, Name: operation}
+	if describer, ok := handler.(OperationTypeDescriber); ok {
+		description.InputType, description.OutputType = describer.OperationTypes()
+	}
+	r.operations[key] = &registeredOperation{
+		handler:     applyMiddleware(&operationHandlerAdapter{handler: handler}, middleware),
+		description: description,
+	}
+	return nil
+}
+
+func (r *ServiceRegistry) operation(service, name string) (Handler, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	registered, ok := r.operations[operationKey{service: service, operation: name}]
+	if !ok {
+		message := fmt.Sprintf("operation %q not found", name)
+		if service != "" {
+			message = fmt.Sprintf("operation %q of service %q not found", name, service)
+		}
+		return nil, &HandlerError{StatusCode: http.StatusNotFound, Failure: &Failure{Message: message}}
+	}
+	return registered.handler, nil
+}
+
+// StartOperation implements the Handler interface.
+func (r *ServiceRegistry) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return nil, err
+	}
+	return handler.StartOperation(ctx, request)
+}
+
+// GetOperationResult implements the Handler interface.
+func (r *ServiceRegistry) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return nil, err
+	}
+	return handler.GetOperationResult(ctx, request)
+}
+
+// GetOperationInfo implements the Handler interface.
+func (r *ServiceRegistry) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return nil, err
+	}
+	return handler.GetOperationInfo(ctx, request)
+}
+
+// CancelOperation implements the Handler interface.
+func (r *ServiceRegistry) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return err
+	}
+	return handler.CancelOperation(ctx, request)
+}
+
+// StreamOperationEvents implements the Handler interface.
+func (r *ServiceRegistry) StreamOperationEvents(ctx context.Context, request *StreamOperationEventsRequest) (<-chan OperationEvent, error) {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return nil, err
+	}
+	return handler.StreamOperationEvents(ctx, request)
+}
+
+// LookupOperation implements the Handler interface.
+func (r *ServiceRegistry) LookupOperation(ctx context.Context, request *LookupOperationRequest) (*OperationInfo, error) {
+	handler, err := r.operation(request.Service, request.Operation)
+	if err != nil {
+		return nil, err
+	}
+	return handler.LookupOperation(ctx, request)
+}
+
+// ListOperations implements the [OperationLister] interface, listing the registered operations sorted by service and
+// name.
+func (r *ServiceRegistry) ListOperations() []OperationDescription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	operations := make([]OperationDescription, 0, len(r.operations))
+	for _, registered := range r.operations {
+		operations = append(operations, registered.description)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Service != operations[j].Service {
+			return operations[i].Service < operations[j].Service
+		}
+		return operations[i].Name < operations[j].Name
+	})
+	return operations
+}
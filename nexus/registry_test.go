@@ -0,0 +1,221 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type echoOperation struct {
+	UnimplementedOperationHandler
+}
+
+func (o *echoOperation) Start(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input string
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(input)
+}
+
+type chargeOperation struct {
+	UnimplementedOperationHandler
+	canceled []string
+}
+
+func (o *chargeOperation) Start(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return &OperationResponseAsync{OperationID: "charge-1"}, nil
+}
+
+func (o *chargeOperation) GetResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return NewOperationResponseSync("receipt for " + request.OperationID)
+}
+
+func (o *chargeOperation) GetInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func (o *chargeOperation) Cancel(ctx context.Context, request *CancelOperationRequest) error {
+	o.canceled = append(o.canceled, request.OperationID)
+	return nil
+}
+
+func (o *chargeOperation) OperationTypes() (inputType, outputType string) {
+	return "ChargeInput", "ChargeOutput"
+}
+
+func (o *chargeOperation) StreamEvents(ctx context.Context, request *StreamOperationEventsRequest) (<-chan OperationEvent, error) {
+	events := make(chan OperationEvent, 1)
+	events <- OperationEvent{Type: "charged", Data: []byte(`"` + request.OperationID + `"`)}
+	close(events)
+	return events, nil
+}
+
+func (o *chargeOperation) Lookup(ctx context.Context, request *LookupOperationRequest) (*OperationInfo, error) {
+	return &OperationInfo{ID: "charge-1", State: OperationStateRunning}, nil
+}
+
+func TestServiceRegistry(t *testing.T) {
+	registry := NewServiceRegistry()
+	charge := &chargeOperation{}
+	require.NoError(t, registry.Register("echo", &echoOperation{}))
+	require.NoError(t, registry.Register("charge", charge))
+	require.EqualError(t, registry.Register("echo", &echoOperation{}), `operation "echo" already registered`)
+	require.ErrorIs(t, registry.Register("", &echoOperation{}), errEmptyOperationName)
+	require.EqualError(t, registry.Register("nil", nil), "nil operation handler")
+	require.EqualError(t, registry.Register("_cancel", &echoOperation{}), `operation name "_cancel" is reserved`)
+
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: registry, ExposeOperations: true}, ClientOptions{})
+	defer teardown()
+
+	options, err := NewStartOperationOptions("echo", "hello")
+	require.NoError(t, err)
+	result, err := client.StartOperation(ctx, options)
+	require.NoError(t, err)
+	defer result.Successful.Body.Close()
+	b, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, `"hello"`, string(b))
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "charge"})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.Equal(t, "charge-1", handle.ID)
+	info, err := handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, OperationStateRunning, info.State)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	b, err = io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, `"receipt for charge-1"`, string(b))
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+	require.Equal(t, []string{"charge-1"}, charge.canceled)
+	events, err := handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.NoError(t, err)
+	event := <-events
+	require.Equal(t, "charged", event.Type)
+	require.Equal(t, `"charge-1"`, string(event.Data))
+	handle, err = client.LookupOperation(ctx, LookupOperationOptions{Operation: "charge", RequestID: "abc"})
+	require.NoError(t, err)
+	require.Equal(t, "charge-1", handle.ID)
+
+	// Methods not implemented by a registered operation.
+	handle, err = client.NewHandle("echo", "abc")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+	_, err = handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedResponseError.Response.StatusCode)
+
+	// Operations that aren't registered.
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "refund"})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, `operation "refund" not found`, unexpectedResponseError.Failure.Message)
+	handle, err = client.NewHandle("refund", "abc")
+	require.NoError(t, err)
+	_, err = handle.StreamEvents(ctx, StreamOperationEventsOptions{})
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+	_, err = client.LookupOperation(ctx, LookupOperationOptions{Operation: "refund", RequestID: "abc"})
+	require.ErrorIs(t, err, ErrOperationNotFound)
+
+	// Operations implementing OperationTypeDescriber are listed with their types.
+	expected := []OperationDescription{{Name: "charge", InputType: "ChargeInput", OutputType: "ChargeOutput"}, {Name: "echo"}}
+	require.Equal(t, expected, registry.ListOperations())
+	writer := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: registry, ExposeOperations: true}).ServeHTTP(writer, httptest.NewRequest("GET", "/_operations", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	var listed []OperationDescription
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &listed))
+	require.Equal(t, expected, listed)
+}
+
+func TestServiceRegistry_Middleware(t *testing.T) {
+	log := &callLog{}
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.Register("echo", &echoOperation{}))
+	require.NoError(t, registry.Register("charge", &chargeOperation{}, newLoggingMiddleware("outer", log), newLoggingMiddleware("inner", log)))
+	require.EqualError(t, registry.Register("nil", &echoOperation{}, nil), "nil middleware")
+
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:    registry,
+		Middleware: []func(Handler) Handler{newLoggingMiddleware("global", log)},
+	}, ClientOptions{})
+	defer teardown()
+
+	// Operation middleware only wraps its operation, inside the global middleware.
+	options, err := NewStartOperationOptions("echo", "hello")
+	require.NoError(t, err)
+	result, err := client.StartOperation(ctx, options)
+	require.NoError(t, err)
+	require.NoError(t, result.Successful.Body.Close())
+	require.Equal(t, []string{"global:echo"}, log.take())
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "charge"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"global:charge", "outer:charge", "inner:charge"}, log.take())
+	_, err = result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"global:charge/charge-1", "outer:charge/charge-1", "inner:charge/charge-1"}, log.take())
+}
+
+type constantOperation struct {
+	UnimplementedOperationHandler
+	result string
+}
+
+func (o *constantOperation) Start(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(o.result)
+}
+
+func TestServiceRegistry_Services(t *testing.T) {
+	registry := NewServiceRegistry()
+	require.NoError(t, registry.RegisterService("billing", "charge", &constantOperation{result: "billed"}))
+	require.NoError(t, registry.RegisterService("shipping", "charge", &constantOperation{result: "shipped"}))
+	require.NoError(t, registry.Register("charge", &constantOperation{result: "unrouted"}))
+	require.EqualError(t, registry.RegisterService("billing", "charge", &constantOperation{}), `operation "charge" of service "billing" already registered`)
+
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: registry, ServiceRouting: true}, ClientOptions{})
+	defer teardown()
+
+	start := func(service string) (string, error) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Service: service, Operation: "charge"})
+		if err != nil {
+			return "", err
+		}
+		defer result.Successful.Body.Close()
+		b, err := io.ReadAll(result.Successful.Body)
+		require.NoError(t, err)
+		return string(b), nil
+	}
+	result, err := start("billing")
+	require.NoError(t, err)
+	require.Equal(t, `"billed"`, result)
+	result, err = start("shipping")
+	require.NoError(t, err)
+	require.Equal(t, `"shipped"`, result)
+
+	// Operations of other services, or registered without a service, aren't dispatched to.
+	_, err = start("returns")
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusNotFound, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, `operation "charge" of service "returns" not found`, unexpectedResponseError.Failure.Message)
+
+	require.Equal(t, []OperationDescription{
+		{Name: "charge"},
+		{Service: "billing", Name: "charge"},
+		{Service: "shipping", Name: "charge"},
+	}, registry.ListOperations())
+}
@@ -0,0 +1,45 @@
+package nexus
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+func newRequestBodyTooLargeError(limit int64) *HandlerError {
+	return newBadRequestError("request body exceeds max size of %d bytes", limit)
+}
+
+// Max size of bulk cancelation request bodies in bytes when [HandlerOptions.MaxRequestBodyBytes] is unset. Bodies only
+// carry a [CancelFilter].
+const maxCancelFilterBytes = 64 << 10
+
+// limitRequestBody enforces a max size of limit bytes on a request body, e.g. [HandlerOptions.MaxRequestBodyBytes] on a
+// start request. Requests declaring a larger Content-Length are rejected upfront, other bodies fail with a bad request
+// [HandlerError] once reading exceeds the limit. Not positive limits are ignored.
+func (h *httpHandler) limitRequestBody(writer http.ResponseWriter, request *http.Request, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	if request.ContentLength > limit {
+		request.Body.Close()
+		return newRequestBodyTooLargeError(limit)
+	}
+	request.Body = &limitedRequestBody{ReadCloser: http.MaxBytesReader(writer, request.Body, limit), limit: limit}
+	return nil
+}
+
+// limitedRequestBody maps errors from reading past the limit of an [http.MaxBytesReader] to bad request errors.
+type limitedRequestBody struct {
+	io.ReadCloser
+	limit int64
+}
+
+func (b *limitedRequestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		err = newRequestBodyTooLargeError(b.limit)
+	}
+	return n, err
+}
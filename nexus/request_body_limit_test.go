@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRequestBodyBytes(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &operationEchoHandler{}, MaxRequestBodyBytes: 10})
+	cases := []struct {
+		name           string
+		body           string
+		unknownLength  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "at limit", body: `"12345678"`, expectedStatus: http.StatusOK, expectedBody: `"foo:\"12345678\""`},
+		{name: "at limit with unknown length", body: `"12345678"`, unknownLength: true, expectedStatus: http.StatusOK, expectedBody: `"foo:\"12345678\""`},
+		{name: "over limit", body: `"123456789"`, expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"request body exceeds max size of 10 bytes"}`},
+		{name: "over limit with unknown length", body: `"123456789"`, unknownLength: true, expectedStatus: http.StatusBadRequest, expectedBody: `{"message":"request body exceeds max size of 10 bytes"}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/foo", strings.NewReader(c.body))
+			request.Header.Set(headerContentType, contentTypeJSON)
+			if c.unknownLength {
+				request.ContentLength = -1
+			}
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, request)
+			require.Equal(t, c.expectedStatus, writer.Code)
+			require.Equal(t, c.expectedBody, writer.Body.String())
+		})
+	}
+}
+
+func TestMaxRequestBodyBytes_InputMiddleware(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:             &operationEchoHandler{},
+		MaxRequestBodyBytes: 10,
+		InputMiddleware: func(ctx context.Context, operation string, body io.Reader) (io.Reader, error) {
+			return body, nil
+		},
+	}, ClientOptions{})
+	defer teardown()
+
+	// MultiReader hides the length of the body, it is sent chunked.
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Body: io.MultiReader(strings.NewReader(`"123456789"`))})
+	var unexpectedResponseError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedResponseError)
+	require.Equal(t, http.StatusBadRequest, unexpectedResponseError.Response.StatusCode)
+	require.Equal(t, "request body exceeds max size of 10 bytes", unexpectedResponseError.Failure.Message)
+}
+
+func TestMaxRequestBodyBytes_Invalid(t *testing.T) {
+	require.PanicsWithError(t, "nexus: HandlerOptions.MaxRequestBodyBytes must not be negative, got: -1", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, MaxRequestBodyBytes: -1})
+	})
+}
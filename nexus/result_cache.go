@@ -0,0 +1,149 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A ResultCache stores results of operations that complete synchronously, see [ClientOptions.ResultCache].
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns the result stored under key, if any.
+	Get(ctx context.Context, key string) (*CachedResult, bool)
+	// Set stores a result under key, replacing any existing result.
+	Set(ctx context.Context, key string, result *CachedResult)
+}
+
+// CachedResult is a successful operation result stored in a [ResultCache].
+type CachedResult struct {
+	// Header of the response the result was delivered in.
+	Header http.Header
+	// The result body.
+	Body []byte
+	// Entity tag of the result, from the response's ETag header, used to revalidate the result once it expires.
+	ETag string
+	// Time the result expires at, derived from the response's Cache-Control header. Results stored with a validator
+	// but without explicit freshness expire immediately, i.e. are revalidated before use.
+	Expires time.Time
+}
+
+func (r *CachedResult) fresh(now time.Time) bool {
+	return now.Before(r.Expires)
+}
+
+// response constructs a response delivering the cached result.
+func (r *CachedResult) response() *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+	}
+}
+
+// resultCacheKey derives the cache key of an operation result from the operation, the request header, and the
+// serialized input. All header fields but the request ID are included, results negotiated for a different Accept
+// header, or requested on behalf of a different caller, e.g. with a different Authorization header, aren't shared.
+func resultCacheKey(service, operation string, header http.Header, input []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(service))
+	hash.Write([]byte{0})
+	hash.Write([]byte(operation))
+	hash.Write([]byte{0})
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if http.CanonicalHeaderKey(name) != headerRequestID {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hash.Write([]byte(http.CanonicalHeaderKey(name)))
+		for _, value := range header[name] {
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+		}
+		hash.Write([]byte{0, 0})
+	}
+	hash.Write([]byte{0})
+	hash.Write(input)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// resultExpiry interprets the Cache-Control and ETag headers of a response, returning the expiry time of its result and
+// whether the result may be stored at all. Only results with explicit freshness, i.e. max-age, or a validator, i.e.
+// no-cache or an ETag, are stored. Results without either would never be revalidated.
+func resultExpiry(header http.Header, now time.Time) (expires time.Time, ok bool) {
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			switch {
+			case directive == "no-store":
+				return time.Time{}, false
+			case directive == "no-cache":
+				// May be stored but must be revalidated before use.
+				expires, ok = now, true
+			case strings.HasPrefix(directive, "max-age="):
+				seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+				if err != nil || seconds < 0 {
+					continue
+				}
+				if maxAge := now.Add(time.Duration(seconds) * time.Second); !ok || maxAge.Before(expires) {
+					expires, ok = maxAge, true
+				}
+			}
+		}
+	}
+	if !ok && header.Get("ETag") != "" {
+		// Revalidated before use.
+		return now, true
+	}
+	return expires, ok
+}
+
+// cacheResult stores a successful start operation response in the result cache, if cacheable, reading the response
+// body into memory.
+func (c *Client) cacheResult(ctx context.Context, key string, response *http.Response) error {
+	if response.Header.Get(headerResultPartial) == "true" {
+		return nil
+	}
+	expires, ok := resultExpiry(response.Header, time.Now())
+	if !ok {
+		return nil
+	}
+	body, err := readAndReplaceBody(response)
+	if err != nil {
+		return err
+	}
+	c.options.ResultCache.Set(ctx, key, &CachedResult{
+		Header:  response.Header.Clone(),
+		Body:    body,
+		ETag:    response.Header.Get("ETag"),
+		Expires: expires,
+	})
+	return nil
+}
+
+// revalidateCachedResult refreshes the expiry of a cached result per a 304 Not Modified response and stores it back
+// in the result cache.
+func (c *Client) revalidateCachedResult(ctx context.Context, key string, cached *CachedResult, response *http.Response) {
+	expires, ok := resultExpiry(response.Header, time.Now())
+	if !ok {
+		return
+	}
+	refreshed := *cached
+	refreshed.Expires = expires
+	c.options.ResultCache.Set(ctx, key, &refreshed)
+}
@@ -0,0 +1,225 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapResultCache struct {
+	mu      sync.Mutex
+	results map[string]*CachedResult
+}
+
+func (c *mapResultCache) Get(ctx context.Context, key string) (*CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[key]
+	return result, ok
+}
+
+func (c *mapResultCache) Set(ctx context.Context, key string, result *CachedResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[key] = result
+}
+
+// cachingInstance responds to start requests with results carrying cache directives depending on the operation.
+type cachingInstance struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *cachingInstance) call(request *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	operation := strings.TrimPrefix(request.URL.Path, "/")
+	input, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.requests = append(f.requests, operation+" "+request.Header.Get("If-None-Match"))
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{headerContentType: []string{contentTypeJSON}},
+		Body:       io.NopCloser(strings.NewReader(`"` + operation + ":" + string(input) + `"`)),
+		Request:    request,
+	}
+	switch operation {
+	case "stable":
+		response.Header.Set("Cache-Control", "max-age=60")
+	case "no-store":
+		response.Header.Set("Cache-Control", "no-store")
+	case "short":
+		response.Header.Set("Cache-Control", "max-age=0")
+	case "revalidate":
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			response.StatusCode = http.StatusNotModified
+			response.Body = http.NoBody
+			response.Header = http.Header{"Cache-Control": []string{"max-age=60"}}
+			break
+		}
+		response.Header.Set("Cache-Control", "no-cache")
+		response.Header.Set("ETag", `"v1"`)
+	case "partial":
+		response.Header.Set(headerResultPartial, "true")
+	case "async":
+		response.StatusCode = http.StatusCreated
+		response.Body = io.NopCloser(strings.NewReader(`{"id":"a/sync","state":"running"}`))
+	}
+	return response, nil
+}
+
+func (f *cachingInstance) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func TestResultCache(t *testing.T) {
+	instance := &cachingInstance{}
+	cache := &mapResultCache{results: map[string]*CachedResult{}}
+	client, err := NewClient(ClientOptions{ServiceBaseURL: "http://localhost", HTTPCaller: instance.call, ResultCache: cache})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := func(operation, input string) string {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation, Body: strings.NewReader(input)})
+		require.NoError(t, err)
+		if result.Successful == nil {
+			return ""
+		}
+		defer result.Successful.Body.Close()
+		b, err := io.ReadAll(result.Successful.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	// Fresh results are served from the cache, keyed by input.
+	require.Equal(t, `"stable:a"`, start("stable", "a"))
+	require.Equal(t, `"stable:a"`, start("stable", "a"))
+	require.Equal(t, `"stable:b"`, start("stable", "b"))
+	require.Equal(t, []string{"stable ", "stable "}, instance.takeRequests())
+
+	// Results without cache directives, results that must not be stored, expired results without an ETag, partial
+	// and async results aren't served from the cache.
+	for _, operation := range []string{"plain", "no-store", "short", "partial", "async"} {
+		start(operation, "a")
+		start(operation, "a")
+		require.Equal(t, []string{operation + " ", operation + " "}, instance.takeRequests())
+	}
+
+	// Results with an ETag are revalidated.
+	require.Equal(t, `"revalidate:a"`, start("revalidate", "a"))
+	require.Equal(t, `"revalidate:a"`, start("revalidate", "a"))
+	require.Equal(t, []string{"revalidate ", `revalidate "v1"`}, instance.takeRequests())
+	// The 304 response refreshed the expiry.
+	require.Equal(t, `"revalidate:a"`, start("revalidate", "a"))
+	require.Empty(t, instance.takeRequests())
+}
+
+func TestResultCache_KeyedByHeader(t *testing.T) {
+	instance := &cachingInstance{}
+	cache := &mapResultCache{results: map[string]*CachedResult{}}
+	client, err := NewClient(ClientOptions{ServiceBaseURL: "http://localhost", HTTPCaller: instance.call, ResultCache: cache})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := func(header http.Header) {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "stable", Body: strings.NewReader("a"), Header: header})
+		require.NoError(t, err)
+		require.NoError(t, result.Successful.Body.Close())
+	}
+
+	start(http.Header{"Authorization": []string{"alice"}, "Accept": []string{contentTypeJSON}})
+	start(http.Header{"Authorization": []string{"alice"}, "Accept": []string{contentTypeJSON}})
+	require.Len(t, instance.takeRequests(), 1)
+
+	// Results aren't shared across callers or negotiated content types.
+	start(http.Header{"Authorization": []string{"bob"}, "Accept": []string{contentTypeJSON}})
+	start(http.Header{"Authorization": []string{"alice"}, "Accept": []string{"application/x-protobuf"}})
+	start(http.Header{"Authorization": []string{"alice"}})
+	require.Len(t, instance.takeRequests(), 3)
+
+	// The request ID is not part of the key.
+	start(http.Header{"Authorization": []string{"alice"}, "Accept": []string{contentTypeJSON}, headerRequestID: []string{"abc"}})
+	require.Empty(t, instance.takeRequests())
+}
+
+type cacheableEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *cacheableEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	var input string
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	response, err := NewOperationResponseSync(input)
+	if err != nil {
+		return nil, err
+	}
+	if request.Operation == "cacheable" {
+		response.Header.Set("Cache-Control", "max-age=60")
+	}
+	return response, nil
+}
+
+func TestResultCache_Handler(t *testing.T) {
+	cache := &mapResultCache{results: map[string]*CachedResult{}}
+	handler := &cacheableEchoHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{ResultCache: cache})
+	defer teardown()
+
+	for i := 0; i < 2; i++ {
+		for _, operation := range []string{"cacheable", "plain"} {
+			response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: operation, Body: strings.NewReader(`"abc"`)})
+			require.NoError(t, err)
+			b, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			require.NoError(t, response.Body.Close())
+			require.Equal(t, `"abc"`, string(b))
+			require.Equal(t, contentTypeJSON, response.Header.Get(headerContentType))
+		}
+	}
+	// Results without cache directives aren't stored.
+	require.Len(t, cache.results, 1)
+}
+
+func TestResultExpiry(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		cacheControl string
+		etag         string
+		expires      time.Time
+		ok           bool
+	}{
+		{cacheControl: "", ok: false},
+		{cacheControl: "", etag: `"v1"`, expires: now, ok: true},
+		{cacheControl: "public", ok: false},
+		{cacheControl: "max-age=10", expires: now.Add(time.Second * 10), ok: true},
+		{cacheControl: "max-age=10", etag: `"v1"`, expires: now.Add(time.Second * 10), ok: true},
+		{cacheControl: "public, MAX-AGE=10", expires: now.Add(time.Second * 10), ok: true},
+		{cacheControl: "no-cache, max-age=10", expires: now, ok: true},
+		{cacheControl: "max-age=0", expires: now, ok: true},
+		{cacheControl: "max-age=invalid", ok: false},
+		{cacheControl: "max-age=10, no-store", etag: `"v1"`, ok: false},
+	}
+	for _, c := range cases {
+		header := http.Header{"Cache-Control": []string{c.cacheControl}}
+		if c.etag != "" {
+			header.Set("ETag", c.etag)
+		}
+		expires, ok := resultExpiry(header, now)
+		require.Equal(t, c.ok, ok, c.cacheControl)
+		require.Equal(t, c.expires, expires, c.cacheControl)
+	}
+}
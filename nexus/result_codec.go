@@ -0,0 +1,97 @@
+package nexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// A ResultCodec serializes operation results as a specific content type, e.g. protobuf messages or PNG images, see
+// [HandlerOptions.OperationCodecs].
+type ResultCodec interface {
+	// ContentType returns the media type set in the Content-Type header of results serialized by this codec, e.g.
+	// application/json.
+	ContentType() string
+	// Marshal serializes a result.
+	Marshal(any) ([]byte, error)
+}
+
+// JSONResultCodec serializes results as JSON using [json.Marshal], the encoding used by [NewOperationResponseSync].
+type JSONResultCodec struct{}
+
+// ContentType implements the ResultCodec interface.
+func (JSONResultCodec) ContentType() string {
+	return contentTypeJSON
+}
+
+// Marshal implements the ResultCodec interface.
+func (JSONResultCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// negotiateResultCodec picks the codec to serialize the result of a start or get result request with per the request's
+// Accept header. Returns a nil codec for operations without registered codecs.
+func (h *httpHandler) negotiateResultCodec(request *http.Request, operation string) (ResultCodec, error) {
+	codecs := h.operationCodecs[operation]
+	if len(codecs) == 0 {
+		return nil, nil
+	}
+	available := make([]string, len(codecs))
+	for i, codec := range codecs {
+		available[i] = codec.ContentType()
+	}
+	contentType, err := NegotiateContentType(request, available)
+	if err != nil {
+		return nil, err
+	}
+	for _, codec := range codecs {
+		if codec.ContentType() == contentType {
+			return codec, nil
+		}
+	}
+	// Unreachable, the negotiated type is one of the available types.
+	return nil, fmt.Errorf("no codec for negotiated content type: %q", contentType)
+}
+
+// encodeResult serializes a result constructed with one of the NewOperationResponseSync helpers with the given codec,
+// replacing the response's JSON body. Results constructed directly from a body are left as is.
+func encodeResult(response *OperationResponseSync, codec ResultCodec) error {
+	if _, ok := codec.(JSONResultCodec); ok || response.source == nil {
+		return nil
+	}
+	b, err := codec.Marshal(response.source.value)
+	if err != nil {
+		err = fmt.Errorf("failed to marshal result as %s: %w", codec.ContentType(), err)
+		response.abandon(err)
+		return err
+	}
+	// Release the original body, this also stops the encoding goroutine of stream responses if started.
+	if closer, ok := response.Body.(io.Closer); ok {
+		closer.Close()
+	}
+	if response.Header == nil {
+		response.Header = make(http.Header)
+	}
+	response.Header.Set(headerContentType, codec.ContentType())
+	response.Body = bytes.NewReader(b)
+	response.ContentLength = int64(len(b))
+	return nil
+}
+
+// normalizeOperationCodecs keys [HandlerOptions.OperationCodecs] by normalized operation name.
+func normalizeOperationCodecs(options HandlerOptions) map[string][]ResultCodec {
+	if len(options.OperationCodecs) == 0 {
+		return nil
+	}
+	codecs := make(map[string][]ResultCodec, len(options.OperationCodecs))
+	for operation, operationCodecs := range options.OperationCodecs {
+		if options.CaseInsensitiveOperations {
+			operation = strings.ToLower(operation)
+		}
+		codecs[operation] = operationCodecs
+	}
+	return codecs
+}
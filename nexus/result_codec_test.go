@@ -0,0 +1,166 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefixCodec is a fake codec serializing results as the content type followed by the formatted value.
+type prefixCodec string
+
+func (c prefixCodec) ContentType() string {
+	return string(c)
+}
+
+func (c prefixCodec) Marshal(v any) ([]byte, error) {
+	if v == "fail" {
+		return nil, fmt.Errorf("cannot marshal %v", v)
+	}
+	return []byte(fmt.Sprintf("%s:%v", c, v)), nil
+}
+
+type codecHandler struct {
+	UnimplementedHandler
+	mu           sync.Mutex
+	contentTypes []string
+}
+
+func (h *codecHandler) record(contentType string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.contentTypes = append(h.contentTypes, contentType)
+}
+
+func (h *codecHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	h.record(request.ResponseContentType)
+	var input string
+	if err := request.ReadJSON(&input); err != nil {
+		return nil, err
+	}
+	return NewOperationResponseSync(input)
+}
+
+func (h *codecHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	h.record(request.ResponseContentType)
+	return NewOperationResponseSync(request.OperationID)
+}
+
+func TestOperationCodecs(t *testing.T) {
+	handler := &codecHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler: handler,
+		OperationCodecs: map[string][]ResultCodec{
+			"export": {prefixCodec("image/png")},
+			"data":   {JSONResultCodec{}, prefixCodec("application/x-protobuf")},
+		},
+	}, ClientOptions{})
+	defer teardown()
+
+	start := func(operation, accept string) (*http.Response, error) {
+		header := http.Header{}
+		if accept != "" {
+			header.Set("Accept", accept)
+		}
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation, Body: strings.NewReader(`"abc"`), Header: header})
+		if err != nil {
+			return nil, err
+		}
+		return result.Successful, nil
+	}
+	readBody := func(response *http.Response) string {
+		defer response.Body.Close()
+		b, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	cases := []struct {
+		operation   string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{operation: "export", accept: "", contentType: "image/png", body: "image/png:abc"},
+		{operation: "export", accept: "image/*", contentType: "image/png", body: "image/png:abc"},
+		{operation: "data", accept: "", contentType: contentTypeJSON, body: `"abc"`},
+		{operation: "data", accept: "application/x-protobuf", contentType: "application/x-protobuf", body: "application/x-protobuf:abc"},
+		{operation: "data", accept: "application/json;q=0.5, application/x-protobuf;q=0.9", contentType: "application/x-protobuf", body: "application/x-protobuf:abc"},
+		// Operations without codecs ignore the Accept header.
+		{operation: "other", accept: "image/png", contentType: contentTypeJSON, body: `"abc"`},
+	}
+	for _, c := range cases {
+		t.Run(c.operation+" "+c.accept, func(t *testing.T) {
+			handler.contentTypes = nil
+			response, err := start(c.operation, c.accept)
+			require.NoError(t, err)
+			require.Equal(t, c.contentType, response.Header.Get(headerContentType))
+			require.Equal(t, c.body, readBody(response))
+			if c.operation == "other" {
+				require.Equal(t, []string{""}, handler.contentTypes)
+			} else {
+				require.Equal(t, []string{c.contentType}, handler.contentTypes)
+			}
+		})
+	}
+
+	// Get result requests are negotiated too.
+	handle, err := client.NewHandle("data", "xyz")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{Header: http.Header{"Accept": []string{"application/x-protobuf"}}})
+	require.NoError(t, err)
+	require.Equal(t, "application/x-protobuf", response.Header.Get(headerContentType))
+	require.Equal(t, "application/x-protobuf:xyz", readBody(response))
+
+	// Unacceptable requests fail before invoking the handler.
+	handler.contentTypes = nil
+	_, err = start("export", contentTypeJSON)
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotAcceptable, unexpectedError.Response.StatusCode)
+	require.Contains(t, unexpectedError.Failure.Message, "image/png")
+	handle, err = client.NewHandle("export", "xyz")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{Header: http.Header{"Accept": []string{"application/x-protobuf"}}})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotAcceptable, unexpectedError.Response.StatusCode)
+	require.Empty(t, handler.contentTypes)
+
+	// Marshaling failures are reported as internal errors.
+	handle, err = client.NewHandle("export", "fail")
+	require.NoError(t, err)
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusInternalServerError, unexpectedError.Response.StatusCode)
+}
+
+func TestOperationCodecs_CaseInsensitive(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:                   &codecHandler{},
+		CaseInsensitiveOperations: true,
+		OperationCodecs:           map[string][]ResultCodec{"Export": {prefixCodec("image/png")}},
+	}, ClientOptions{})
+	defer teardown()
+
+	handle, err := client.NewHandle("EXPORT", "xyz")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, "image/png", response.Header.Get(headerContentType))
+}
+
+func TestOperationCodecs_InvalidOptions(t *testing.T) {
+	require.Panics(t, func() {
+		NewHTTPHandler(HandlerOptions{Handler: &codecHandler{}, OperationCodecs: map[string][]ResultCodec{"export": {}}})
+	})
+	require.Panics(t, func() {
+		NewHTTPHandler(HandlerOptions{Handler: &codecHandler{}, OperationCodecs: map[string][]ResultCodec{"export": {nil}}})
+	})
+}
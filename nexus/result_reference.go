@@ -0,0 +1,99 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// Content type of result reference bodies.
+const contentTypeResultReference = "application/vnd.nexus.result-reference+json"
+
+// ResultReference refers to an operation result stored elsewhere, e.g. in object storage, allowing handlers to offload
+// very large results. Unlike an HTTP redirect, a reference is a structured value the caller handles explicitly: it may
+// resolve it with [OperationHandle.ResolveReference] or pass it along.
+//
+// A reference is delivered as a result with the application/vnd.nexus.result-reference+json content type, construct one
+// with [NewOperationResponseSyncReference] and read it with [ReadResultReference].
+type ResultReference struct {
+	// URL of the result, e.g. a signed URL. Required.
+	URL string `json:"url"`
+	// Content type of the referenced result. Optional.
+	ContentType string `json:"contentType,omitempty"`
+	// Size of the referenced result in bytes. Optional.
+	Size int64 `json:"size,omitempty"`
+	// Time after which the URL is no longer valid. Optional.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Additional metadata, e.g. a checksum. Optional.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// NewOperationResponseSyncReference constructs an [OperationResponseSync] delivering a reference to a result instead
+// of the result itself.
+func NewOperationResponseSyncReference(reference ResultReference) (*OperationResponseSync, error) {
+	if reference.URL == "" {
+		return nil, errors.New("result reference URL is required")
+	}
+	response, err := NewOperationResponseSync(reference)
+	if err != nil {
+		return nil, err
+	}
+	response.Header.Set(headerContentType, contentTypeResultReference)
+	return response, nil
+}
+
+// IsResultReference returns true if a result response, e.g. from [OperationHandle.GetResult], carries a
+// [ResultReference] rather than the result itself.
+func IsResultReference(response *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get(headerContentType))
+	return err == nil && mediaType == contentTypeResultReference
+}
+
+// ReadResultReference reads a [ResultReference] from a result response, see [IsResultReference]. The response body is
+// read in its entirety and closed.
+func ReadResultReference(response *http.Response) (*ResultReference, error) {
+	defer response.Body.Close()
+	if !IsResultReference(response) {
+		return nil, fmt.Errorf("response is not a result reference, content type: %q", response.Header.Get(headerContentType))
+	}
+	var reference ResultReference
+	if err := json.NewDecoder(response.Body).Decode(&reference); err != nil {
+		return nil, err
+	}
+	return &reference, nil
+}
+
+// ResolveReference fetches the result a [ResultReference] refers to with the client's HTTP caller. No Nexus specific
+// headers are sent.
+//
+// Fails with an [UnexpectedResponseError] if the response status isn't 200.
+//
+// ⚠️ If a response is returned, its body must be read in its entirety and closed to free up the underlying connection.
+func (h *OperationHandle) ResolveReference(ctx context.Context, reference *ResultReference) (*http.Response, error) {
+	response, err := h.resolveReference(ctx, reference)
+	return response, h.client.mapError(err)
+}
+
+func (h *OperationHandle) resolveReference(ctx context.Context, reference *ResultReference) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", reference.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(headerUserAgent, userAgent)
+	response, err := h.client.options.HTTPCaller(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		body, err := readAndReplaceBody(response)
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.client.newUnexpectedResponseError(fmt.Sprintf("unexpected response status: %q", response.Status), response, body)
+	}
+	return response, nil
+}
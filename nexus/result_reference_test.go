@@ -0,0 +1,87 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type referencingHandler struct {
+	UnimplementedHandler
+	storageURL string
+}
+
+func (h *referencingHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	return NewOperationResponseSyncReference(ResultReference{
+		URL:         h.storageURL + "/" + request.OperationID,
+		ContentType: "text/plain",
+		Size:        6,
+		ExpiresAt:   &expiresAt,
+		Metadata:    map[string]string{"checksum": "abc"},
+	})
+}
+
+func TestResultReference(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/exists" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.Header().Set(headerContentType, "text/plain")
+		_, _ = writer.Write([]byte("result"))
+	}))
+	defer storage.Close()
+
+	ctx, client, teardown := setup(t, &referencingHandler{storageURL: storage.URL})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "exists")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.True(t, IsResultReference(response))
+	reference, err := ReadResultReference(response)
+	require.NoError(t, err)
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, &ResultReference{
+		URL:         storage.URL + "/exists",
+		ContentType: "text/plain",
+		Size:        6,
+		ExpiresAt:   &expiresAt,
+		Metadata:    map[string]string{"checksum": "abc"},
+	}, reference)
+
+	response, err = handle.ResolveReference(ctx, reference)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte("result"), body)
+
+	_, err = handle.ResolveReference(ctx, &ResultReference{URL: storage.URL + "/missing"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotFound, unexpectedError.Response.StatusCode)
+}
+
+func TestResultReference_NotAReference(t *testing.T) {
+	ctx, client, teardown := setup(t, &partialResultHandler{})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{})
+	require.NoError(t, err)
+	require.False(t, IsResultReference(response))
+	_, err = ReadResultReference(response)
+	require.ErrorContains(t, err, "not a result reference")
+
+	_, err = NewOperationResponseSyncReference(ResultReference{})
+	require.Error(t, err)
+}
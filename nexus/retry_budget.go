@@ -0,0 +1,96 @@
+package nexus
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is returned when a request is not retried because the client's retry budget, configured via
+// [ClientOptions.RetryBudget], is exhausted.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudgetOptions configures a client wide retry budget, see [ClientOptions.RetryBudget].
+//
+// The budget is a token bucket. Every successful request deposits Ratio tokens, every retry withdraws a token, and
+// retries are skipped while the bucket is empty. The bucket is also refilled at MinPerSecond tokens per second, up to
+// MinPerSecond tokens, allowing retries when request volume is low. At most max(100, MinPerSecond) tokens are banked.
+type RetryBudgetOptions struct {
+	// Number of retries allowed per successful request, e.g. 0.1 allows one retry for every ten successful requests.
+	// Defaults to 0.1.
+	Ratio float64
+	// Number of retries allowed per second regardless of Ratio.
+	// Defaults to 10.
+	MinPerSecond float64
+}
+
+// Max number of tokens banked by a retry budget, unless MinPerSecond is larger.
+const retryBudgetCapacity = 100
+
+type retryBudget struct {
+	options  RetryBudgetOptions
+	capacity float64
+	mu       sync.Mutex
+	tokens   float64
+	refilled time.Time
+}
+
+func newRetryBudget(options RetryBudgetOptions) *retryBudget {
+	if options.Ratio <= 0 {
+		options.Ratio = 0.1
+	}
+	if options.MinPerSecond <= 0 {
+		options.MinPerSecond = 10
+	}
+	return &retryBudget{
+		options:  options,
+		capacity: max(retryBudgetCapacity, options.MinPerSecond),
+		tokens:   options.MinPerSecond,
+		refilled: time.Now(),
+	}
+}
+
+// refill adds the tokens accrued at MinPerSecond since the last refill. Must be called with the lock held.
+func (b *retryBudget) refill() {
+	now := time.Now()
+	if b.tokens < b.options.MinPerSecond {
+		b.tokens = min(b.tokens+now.Sub(b.refilled).Seconds()*b.options.MinPerSecond, b.options.MinPerSecond)
+	}
+	b.refilled = now
+}
+
+// allowRetry withdraws a token for a retry, returning false if the budget is exhausted. A nil budget allows all
+// retries.
+func (b *retryBudget) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = min(b.tokens+b.options.Ratio, b.capacity)
+}
+
+// wrap wraps an HTTP caller, depositing into the budget for every successful request, i.e. requests that get a non 5xx
+// response.
+func (b *retryBudget) wrap(caller func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		response, err := caller(request)
+		if err == nil && response.StatusCode < 500 {
+			b.deposit()
+		}
+		return response, err
+	}
+}
@@ -0,0 +1,92 @@
+package nexus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// An exhausted budget that is practically never refilled.
+var exhaustedRetryBudget = &RetryBudgetOptions{Ratio: 0.001, MinPerSecond: 0.001}
+
+func TestRetryBudget(t *testing.T) {
+	budget := newRetryBudget(RetryBudgetOptions{Ratio: 0.5, MinPerSecond: 1})
+	require.True(t, budget.allowRetry())
+	require.False(t, budget.allowRetry())
+
+	// Successful requests replenish the budget.
+	budget.deposit()
+	require.False(t, budget.allowRetry())
+	budget.deposit()
+	require.True(t, budget.allowRetry())
+	require.False(t, budget.allowRetry())
+
+	// Deposits are capped.
+	for i := 0; i < retryBudgetCapacity*4; i++ {
+		budget.deposit()
+	}
+	for i := 0; i < retryBudgetCapacity; i++ {
+		require.True(t, budget.allowRetry())
+	}
+	require.False(t, budget.allowRetry())
+
+	// A nil budget allows all retries.
+	var nilBudget *retryBudget
+	require.True(t, nilBudget.allowRetry())
+}
+
+func TestRetryBudget_MinPerSecond(t *testing.T) {
+	budget := newRetryBudget(RetryBudgetOptions{MinPerSecond: 20})
+	for i := 0; i < 20; i++ {
+		require.True(t, budget.allowRetry())
+	}
+	require.False(t, budget.allowRetry())
+	time.Sleep(time.Millisecond * 100)
+	require.True(t, budget.allowRetry())
+}
+
+func TestRetryBudget_Resume(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &seekableResultHandler{}}, ClientOptions{
+		RetryBudget: exhaustedRetryBudget,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return nil, err
+			}
+			response.Body = &failingReader{ReadCloser: response.Body, limit: 10}
+			return response, nil
+		},
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	response, err := handle.GetResult(ctx, GetOperationResultOptions{MaxResumes: 3})
+	require.NoError(t, err)
+	defer response.Body.Close()
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorIs(t, err, ErrRetryBudgetExhausted)
+}
+
+func TestRetryBudget_Hedging(t *testing.T) {
+	handler := &slowFirstInfoHandler{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{
+		HedgeDelay:  time.Millisecond * 50,
+		RetryBudget: exhaustedRetryBudget,
+	})
+	defer teardown()
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*300)
+	defer cancel()
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// No hedged request was sent.
+	require.Equal(t, int32(1), handler.calls.Load())
+}
@@ -2,15 +2,20 @@ package nexus
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
-	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -18,19 +23,46 @@ import (
 
 // StartOperationRequest is input for Handler.StartOperation.
 type StartOperationRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
 	// Operation name.
 	Operation string
 	// Request ID, should be used to dedupe start requests.
 	RequestID string
+	// Value of the standard Idempotency-Key header, empty if not provided. Independent of RequestID, which is always set
+	// by Nexus clients.
+	IdempotencyKey string
+	// Priority hint provided by the caller, zero if not provided. The framework only surfaces the value, scheduling
+	// work accordingly is up to the handler.
+	Priority int
+	// Time the caller asked for the operation to start at, zero if the operation should start immediately. The
+	// framework only surfaces the value, scheduling the operation is up to the handler.
+	StartAfter time.Time
+	// Set when the caller indicated that it intends to wait for the operation's result, e.g. when started via
+	// [Client.ExecuteOperation]. The framework only surfaces the hint, handlers may use it for optimizations, such as
+	// keeping the result in memory instead of persisting it.
+	ClientWillPoll bool
 	// Callback URL to call upon completion if the started operation is async.
 	CallbackURL string
+	// All callbacks provided by the caller, including CallbackURL, to call upon completion if the started operation
+	// is async. Use [NewCompletionHTTPRequests] to deliver a completion to all matching callbacks.
+	Callbacks []Callback
+	// Content type negotiated for the operation's result when codecs are registered for the operation in
+	// [HandlerOptions.OperationCodecs], empty otherwise. Handlers responding with a body directly should encode the
+	// result in this content type.
+	ResponseContentType string
 	// The original HTTP request.
-	// Read the URL, Header, and Body of the request to process the operation input.
+	// Read the URL, Header, and Body of the request to process the operation input. Inspect the TLS connection state
+	// and RemoteAddr of the request to authorize the caller.
 	HTTPRequest *http.Request
+	// Options for decoding the input in ReadJSON.
+	decoding jsonDecodingOptions
 }
 
 // GetOperationResultRequest is input for Handler.GetOperationResult.
 type GetOperationResultRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
 	// Operation name.
 	Operation string
 	// Operation ID as originally generated by a Handler.
@@ -39,36 +71,45 @@ type GetOperationResultRequest struct {
 	// If non-zero, reflects the duration the caller has indicated that it wants to wait for operation completion,
 	// turning the request into a long poll.
 	Wait time.Duration
-	// The original HTTP request.
+	// Opaque token identifying the requested page of a paginated result, as previously returned by the handler in
+	// [OperationResponseSync.NextPageToken]. Empty for the first page.
+	PageToken string
+	// Content type negotiated for the operation's result, see [StartOperationRequest.ResponseContentType].
+	ResponseContentType string
+	// The original HTTP request, populated the same as [StartOperationRequest.HTTPRequest].
 	HTTPRequest *http.Request
 }
 
 // GetOperationInfoRequest is input for Handler.GetOperationInfo.
 type GetOperationInfoRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
 	// Operation name.
 	Operation string
 	// Operation ID as originally generated by a Handler.
 	// It is the handler's responsibility to validate this ID and authorize access to the underlying resource.
 	OperationID string
-	// The original HTTP request.
+	// The original HTTP request, populated the same as [StartOperationRequest.HTTPRequest].
 	HTTPRequest *http.Request
 }
 
 // CancelOperationRequest is input for Handler.CancelOperation.
 type CancelOperationRequest struct {
+	// Service name, set when [HandlerOptions.ServiceRouting] is enabled.
+	Service string
 	// Operation name.
 	Operation string
 	// Operation ID as originally generated by a Handler.
 	// It is the handler's responsibility to validate this ID and authorize access to the underlying resource.
 	OperationID string
-	// The original HTTP request.
+	// The original HTTP request, populated the same as [StartOperationRequest.HTTPRequest].
 	HTTPRequest *http.Request
 }
 
-// An OperationResponse is the return type from the handler StartOperation and GetResult methods. It has two
-// implementations: [OperationResponseSync] and [OperationResponseAsync].
+// An OperationResponse is the return type from the handler StartOperation and GetResult methods. It has three
+// implementations: [OperationResponseSync], [OperationResponseAsync], and [OperationResponseAccepted].
 type OperationResponse interface {
-	applyToHTTPResponse(http.ResponseWriter, *httpHandler)
+	applyToHTTPResponse(http.ResponseWriter, *http.Request, *httpHandler)
 }
 
 // Indicates that an operation completed successfully.
@@ -78,6 +119,43 @@ type OperationResponseSync struct {
 	// Body conveying the operation result.
 	// If it is an [io.Closer] it will be automatically closed by the framework.
 	Body io.Reader
+	// Length of Body in bytes, if known. When positive, it is sent in the Content-Length header, avoiding chunked
+	// transfer encoding and allowing clients to track download progress. Body must provide exactly this many bytes.
+	// When Body is also an [io.Seeker], e.g. an [*os.File] or a [*bytes.Reader], results served from
+	// Handler.GetOperationResult honor Range headers, responding with 206 Partial Content, which allows clients to resume
+	// interrupted downloads, see [GetOperationResultOptions.MaxResumes].
+	// Optional, zero means unknown.
+	ContentLength int64
+	// Non-fatal warnings to surface to the caller, e.g. deprecation notices or an indication of partial data. Optional.
+	// Delivered in Nexus-Warning headers, callers may read them with [ResponseWarnings].
+	Warnings []string
+	// Marks the result as partial, e.g. a best-effort result returned from Handler.GetOperationResult when a long
+	// poll times out before the operation completes. Delivered in the Nexus-Result-Partial header, callers may check
+	// it with [IsPartialResult] and decide whether to use the result or keep polling.
+	Partial bool
+	// If set, the body is compressed with gzip when the caller accepts it, as indicated by the request's
+	// Accept-Encoding header, and the Content-Encoding header is set accordingly. ContentLength is ignored for
+	// compressed responses. Useful for large, compressible results.
+	Compress bool
+	// Opaque token for fetching the next page of a paginated result, e.g. when an operation's result is a large
+	// collection. Delivered in the Nexus-Next-Page-Token header and passed back by the caller in
+	// [GetOperationResultRequest.PageToken]. Interpretation is up to the handler. Empty for the last page.
+	NextPageToken string
+	// Invoked once the framework is done writing the response, after Body is closed, with the error that prevented the
+	// response from being fully written, if any, e.g. when the caller disconnects mid-body. Use it to sequence cleanup,
+	// such as releasing a lock or deleting a temporary file backing Body, against delivery of the result. Optional.
+	//
+	// Note that a nil error indicates that the body was written to the connection, not that the caller read it.
+	OnWritten func(err error)
+	// The value this response was constructed from, if constructed with one of the NewOperationResponseSync helpers.
+	source *responseSource
+}
+
+// responseSource records the value an [OperationResponseSync] was constructed from and how it was encoded, allowing
+// [HandlerOptions.ResponseMiddleware] to transform the result prior to serialization.
+type responseSource struct {
+	value  any
+	stream bool
 }
 
 // NewOperationResponseSync constructs an [OperationResponseSync], setting the proper Content-Type header.
@@ -90,34 +168,195 @@ func NewOperationResponseSync(v any) (*OperationResponseSync, error) {
 	header := make(http.Header)
 	header.Set(headerContentType, contentTypeJSON)
 	return &OperationResponseSync{
-		Header: header,
-		Body:   bytes.NewReader(b),
+		Header:        header,
+		Body:          bytes.NewReader(b),
+		ContentLength: int64(len(b)),
+		source:        &responseSource{value: v},
 	}, nil
 }
 
-func (r *OperationResponseSync) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
+// NewOperationResponseSyncStream constructs an [OperationResponseSync], setting the proper Content-Type header.
+// Unlike [NewOperationResponseSync], the value is encoded to JSON as the response is written, without materializing
+// the entire payload in memory first. Slices and arrays are encoded one element at a time, other values are encoded
+// with a [json.Encoder].
+//
+// Since the value is encoded lazily, encoding errors cannot be reported via the response status. Instead, the response
+// is aborted and the client observes a broken response rather than truncated JSON.
+//
+// Encoding starts on the first read of the response body, which must be closed to release resources if read partially.
+func NewOperationResponseSyncStream(v any) *OperationResponseSync {
+	header := make(http.Header)
+	header.Set(headerContentType, contentTypeJSON)
+	return &OperationResponseSync{
+		Header: header,
+		Body:   &jsonStreamReader{value: v},
+		source: &responseSource{value: v, stream: true},
+	}
+}
+
+// jsonStreamReader reads the JSON encoding of a value, encoded with [encodeJSONStream] in a goroutine that is started on
+// the first read, so that responses that are never written don't leak it.
+type jsonStreamReader struct {
+	value  any
+	once   sync.Once
+	reader *io.PipeReader
+}
+
+func (r *jsonStreamReader) start() {
+	r.once.Do(func() {
+		reader, writer := io.Pipe()
+		r.reader = reader
+		go func() {
+			writer.CloseWithError(encodeJSONStream(writer, r.value))
+		}()
+	})
+}
+
+// Read implements io.Reader, starting the encoding goroutine on the first call.
+func (r *jsonStreamReader) Read(p []byte) (int, error) {
+	r.start()
+	return r.reader.Read(p)
+}
+
+// Close implements io.Closer, stopping the encoding goroutine if started.
+func (r *jsonStreamReader) Close() error {
+	// Closing before the first read releases the reader without ever starting the encoding goroutine.
+	r.once.Do(func() { r.reader, _ = io.Pipe() })
+	return r.reader.Close()
+}
+
+// encodeJSONStream encodes v as JSON to w, encoding slices and arrays element by element.
+func encodeJSONStream(w io.Writer, v any) error {
+	value := reflect.ValueOf(v)
+	_, isMarshaler := v.(json.Marshaler)
+	if isMarshaler || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) || value.Type().Elem().Kind() == reflect.Uint8 {
+		return json.NewEncoder(w).Encode(v)
+	}
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		_, err := io.WriteString(w, "null")
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		b, err := json.Marshal(value.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+func (r *OperationResponseSync) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler) {
+	var writeErr error
+	if r.OnWritten != nil {
+		// Registered first to run after the body is closed.
+		defer func() { r.OnWritten(writeErr) }()
+	}
 	header := writer.Header()
 	for k, v := range r.Header {
 		header[k] = v
 	}
+	for _, warning := range r.Warnings {
+		header.Add(headerWarning, formatWarning(warning))
+	}
+	if r.Partial {
+		header.Set(headerResultPartial, "true")
+	}
+	if r.NextPageToken != "" {
+		header.Set(headerNextPageToken, r.NextPageToken)
+	}
+	compress := false
+	if r.Compress {
+		header.Add("Vary", "Accept-Encoding")
+		compress = acceptsGzip(request)
+	}
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+	} else if r.ContentLength > 0 {
+		header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	}
+	if r.Body == nil {
+		// Treat a nil body as an empty one.
+		return
+	}
 	if closer, ok := r.Body.(io.Closer); ok {
 		defer closer.Close()
 	}
-	if _, err := io.Copy(writer, r.Body); err != nil {
-		handler.logger.Error("failed to write response body", "error", err)
+	if handler.options.MaxResultWriteDuration > 0 {
+		deadline := time.Now().Add(handler.options.MaxResultWriteDuration)
+		if err := http.NewResponseController(writer).SetWriteDeadline(deadline); err != nil {
+			handler.logger.Warn("failed to set response write deadline", "error", err)
+		}
+	}
+	source := r.Body
+	if seeker, ok := r.Body.(io.ReadSeeker); ok && !compress && r.ContentLength > 0 && request.Method == "GET" {
+		if source, writeErr = r.applyRange(writer, request, handler, seeker); writeErr != nil {
+			return
+		}
+	}
+	var digest *resultDigestWriter
+	// Range responses have their headers written and don't carry a digest.
+	if _, ranged := source.(*io.LimitedReader); handler.options.SendResultDigest && !ranged {
+		digest = &resultDigestWriter{}
+		source = digest.prepare(header, source)
+	}
+	var body io.Writer = writer
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(writer)
+		body = gzipWriter
+	}
+	_, writeErr = io.Copy(body, source)
+	if writeErr == nil && gzipWriter != nil {
+		writeErr = gzipWriter.Close()
+	}
+	if writeErr != nil {
+		handler.logger.Error("failed to write response body", "error", writeErr)
+		// The status code has likely already been sent, abort the response to ensure that the client does not mistake
+		// a partially written body for a complete one.
+		panic(http.ErrAbortHandler)
+	}
+	if digest != nil {
+		digest.finish(header)
+	}
+}
+
+// abandon releases a response that is not written due to err, closing its body and invoking OnWritten.
+func (r *OperationResponseSync) abandon(err error) {
+	if closer, ok := r.Body.(io.Closer); ok {
+		closer.Close()
+	}
+	if r.OnWritten != nil {
+		r.OnWritten(err)
 	}
 }
 
 // Indicates that an operation has been accepted and will complete asynchronously.
 type OperationResponseAsync struct {
 	OperationID string
+	// Time after which the handler gives up on the operation, see [OperationInfo.Deadline]. Optional.
+	Deadline time.Time
 }
 
-func (r *OperationResponseAsync) applyToHTTPResponse(writer http.ResponseWriter, handler *httpHandler) {
+func (r *OperationResponseAsync) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler) {
 	info := OperationInfo{
 		ID:    r.OperationID,
 		State: OperationStateRunning,
 	}
+	if !r.Deadline.IsZero() {
+		info.Deadline = &r.Deadline
+	}
 	bytes, err := json.Marshal(info)
 	if err != nil {
 		handler.logger.Error("failed to serialize operation info", "error", err)
@@ -126,6 +365,7 @@ func (r *OperationResponseAsync) applyToHTTPResponse(writer http.ResponseWriter,
 	}
 
 	writer.Header().Set(headerContentType, contentTypeJSON)
+	writer.Header().Set(headerOperationID, r.OperationID)
 	writer.WriteHeader(http.StatusCreated)
 
 	if _, err := writer.Write(bytes); err != nil {
@@ -133,6 +373,88 @@ func (r *OperationResponseAsync) applyToHTTPResponse(writer http.ResponseWriter,
 	}
 }
 
+// OperationResponseAccepted indicates that a fire-and-forget operation was accepted and will run asynchronously, without
+// a result to retrieve. Unlike [OperationResponseAsync], callers should not poll for the operation's result.
+//
+// The handler responds with 202 Accepted and a Nexus-Result-Available header set to false. Clients surface this
+// response as [StartOperationResult.Accepted].
+type OperationResponseAccepted struct {
+	// ID of the accepted operation for reference, e.g. for correlating logs. Optional.
+	OperationID string
+}
+
+func (r *OperationResponseAccepted) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler) {
+	writer.Header().Set(headerResultAvailable, "false")
+	if r.OperationID == "" {
+		writer.WriteHeader(http.StatusAccepted)
+		return
+	}
+	bytes, err := json.Marshal(OperationInfo{
+		ID:    r.OperationID,
+		State: OperationStateRunning,
+	})
+	if err != nil {
+		handler.logger.Error("failed to serialize operation info", "error", err)
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set(headerContentType, contentTypeJSON)
+	writer.WriteHeader(http.StatusAccepted)
+
+	if _, err := writer.Write(bytes); err != nil {
+		handler.logger.Error("failed to write response body", "error", err)
+	}
+}
+
+// WithResponseHeader wraps an [OperationResponse], adding the given headers to the HTTP response. The wrapped response's
+// status and body are preserved. Headers set by the wrapped response itself take precedence.
+func WithResponseHeader(response OperationResponse, header http.Header) OperationResponse {
+	return &headerDecoratedOperationResponse{OperationResponse: response, header: header}
+}
+
+// WithCacheControl wraps an [OperationResponse], adding a Cache-Control header allowing caches to store the response
+// for up to maxAge.
+func WithCacheControl(response OperationResponse, maxAge time.Duration) OperationResponse {
+	return WithResponseHeader(response, http.Header{"Cache-Control": []string{fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))}})
+}
+
+type headerDecoratedOperationResponse struct {
+	OperationResponse
+	header http.Header
+}
+
+func (r *headerDecoratedOperationResponse) applyToHTTPResponse(writer http.ResponseWriter, request *http.Request, handler *httpHandler) {
+	header := writer.Header()
+	for k, v := range r.header {
+		header[k] = v
+	}
+	r.OperationResponse.applyToHTTPResponse(writer, request, handler)
+}
+
+// Error indicating that a Handler returned neither a response nor an error, reported as an internal server error.
+var errNilOperationResponse = errors.New("handler returned a nil response without an error")
+
+// isNilOperationResponse returns true if response is nil or an interface holding a nil pointer.
+func isNilOperationResponse(response OperationResponse) bool {
+	if response == nil {
+		return true
+	}
+	value := reflect.ValueOf(response)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+// unwrapOperationResponse returns the innermost response of a possibly decorated response.
+func unwrapOperationResponse(response OperationResponse) OperationResponse {
+	for {
+		decorated, ok := response.(*headerDecoratedOperationResponse)
+		if !ok {
+			return response
+		}
+		response = decorated.OperationResponse
+	}
+}
+
 // A Handler must implement all of the Nexus service endpoints as defined in the [Nexus HTTP API].
 //
 // Handler implementations must embed the [UnimplementedHandler].
@@ -142,7 +464,8 @@ func (r *OperationResponseAsync) applyToHTTPResponse(writer http.ResponseWriter,
 // [Nexus HTTP API]: https://github.com/nexus-rpc/api
 type Handler interface {
 	// StartOperation handles requests for starting an operation. Return [OperationResponseSync] to respond successfully
-	// - inline, or [OperationResponseAsync] to indicate that an asynchronous operation was started.
+	// - inline, [OperationResponseAsync] to indicate that an asynchronous operation was started, or
+	// [OperationResponseAccepted] to indicate that a fire-and-forget operation was started without a result to retrieve.
 	// Return an [UnsuccessfulOperationError] to indicate that an operation completed as failed or canceled.
 	StartOperation(context.Context, *StartOperationRequest) (OperationResponse, error)
 	// GetOperationResult handles requests to get the result of an asynchronous operation. Return
@@ -165,7 +488,26 @@ type Handler interface {
 	//  1. asynchronous - returning from this method only ensures that cancelation is delivered, it may later be ignored
 	//  by the underlying operation implemention.
 	//  2. idempotent - implementors should ignore duplicate cancelations for the same operation.
+	//
+	// Return [ErrOperationCanceledSynchronously] to indicate that the operation was canceled before returning.
 	CancelOperation(context.Context, *CancelOperationRequest) error
+	// StreamOperationEvents handles requests to stream events emitted by an asynchronous operation during its
+	// execution, e.g. logs. Events sent on the returned channel are written to the caller as newline delimited JSON
+	// until the channel is closed or the context is canceled, implementors must stop sending events once the context
+	// is done.
+	StreamOperationEvents(context.Context, *StreamOperationEventsRequest) (<-chan OperationEvent, error)
+	// CancelOperations handles administrative requests to cancel all operations matching a filter, e.g. all operations
+	// of a tenant. Like CancelOperation, it should be idempotent: operations that are already canceled should be
+	// counted as canceled rather than failed, allowing callers to safely retry requests. Retries carry the same
+	// [CancelOperationsRequest.RequestID] when set by the caller.
+	CancelOperations(context.Context, *CancelOperationsRequest) (*CancelResult, error)
+	// LookupOperation handles requests to look up the asynchronous operation started by a start operation request,
+	// identified by the request's [StartOperationRequest.RequestID], allowing callers to recover operations whose
+	// start response was lost, see [ClientOptions.RecoverStartTimeouts]. Return nil info only if the request did not
+	// run an operation, callers may safely retry it. If the request completed the operation synchronously, return info
+	// with an empty ID and the state the operation completed in, the result is not recoverable, but callers learn not
+	// to run the operation again, see [ErrOperationCompletedSynchronously].
+	LookupOperation(context.Context, *LookupOperationRequest) (*OperationInfo, error)
 	mustEmbedUnimplementedHandler()
 }
 
@@ -176,6 +518,10 @@ type HandlerError struct {
 	StatusCode int
 	// Failure to report back in the response. Optional.
 	Failure *Failure
+	// Header to set on the response, e.g. WWW-Authenticate for 401 or Retry-After for 429 responses. Optional.
+	Header http.Header
+	// Set for errors returned from UnimplementedHandler methods.
+	unimplemented bool
 }
 
 // Error implements the error interface.
@@ -197,21 +543,52 @@ func newBadRequestError(format string, args ...any) *HandlerError {
 
 type baseHTTPHandler struct {
 	logger *slog.Logger
+	// Optional function for extracting failure metadata from errors, see [HandlerOptions.ErrorDetailsFunc].
+	errorDetailsFunc func(error) map[string]string
+	// Codec for serializing failures, defaults to JSON when nil.
+	failureCodec FailureCodec
 }
 
 type httpHandler struct {
 	baseHTTPHandler
 	options HandlerOptions
+	// Set when [HandlerOptions.CoalesceStartRequests] is enabled.
+	coalescer *startCoalescer
+	// HandlerOptions.DeprecatedOperations keyed by normalized operation name.
+	deprecatedOperations map[string]OperationDeprecation
+	// HandlerOptions.OperationCodecs keyed by normalized operation name.
+	operationCodecs map[string][]ResultCodec
+	// The Handler prior to applying HandlerOptions.Middleware, if it implements OperationLister.
+	operationLister OperationLister
 }
 
 func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
+	h.writeFailureLogged(writer, err, slog.LevelError)
+}
+
+// writeFailureLogged is like writeFailure, logging unexpected errors at the given level.
+func (h *baseHTTPHandler) writeFailureLogged(writer http.ResponseWriter, err error, level slog.Level) {
 	var failure *Failure
 	var unsuccessfulError *UnsuccessfulOperationError
 	var handlerError *HandlerError
+	var validationError *ValidationError
+	var quotaError *QuotaExceededError
 	var operationState OperationState
 	statusCode := http.StatusInternalServerError
 
-	if errors.As(err, &unsuccessfulError) {
+	if errors.As(err, &validationError) {
+		statusCode = http.StatusBadRequest
+		var marshalErr error
+		if failure, marshalErr = validationError.failure(); marshalErr != nil {
+			h.logger.Error("failed to marshal validation error", "error", marshalErr)
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	} else if errors.As(err, &quotaError) {
+		statusCode = http.StatusTooManyRequests
+		failure = &Failure{Message: quotaError.Error()}
+		quotaError.setHeaders(writer.Header(), time.Now())
+	} else if errors.As(err, &unsuccessfulError) {
 		operationState = unsuccessfulError.State
 		failure = &unsuccessfulError.Failure
 		statusCode = statusOperationFailed
@@ -226,22 +603,39 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 	} else if errors.As(err, &handlerError) {
 		failure = handlerError.Failure
 		statusCode = handlerError.StatusCode
+		header := writer.Header()
+		for k, v := range handlerError.Header {
+			header[k] = v
+		}
+	} else if errors.Is(err, ErrOperationConflict) {
+		statusCode = http.StatusConflict
+		failure = &Failure{Message: err.Error()}
 	} else {
 		failure = &Failure{
 			Message: "internal server error",
 		}
-		h.logger.Error("handler failed", "error", err)
+		h.logger.Log(context.Background(), level, "handler failed", "error", err)
+	}
+
+	if h.errorDetailsFunc != nil {
+		if details := h.errorDetailsFunc(err); len(details) > 0 {
+			failure = withFailureMetadata(failure, details)
+		}
 	}
 
 	var bytes []byte
 	if failure != nil {
-		bytes, err = json.Marshal(failure)
+		codec := h.failureCodec
+		if codec == nil {
+			codec = jsonFailureCodec{}
+		}
+		bytes, err = codec.Marshal(failure)
 		if err != nil {
 			h.logger.Error("failed to marshal failure", "error", err)
 			writer.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		writer.Header().Set(headerContentType, contentTypeJSON)
+		writer.Header().Set(headerContentType, codec.ContentType())
 	}
 
 	writer.WriteHeader(statusCode)
@@ -251,43 +645,303 @@ func (h *baseHTTPHandler) writeFailure(writer http.ResponseWriter, err error) {
 	}
 }
 
+// withFailureMetadata returns a copy of failure with the given metadata merged in, existing keys take precedence.
+// The original failure is not modified since it may be owned by the caller.
+func withFailureMetadata(failure *Failure, metadata map[string]string) *Failure {
+	merged := &Failure{Metadata: make(map[string]string, len(metadata))}
+	if failure != nil {
+		*merged = *failure
+		merged.Metadata = make(map[string]string, len(failure.Metadata)+len(metadata))
+		for k, v := range failure.Metadata {
+			merged.Metadata[k] = v
+		}
+	}
+	for k, v := range metadata {
+		if _, ok := merged.Metadata[k]; !ok {
+			merged.Metadata[k] = v
+		}
+	}
+	return merged
+}
+
+// operationPath holds the parsed components of an operation URL path.
+type operationPath struct {
+	service     string
+	operation   string
+	operationID string
+}
+
+// parseOperationPath extracts the service (when service routing is enabled), operation, and operation ID (when
+// withOperationID is set) from the request's URL path. Components are parsed from the end of the path to allow
+// mounting the handler under an arbitrary prefix. If suffix is non-empty (e.g. "result"), it is expected to be the last
+// path component.
+// The operation is resolved with [HandlerOptions.OperationResolver] instead, when set.
+func (h *httpHandler) parseOperationPath(request *http.Request, withOperationID bool, suffix string) (operationPath, error) {
+	var parsed operationPath
+	segments := strings.Split(request.URL.EscapedPath(), "/")
+	if suffix != "" {
+		segments = segments[:len(segments)-1]
+	}
+	pop := func() (string, error) {
+		if len(segments) == 0 {
+			return "", newBadRequestError("failed to parse URL path")
+		}
+		segment := segments[len(segments)-1]
+		segments = segments[:len(segments)-1]
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", newBadRequestError("failed to parse URL path")
+		}
+		return unescaped, nil
+	}
+	var err error
+	if withOperationID {
+		if parsed.operationID, err = pop(); err != nil {
+			return parsed, err
+		}
+	}
+	if parsed.operation, err = pop(); err != nil {
+		return parsed, err
+	}
+	if h.options.ServiceRouting {
+		if parsed.service, err = pop(); err != nil {
+			return parsed, err
+		}
+	}
+	if h.options.OperationResolver != nil {
+		if parsed.operation, err = h.options.OperationResolver(request); err != nil {
+			var handlerError *HandlerError
+			if errors.As(err, &handlerError) {
+				return parsed, err
+			}
+			return parsed, newBadRequestError("failed to resolve operation: %v", err)
+		}
+	}
+	if parsed.operation == "" {
+		return parsed, newBadRequestError("empty operation name")
+	}
+	// Lowercase before validating, names that are reserved in lowercase are reserved in any case.
+	if h.options.CaseInsensitiveOperations {
+		parsed.service = strings.ToLower(parsed.service)
+		parsed.operation = strings.ToLower(parsed.operation)
+	}
+	validateOperationName := h.options.OperationNameValidator
+	if validateOperationName == nil {
+		validateOperationName = DefaultOperationNameValidator
+	}
+	if err := validateOperationName(parsed.operation); err != nil {
+		return parsed, newBadRequestError("invalid operation name: %v", err)
+	}
+	if withOperationID && parsed.operationID == "" {
+		return parsed, newBadRequestError("empty operation ID")
+	}
+	setRequestMetricsOperation(request, parsed.operation)
+	return parsed, nil
+}
+
+// parseStartAfter parses a Nexus-Start-After header value, either an RFC3339 timestamp or a non-negative duration
+// relative to now.
+func parseStartAfter(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d < 0 {
+		return time.Time{}, fmt.Errorf("negative duration: %s", value)
+	}
+	return now.Add(d), nil
+}
+
 func (h *httpHandler) startOperation(writer http.ResponseWriter, request *http.Request) {
-	operation, err := url.PathUnescape(path.Base(request.URL.RawPath))
+	parsed, err := h.parseOperationPath(request, false, "")
 	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
+	h.setDeprecationHeaders(writer.Header(), parsed.operation)
+	codec, err := h.negotiateResultCodec(request, parsed.operation)
+	if err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	requestID := request.Header.Get(headerRequestID)
+	if h.options.RequireRequestID && requestID == "" {
+		h.writeFailure(writer, newBadRequestError("missing %s header", headerRequestID))
+		return
+	}
+	// Requests with an unknown length (-1) may have a body.
+	if request.Header.Get(headerContentType) == "" && request.ContentLength != 0 {
+		if h.options.RequireContentType {
+			h.writeFailure(writer, newBadRequestError("missing %s header", headerContentType))
+			return
+		}
+		if h.options.DefaultContentType != "" {
+			request.Header.Set(headerContentType, h.options.DefaultContentType)
+		}
+	}
+	if err := h.limitRequestBody(writer, request, h.options.MaxRequestBodyBytes); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	if h.options.VerifyBodyDigest {
+		if err := verifyRequestBodyDigest(request); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+	}
+	if err := h.applyInputMiddleware(request, parsed.operation); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	callbacks, err := callbacksFromRequest(request)
+	if err != nil {
+		h.writeFailure(writer, newBadRequestError("%v", err))
+		return
+	}
+	var priority int
+	if value := request.Header.Get(headerPriority); value != "" {
+		priority, err = strconv.Atoi(value)
+		if err != nil {
+			h.writeFailure(writer, newBadRequestError("invalid %s header: %q", headerPriority, value))
+			return
+		}
+	}
+	var startAfter time.Time
+	if value := request.Header.Get(headerStartAfter); value != "" {
+		startAfter, err = parseStartAfter(value, time.Now())
+		if err != nil {
+			h.writeFailure(writer, newBadRequestError("invalid %s header: %q", headerStartAfter, value))
+			return
+		}
+	}
 	handlerRequest := &StartOperationRequest{
-		Operation:   operation,
-		RequestID:   request.Header.Get(headerRequestID),
-		CallbackURL: request.URL.Query().Get(queryCallbackURL),
-		HTTPRequest: request,
+		Service:        parsed.service,
+		Operation:      parsed.operation,
+		RequestID:      requestID,
+		IdempotencyKey: request.Header.Get(headerIdempotencyKey),
+		Priority:       priority,
+		StartAfter:     startAfter,
+		ClientWillPoll: request.Header.Get(headerClientWillPoll) == "true",
+		CallbackURL:    request.URL.Query().Get(queryCallbackURL),
+		Callbacks:      callbacks,
+		HTTPRequest:    request,
+		decoding:       h.jsonDecodingOptions(),
+	}
+	if codec != nil {
+		handlerRequest.ResponseContentType = codec.ContentType()
+	}
+	var response OperationResponse
+	if h.coalescer != nil && requestID != "" {
+		key := coalesceKey{service: parsed.service, operation: parsed.operation, requestID: requestID}
+		response, err = h.coalescer.do(request.Context(), key, func() (OperationResponse, error) {
+			return h.options.Handler.StartOperation(request.Context(), handlerRequest)
+		})
+	} else {
+		response, err = h.options.Handler.StartOperation(request.Context(), handlerRequest)
+	}
+	err = h.checkUnimplemented(err, parsed.operation, "StartOperation")
+	if err == nil && isNilOperationResponse(response) {
+		err = errNilOperationResponse
+	}
+	if err == nil {
+		if syncResponse, ok := unwrapOperationResponse(response).(*OperationResponseSync); ok {
+			err = h.applyResponseMiddleware(request.Context(), parsed.operation, syncResponse)
+			if err == nil && codec != nil {
+				err = encodeResult(syncResponse, codec)
+			}
+		}
 	}
-	response, err := h.options.Handler.StartOperation(request.Context(), handlerRequest)
 	if err != nil {
-		h.writeFailure(writer, err)
+		if request.Context().Err() != nil {
+			// The client most likely disconnected while the handler was reading the request body, don't log the
+			// failure as an error. It is still written in case the context was done for another reason, e.g. a
+			// server side timeout.
+			h.writeFailureLogged(writer, err, slog.LevelDebug)
+		} else {
+			h.writeFailure(writer, err)
+		}
 	} else {
-		response.applyToHTTPResponse(writer, h)
+		if async, ok := unwrapOperationResponse(response).(*OperationResponseAsync); ok {
+			writer.Header().Set(headerLocation, h.operationLocation(request, parsed, async.OperationID))
+		}
+		response.applyToHTTPResponse(writer, request, h)
+	}
+}
+
+// applyResponseMiddleware transforms a successful result with [HandlerOptions.ResponseMiddleware], if set, replacing
+// the response's body with the encoded transformed result. Responses not constructed from a value are left as is.
+func (h *httpHandler) applyResponseMiddleware(ctx context.Context, operation string, response *OperationResponseSync) error {
+	if h.options.ResponseMiddleware == nil || response.source == nil {
+		return nil
+	}
+	result, err := h.options.ResponseMiddleware(ctx, operation, response.source.value)
+	if err != nil {
+		response.abandon(err)
+		return err
+	}
+	var transformed *OperationResponseSync
+	if response.source.stream {
+		transformed = NewOperationResponseSyncStream(result)
+	} else if transformed, err = NewOperationResponseSync(result); err != nil {
+		err = fmt.Errorf("failed to marshal transformed result: %w", err)
+		response.abandon(err)
+		return err
+	}
+	// Release the original body, this also stops the encoding goroutine of stream responses if started.
+	if closer, ok := response.Body.(io.Closer); ok {
+		closer.Close()
 	}
+	response.Body = transformed.Body
+	response.ContentLength = transformed.ContentLength
+	response.source = transformed.source
+	return nil
+}
+
+// operationLocation returns the path of the resource representing an operation started by the given request.
+//
+// When [HandlerOptions.BasePath] is unset, the path is relative to the start request's path, which is correct
+// regardless of where the handler is mounted as long as the request path isn't rewritten by a proxy.
+func (h *httpHandler) operationLocation(request *http.Request, parsed operationPath, operationID string) string {
+	if h.options.BasePath == "" {
+		return strings.TrimSuffix(request.URL.EscapedPath(), "/") + "/" + url.PathEscape(operationID)
+	}
+	elems := []string{strings.TrimSuffix(h.options.BasePath, "/")}
+	if parsed.service != "" {
+		elems = append(elems, url.PathEscape(parsed.service))
+	}
+	elems = append(elems, url.PathEscape(parsed.operation), url.PathEscape(operationID))
+	return strings.Join(elems, "/")
 }
 
 func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *http.Request) {
-	// strip /result
-	prefix, operationIDEscaped := path.Split(path.Dir(request.URL.RawPath))
-	operationID, err := url.PathUnescape(operationIDEscaped)
+	parsed, err := h.parseOperationPath(request, true, "result")
 	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
-	operation, err := url.PathUnescape(path.Base(prefix))
+	h.setDeprecationHeaders(writer.Header(), parsed.operation)
+	codec, err := h.negotiateResultCodec(request, parsed.operation)
 	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
-	handlerRequest := &GetOperationResultRequest{Operation: operation, OperationID: operationID, HTTPRequest: request}
+	handlerRequest := &GetOperationResultRequest{
+		Service:     parsed.service,
+		Operation:   parsed.operation,
+		OperationID: parsed.operationID,
+		PageToken:   request.URL.Query().Get(queryPageToken),
+		HTTPRequest: request,
+	}
+	if codec != nil {
+		handlerRequest.ResponseContentType = codec.ContentType()
+	}
 
 	waitStr := request.URL.Query().Get(queryWait)
 	ctx := request.Context()
+	var timeoutSource string
 	if waitStr != "" {
 		waitDuration, err := time.ParseDuration(waitStr)
 		if err != nil {
@@ -296,14 +950,27 @@ func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *ht
 			return
 		}
 		handlerRequest.Wait = waitDuration
+		timeout := h.options.GetResultTimeout
+		timeoutSource = timeoutSourceServer
+		if requestTimeoutStr := request.Header.Get(headerRequestTimeout); requestTimeoutStr != "" {
+			requestTimeout, err := time.ParseDuration(requestTimeoutStr)
+			if err != nil || requestTimeout <= 0 {
+				h.logger.Warn("ignoring invalid request timeout header", "timeout", requestTimeoutStr)
+			} else if requestTimeout < timeout {
+				timeout = requestTimeout
+				timeoutSource = timeoutSourceClient
+			}
+		}
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(request.Context(), h.options.GetResultTimeout)
+		ctx, cancel = context.WithTimeout(request.Context(), timeout)
 		defer cancel()
 	}
 
 	response, err := h.options.Handler.GetOperationResult(ctx, handlerRequest)
+	err = h.checkUnimplemented(err, parsed.operation, "GetOperationResult")
 	if err != nil {
 		if handlerRequest.Wait > 0 && ctx.Err() != nil {
+			writer.Header().Set(headerTimeoutSource, timeoutSource)
 			writer.WriteHeader(http.StatusRequestTimeout)
 		} else if errors.Is(err, ErrOperationStillRunning) {
 			writer.WriteHeader(statusOperationRunning)
@@ -312,24 +979,39 @@ func (h *httpHandler) getOperationResult(writer http.ResponseWriter, request *ht
 		}
 		return
 	}
-	response.applyToHTTPResponse(writer, h)
+	if response == nil {
+		h.writeFailure(writer, errNilOperationResponse)
+		return
+	}
+	if err := h.applyResponseMiddleware(ctx, parsed.operation, response); err != nil {
+		h.writeFailure(writer, err)
+		return
+	}
+	if codec != nil {
+		if err := encodeResult(response, codec); err != nil {
+			h.writeFailure(writer, err)
+			return
+		}
+	}
+	response.applyToHTTPResponse(writer, request, h)
 }
 
 func (h *httpHandler) getOperationInfo(writer http.ResponseWriter, request *http.Request) {
-	prefix, operationIDEscaped := path.Split(request.URL.RawPath)
-	operationID, err := url.PathUnescape(operationIDEscaped)
+	parsed, err := h.parseOperationPath(request, true, "")
 	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
-	operation, err := url.PathUnescape(path.Base(prefix))
-	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
-		return
+	h.setDeprecationHeaders(writer.Header(), parsed.operation)
+	handlerRequest := &GetOperationInfoRequest{
+		Service:     parsed.service,
+		Operation:   parsed.operation,
+		OperationID: parsed.operationID,
+		HTTPRequest: request,
 	}
-	handlerRequest := &GetOperationInfoRequest{Operation: operation, OperationID: operationID, HTTPRequest: request}
 
 	info, err := h.options.Handler.GetOperationInfo(request.Context(), handlerRequest)
+	err = h.checkUnimplemented(err, parsed.operation, "GetOperationInfo")
 	if err != nil {
 		h.writeFailure(writer, err)
 		return
@@ -347,21 +1029,25 @@ func (h *httpHandler) getOperationInfo(writer http.ResponseWriter, request *http
 }
 
 func (h *httpHandler) cancelOperation(writer http.ResponseWriter, request *http.Request) {
-	// strip /cancel
-	prefix, operationIDEscaped := path.Split(path.Dir(request.URL.RawPath))
-	operationID, err := url.PathUnescape(operationIDEscaped)
+	parsed, err := h.parseOperationPath(request, true, "cancel")
 	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
+		h.writeFailure(writer, err)
 		return
 	}
-	operation, err := url.PathUnescape(path.Base(prefix))
-	if err != nil {
-		h.writeFailure(writer, newBadRequestError("failed to parse URL path"))
-		return
+	h.setDeprecationHeaders(writer.Header(), parsed.operation)
+	handlerRequest := &CancelOperationRequest{
+		Service:     parsed.service,
+		Operation:   parsed.operation,
+		OperationID: parsed.operationID,
+		HTTPRequest: request,
 	}
-	handlerRequest := &CancelOperationRequest{Operation: operation, OperationID: operationID, HTTPRequest: request}
 
-	if err := h.options.Handler.CancelOperation(request.Context(), handlerRequest); err != nil {
+	err = h.options.Handler.CancelOperation(request.Context(), handlerRequest)
+	if err = h.checkUnimplemented(err, parsed.operation, "CancelOperation"); err != nil {
+		if errors.Is(err, ErrOperationCanceledSynchronously) {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
 		h.writeFailure(writer, err)
 		return
 	}
@@ -381,27 +1067,387 @@ type HandlerOptions struct {
 	//
 	// Defaults to one minute.
 	GetResultTimeout time.Duration
+	// If set, start operation request bodies are verified against the SHA-256 digest in the request's Digest header,
+	// when present.
+	//
+	// The body is hashed as it is read by the Handler, once the body is read in its entirety, a mismatch is reported as
+	// a read error of type *[HandlerError] with a 400 status code. Handlers should propagate this error (optionally
+	// wrapped) to fail the request.
+	VerifyBodyDigest bool
+	// If set, a SHA-256 digest of successful results is sent in the Digest trailer of start operation and get operation
+	// result responses, for clients to verify with [ClientOptions.VerifyResultDigest]. The digest is computed as the
+	// result is written and covers the result before compression. Not sent for partial responses to range requests.
+	//
+	// Trailers require chunked encoding, responses with a digest are sent without a Content-Length header, which
+	// prevents clients from resuming interrupted downloads, see [GetOperationResultOptions.MaxResumes].
+	SendResultDigest bool
+	// If set, URL paths are expected to be prefixed with a service segment, e.g. /{service}/{operation}, allowing a
+	// single handler to host multiple logical services. The parsed service is exposed to the Handler via the Service
+	// field of the various request types.
+	ServiceRouting bool
+	// If set, a single trailing slash is trimmed from request URL paths before routing, e.g. a request to /charge/ is
+	// treated as a request to /charge.
+	//
+	// Defaults to false, in which case requests with a trailing slash are not routed and get a 404 response.
+	TrimTrailingSlash bool
+	// If set, operation (and service) names parsed from the URL path are converted to lower case before being passed
+	// to the Handler, e.g. a request to /Charge is handled as the "charge" operation. Operation IDs and the fixed path
+	// segments (result, cancel, and events) are always matched case sensitively.
+	//
+	// Defaults to false, in which case operation names are passed to the Handler as is.
+	CaseInsensitiveOperations bool
+	// If set, start operation requests without a Nexus-Request-Id header are rejected with a 400 status code, ensuring
+	// that clients always supply a key for deduping start requests.
+	RequireRequestID bool
+	// Path prefix the handler is exposed under, used to construct the Location header of asynchronous start operation
+	// responses, e.g. /api/nexus. Optional.
+	//
+	// Defaults to deriving the location from the start request's URL path, set this when a proxy rewrites request
+	// paths before they reach the handler.
+	BasePath string
+	// Handler for requests to the root path (/), e.g. for serving a landing page, a capability document, or a redirect.
+	// Optional.
+	//
+	// Defaults to nil, in which case requests to the root path get a 404 response.
+	RootHandler http.Handler
+	// A function for extracting failure metadata from errors written to the caller, e.g. wrapped error messages or
+	// error codes for debugging internal services. Optional.
+	//
+	// When set, the returned map is merged into the Metadata of the response's [Failure], keys already set by the
+	// Handler take precedence. Note that the function is called for all failures including framework errors and
+	// internal errors whose message is otherwise hidden from the caller, take care not to leak sensitive information.
+	//
+	// Defaults to nil, in which case no metadata is added.
+	ErrorDetailsFunc func(error) map[string]string
+	// Codec for serializing failures in error responses, e.g. for emitting protobuf google.rpc.Status messages in
+	// services that use protobuf end to end. The response's Content-Type header is set to the codec's content type.
+	// Clients must be configured with a matching codec via [ClientOptions.FailureCodecs]. Optional.
+	//
+	// Defaults to JSON.
+	FailureCodec FailureCodec
+	// If set, GET requests to /_operations respond with a JSON list of the operations described by the Handler, which
+	// must implement [OperationLister]. Useful for generating client stubs or API docs.
+	//
+	// Defaults to false since the operation list may be sensitive. The endpoint does not conflict with operations named
+	// _operations, which are started with POST requests.
+	ExposeOperations bool
+	// Max duration to allow writing a successful operation response, including its body. Optional.
+	//
+	// When exceeded, e.g. because a slow client can't keep up with a large streaming result, the write fails and the
+	// response is aborted, releasing the handler's resources. Requires a server whose response writer supports write
+	// deadlines, such as [http.Server].
+	//
+	// Defaults to no limit.
+	MaxResultWriteDuration time.Duration
+	// A function for post-processing successful results before they are serialized, e.g. for wrapping results in an
+	// envelope or redacting fields based on the caller's role. Return an error to reject the result, the error is
+	// written to the caller as if returned from the Handler. Optional.
+	//
+	// Applies to start operation and get operation result responses constructed with [NewOperationResponseSync] or
+	// [NewOperationResponseSyncStream], which record the result value, the transformed result is encoded the same way.
+	// Responses constructed directly from a body are written as is.
+	ResponseMiddleware func(ctx context.Context, operation string, result any) (any, error)
+	// A function for pre-processing start operation request bodies before they are read by the [Handler], e.g. for
+	// decrypting or decompressing inputs or converting inputs of legacy formats. Receives the raw body and returns the
+	// body the handler reads, transform the body as it's read to avoid buffering large inputs. Optional.
+	//
+	// Errors returned from the function, or from reading the returned body, fail the request with a 400 status code.
+	// The body's digest, when verified with [HandlerOptions.VerifyBodyDigest], is computed over the raw body.
+	InputMiddleware func(ctx context.Context, operation string, body io.Reader) (io.Reader, error)
+	// If set, [StartOperationRequest.ReadJSON] rejects inputs with fields that are unknown to the target type with a
+	// 400 status code, surfacing client bugs that would otherwise be masked.
+	//
+	// Defaults to false, in which case unknown fields are ignored.
+	DisallowUnknownFields bool
+	// If set, [StartOperationRequest.ReadJSON] decodes numbers into interface values, e.g. in a map[string]any, as
+	// [json.Number] rather than float64, preserving the precision of integers beyond 2^53, such as large IDs.
+	//
+	// Defaults to false for compatibility, in which case numbers decoded into interface values are float64.
+	UseNumber bool
+	// If set, concurrent start operation requests for the same operation with the same request ID are coalesced: while
+	// the first request is being handled, subsequent requests block and receive its outcome instead of invoking the
+	// Handler, preventing concurrent retries of a slow start from executing twice. Requests without a request ID are
+	// not coalesced.
+	//
+	// Successful synchronous results are buffered in memory to be written to each caller. Coalescing only applies to
+	// concurrent requests, requests arriving after the first one completed invoke the Handler as usual. If the first
+	// request fails after its client disconnected, the next blocked request invokes the Handler instead of failing too.
+	CoalesceStartRequests bool
+	// Max length of request URIs (path and query). Requests with longer URIs are rejected with a 414 status code.
+	// Optional.
+	//
+	// Defaults to no limit beyond what the HTTP server itself enforces.
+	MaxURLLength int
+	// If set, requests from callers that did not present a TLS client certificate are rejected with a 401 status
+	// code. The caller's identity is available to the [Handler] via [ClientIdentityFromContext] regardless of this
+	// option.
+	//
+	// Note that certificates are verified by the server per its [crypto/tls.Config], not by the handler.
+	RequireClientCert bool
+	// Content type assumed for start operation requests with a body but no Content-Type header, set on the request
+	// before it is passed to the [Handler]. Optional.
+	//
+	// Defaults to no assumption, in which case [StartOperationRequest.ReadJSON] treats such bodies as JSON.
+	DefaultContentType string
+	// If set, start operation requests with a body but no Content-Type header are rejected with a 400 status code.
+	// Mutually exclusive with DefaultContentType.
+	RequireContentType bool
+	// Bounds the number of concurrent [Handler] invocations, queuing excess requests up to a bound and rejecting
+	// requests once the queue is full with a 503 status code and a Retry-After header. Useful for protecting CPU bound
+	// handlers. Optional.
+	//
+	// Note that long poll get result requests and event streams occupy a slot for their entire duration.
+	Concurrency *ConcurrencyOptions
+	// Operations being sunset, keyed by operation name. Responses for deprecated operations, including failures, carry a
+	// Deprecation header, a Sunset header when a sunset time is set, and the deprecation message as a warning, helping
+	// drive callers to migrate without breaking them. Clients log a warning the first time they get such a response
+	// for an operation, callers may also check individual responses with [ResponseDeprecation]. Optional.
+	DeprecatedOperations map[string]OperationDeprecation
+	// Codecs for serializing the results of specific operations, keyed by operation name, in order of preference.
+	// Allows operations to support different content types, e.g. an export operation responding with image/png only
+	// while a data operation responds with JSON or protobuf. Optional.
+	//
+	// Start operation and get operation result requests for these operations are negotiated against the request's
+	// Accept header and fail with a 406 status code, before invoking the [Handler], if none of the operation's content
+	// types are acceptable. The negotiated content type is exposed to the handler in
+	// [StartOperationRequest.ResponseContentType] and [GetOperationResultRequest.ResponseContentType]. Results
+	// constructed with [NewOperationResponseSync] or [NewOperationResponseSyncStream] are serialized with the
+	// negotiated codec, other results are written as is. Use [JSONResultCodec] to include JSON.
+	OperationCodecs map[string][]ResultCodec
+	// Report the time spent processing each request, up to writing the response headers, in the Nexus-Handler-Duration
+	// response header. Lets callers tell network latency apart from handler latency, see [ServerProcessingTime].
+	// Optional.
+	ReportHandlerDuration bool
+	// Called when a request is routed to a [Handler] method that isn't implemented, i.e. falls back to the embedded
+	// [UnimplementedHandler], with the operation name, if known, and the Handler method name, e.g. "CancelOperation".
+	// Useful for finding operations that were left unimplemented by mistake, e.g. by logging a warning. Optional.
+	OnUnimplemented func(operation, method string)
+	// Status code to respond with to requests routed to unimplemented [Handler] methods, e.g. 404 to hide the existence
+	// of the operation or 405 to indicate the operation doesn't support the method. Must be a 4xx or 5xx status code.
+	// Optional, defaults to 501.
+	UnimplementedStatusCode int
+	// A tracer for starting a server span around each request, named after the [Handler] method serving the request,
+	// e.g. "nexus.StartOperation". The caller's trace context is extracted from the W3C traceparent header, see
+	// [TraceContextFromContext]. Handler methods receive a context holding the server span, use [StartSpan] to start
+	// child spans. Optional.
+	Tracer Tracer
+	// Operation level middleware wrapping the Handler, the first middleware being the outermost. Middleware run after
+	// the URL path is parsed and the request is decoded, receiving the operation name and the request options in the
+	// Handler method's request, allowing per operation decisions before delegating to the wrapped Handler. Middleware
+	// typically embed the wrapped Handler and override some of its methods. Optional.
+	//
+	// Wrapping the Handler hides its [OperationLister] implementation from middleware, [HandlerOptions.ExposeOperations]
+	// always lists the operations of the unwrapped Handler.
+	Middleware []func(Handler) Handler
+	// HTTP level middleware wrapping the [http.Handler] returned from [NewHTTPHandler], the first middleware being the
+	// outermost. Middleware run before the URL path is parsed and before any of the other options are applied, seeing
+	// every request including those rejected by the handler, e.g. for logging, authentication or request ID
+	// propagation. Optional.
+	HTTPMiddleware []func(http.Handler) http.Handler
+	// Resolves the operation name of a request, overriding the operation path segment, for fronting the handler with
+	// gateways that carry the operation in a header or the request body. Requests must still match the handler's
+	// routes, the operation path segment is parsed but ignored. Used for start, get result, get info, cancel and stream
+	// events requests. Optional, defaults to taking the operation from the URL path.
+	//
+	// Errors fail the request with a bad request status code, unless they wrap a [HandlerError]. Resolvers reading
+	// the body of start requests must replace it for the [Handler] to read the input.
+	OperationResolver func(*http.Request) (operation string, err error)
+	// Disable recovering from panics in route handlers, including [Handler] methods. By default, requests whose
+	// handling panics are responded to with a generic internal server error, or aborted if the response headers were
+	// already written, and the panic is logged along with its stack trace. Disable to handle panics in HTTP level
+	// middleware instead.
+	DisablePanicRecovery bool
+	// Validates operation names before dispatching requests to the [Handler], hardening the handler against
+	// malicious names, e.g. names with control characters injected into logs. Requests with names failing validation
+	// are rejected with a bad request status code, the validation error is included in the failure message and should
+	// not echo the name. Names are validated after being lowercased when CaseInsensitiveOperations is set. Optional,
+	// defaults to [DefaultOperationNameValidator].
+	OperationNameValidator func(operation string) error
+	// Max size of start operation request bodies in bytes. Requests with larger bodies are rejected with a bad request
+	// status code, either upfront based on their Content-Length header or once the [Handler] reads past the limit.
+	// Optional, bodies are unlimited by default. Also applies to bulk cancelation requests, which are otherwise limited
+	// to 64 KiB.
+	MaxRequestBodyBytes int64
+	// Receives metrics for each request routed to a [Handler] method, including whether the request missed the
+	// deadline requested by the caller in the Request-Timeout or Nexus-Operation-Timeout header. Optional.
+	MetricsHandler HandlerMetricsHandler
+}
+
+// validate checks that the options are valid, returning an error describing the first invalid option.
+func (o *HandlerOptions) validate() error {
+	if o.Handler == nil {
+		return errors.New("nexus: HandlerOptions.Handler is required")
+	}
+	if o.GetResultTimeout < 0 {
+		return fmt.Errorf("nexus: HandlerOptions.GetResultTimeout must not be negative, got: %v", o.GetResultTimeout)
+	}
+	if o.MaxRequestBodyBytes < 0 {
+		return fmt.Errorf("nexus: HandlerOptions.MaxRequestBodyBytes must not be negative, got: %d", o.MaxRequestBodyBytes)
+	}
+	if _, ok := o.Handler.(OperationLister); o.ExposeOperations && !ok {
+		return errors.New("nexus: HandlerOptions.ExposeOperations requires a Handler that implements OperationLister")
+	}
+	if o.DefaultContentType != "" && o.RequireContentType {
+		return errors.New("nexus: HandlerOptions.DefaultContentType and RequireContentType are mutually exclusive")
+	}
+	if o.Concurrency != nil && (o.Concurrency.MaxConcurrent <= 0 || o.Concurrency.MaxQueued < 0) {
+		return fmt.Errorf("nexus: HandlerOptions.Concurrency.MaxConcurrent must be positive and MaxQueued must not be negative, got: %d and %d", o.Concurrency.MaxConcurrent, o.Concurrency.MaxQueued)
+	}
+	if o.DefaultContentType != "" {
+		if _, _, err := mime.ParseMediaType(o.DefaultContentType); err != nil {
+			return fmt.Errorf("nexus: invalid HandlerOptions.DefaultContentType: %w", err)
+		}
+	}
+	for _, middleware := range o.Middleware {
+		if middleware == nil {
+			return errors.New("nexus: HandlerOptions.Middleware must not contain nil middleware")
+		}
+	}
+	for _, middleware := range o.HTTPMiddleware {
+		if middleware == nil {
+			return errors.New("nexus: HandlerOptions.HTTPMiddleware must not contain nil middleware")
+		}
+	}
+	if o.UnimplementedStatusCode != 0 && (o.UnimplementedStatusCode < 400 || o.UnimplementedStatusCode > 599) {
+		return fmt.Errorf("nexus: HandlerOptions.UnimplementedStatusCode must be a 4xx or 5xx status code, got %d", o.UnimplementedStatusCode)
+	}
+	for operation, codecs := range o.OperationCodecs {
+		if len(codecs) == 0 {
+			return fmt.Errorf("nexus: HandlerOptions.OperationCodecs must not be empty for operation %q", operation)
+		}
+		for _, codec := range codecs {
+			if codec == nil {
+				return fmt.Errorf("nexus: HandlerOptions.OperationCodecs must not contain nil codecs for operation %q", operation)
+			}
+		}
+	}
+	return nil
 }
 
 // NewHTTPHandler constructs an [http.Handler] from given options for handling Nexus service requests.
+//
+// Panics if the options are invalid, e.g. if Handler is nil, to fail fast on misconfiguration rather than on the first
+// request.
 func NewHTTPHandler(options HandlerOptions) http.Handler {
+	if err := options.validate(); err != nil {
+		panic(err)
+	}
 	if options.Logger == nil {
 		options.Logger = slog.Default()
 	}
 	if options.GetResultTimeout == 0 {
 		options.GetResultTimeout = time.Minute
 	}
+	lister, _ := options.Handler.(OperationLister)
+	options.Handler = applyMiddleware(options.Handler, options.Middleware)
 	handler := &httpHandler{
 		baseHTTPHandler: baseHTTPHandler{
-			logger: slog.Default(),
+			logger:           options.Logger,
+			errorDetailsFunc: options.ErrorDetailsFunc,
+			failureCodec:     options.FailureCodec,
 		},
-		options: options,
+		options:         options,
+		operationLister: lister,
+	}
+	if options.CoalesceStartRequests {
+		handler.coalescer = newStartCoalescer()
+	}
+	handler.operationCodecs = normalizeOperationCodecs(options)
+	if len(options.DeprecatedOperations) > 0 {
+		handler.deprecatedOperations = make(map[string]OperationDeprecation, len(options.DeprecatedOperations))
+		for operation, deprecation := range options.DeprecatedOperations {
+			if options.CaseInsensitiveOperations {
+				operation = strings.ToLower(operation)
+			}
+			handler.deprecatedOperations[operation] = deprecation
+		}
+	}
+
+	// Don't clean paths, mux would otherwise redirect e.g. /op//result to /op/result, which is a valid get operation
+	// info path. Empty path segments in result and cancel paths are instead rejected when parsing the path.
+	router := mux.NewRouter().UseEncodedPath().SkipClean(true)
+	var prefix string
+	if options.ServiceRouting {
+		prefix = "/{service}"
 	}
+	if options.RootHandler != nil {
+		router.Handle("/", options.RootHandler)
+	}
+	if options.ExposeOperations {
+		router.HandleFunc(operationsPath, handler.listOperations).Methods("GET").Name("ListOperations")
+	}
+	// Registered before the start operation route, which would otherwise match the path.
+	router.HandleFunc(prefix+cancelOperationsPath, handler.cancelOperations).Methods("POST").Name("CancelOperations")
+	router.HandleFunc(prefix+"/{operation}", handler.startOperation).Methods("POST").Name("StartOperation")
+	router.HandleFunc(prefix+"/{operation}", handler.lookupOperation).Methods("GET").Queries(queryRequestID, "{request_id}").Name("LookupOperation")
+	router.HandleFunc(prefix+"/{operation}/{operation_id}", handler.getOperationInfo).Methods("GET").Name("GetOperationInfo")
+	router.HandleFunc(prefix+"/{operation:[^/]*}/{operation_id:[^/]*}/result", handler.getOperationResult).Methods("GET").Name("GetOperationResult")
+	router.HandleFunc(prefix+"/{operation:[^/]*}/{operation_id:[^/]*}/cancel", handler.cancelOperation).Methods("POST").Name("CancelOperation")
+	router.HandleFunc(prefix+"/{operation:[^/]*}/{operation_id:[^/]*}/events", handler.streamOperationEvents).Methods("GET").Name("StreamOperationEvents")
+	if options.MetricsHandler != nil {
+		router.Use(handler.recordRequestMetrics)
+	}
+	if options.Tracer != nil {
+		router.Use(handler.withTracing)
+	}
+	if !options.DisablePanicRecovery {
+		router.Use(handler.recoverPanics)
+	}
+	var root http.Handler = router
+	if options.Concurrency != nil {
+		root = handler.limitConcurrency(root)
+	}
+	if options.TrimTrailingSlash {
+		root = trimTrailingSlash(root)
+	}
+	if options.MaxURLLength > 0 {
+		root = handler.limitURLLength(root)
+	}
+	root = withRequestAttributes(withRequestIDEcho(withBaggage(handler.withClientIdentity(root))))
+	if options.ReportHandlerDuration {
+		root = withHandlerDuration(root)
+	}
+	return applyHTTPMiddleware(root, options.HTTPMiddleware)
+}
 
-	router := mux.NewRouter().UseEncodedPath()
-	router.HandleFunc("/{operation}", handler.startOperation).Methods("POST")
-	router.HandleFunc("/{operation}/{operation_id}", handler.getOperationInfo).Methods("GET")
-	router.HandleFunc("/{operation}/{operation_id}/result", handler.getOperationResult).Methods("GET")
-	router.HandleFunc("/{operation}/{operation_id}/cancel", handler.cancelOperation).Methods("POST")
-	return router
+// withRequestIDEcho wraps an [http.Handler], echoing the request's Nexus-Request-Id header on the response, including
+// failure responses, for correlating requests and responses end to end.
+func withRequestIDEcho(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if requestID := request.Header.Get(headerRequestID); requestID != "" {
+			writer.Header().Set(headerRequestID, requestID)
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// limitURLLength wraps an [http.Handler], rejecting requests with URIs longer than [HandlerOptions.MaxURLLength].
+func (h *httpHandler) limitURLLength(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if length := len(request.URL.RequestURI()); length > h.options.MaxURLLength {
+			h.writeFailure(writer, &HandlerError{
+				StatusCode: http.StatusRequestURITooLong,
+				Failure:    &Failure{Message: fmt.Sprintf("request URI length %d exceeds max length of %d", length, h.options.MaxURLLength)},
+			})
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// trimTrailingSlash wraps an [http.Handler], trimming a single trailing slash from the request URL path before
+// delegating.
+func trimTrailingSlash(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if len(request.URL.Path) > 1 && strings.HasSuffix(request.URL.Path, "/") {
+			r := new(http.Request)
+			*r = *request
+			r.URL = new(url.URL)
+			*r.URL = *request.URL
+			r.URL.Path = strings.TrimSuffix(request.URL.Path, "/")
+			r.URL.RawPath = strings.TrimSuffix(request.URL.RawPath, "/")
+			request = r
+		}
+		handler.ServeHTTP(writer, request)
+	})
 }
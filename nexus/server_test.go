@@ -1,12 +1,18 @@
 package nexus
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,6 +49,58 @@ func TestWriteFailure_HandlerError(t *testing.T) {
 	require.Equal(t, "foo", failure.Message)
 }
 
+func TestWriteFailure_HandlerErrorWithHeader(t *testing.T) {
+	h := baseHTTPHandler{
+		logger: slog.Default(),
+	}
+
+	writer := httptest.NewRecorder()
+	h.writeFailure(writer, &HandlerError{
+		StatusCode: http.StatusTooManyRequests,
+		Failure:    &Failure{Message: "slow down"},
+		Header:     http.Header{"Retry-After": []string{"10"}},
+	})
+
+	require.Equal(t, http.StatusTooManyRequests, writer.Code)
+	require.Equal(t, "10", writer.Header().Get("Retry-After"))
+	require.Equal(t, contentTypeJSON, writer.Header().Get(headerContentType))
+}
+
+func TestWriteFailure_ErrorDetailsFunc(t *testing.T) {
+	h := baseHTTPHandler{
+		logger: slog.Default(),
+		errorDetailsFunc: func(err error) map[string]string {
+			return map[string]string{"error": err.Error(), "code": "E42"}
+		},
+	}
+
+	writer := httptest.NewRecorder()
+	h.writeFailure(writer, fmt.Errorf("wrapped: %w", errors.New("cause")))
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+	var failure *Failure
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+	require.Equal(t, "internal server error", failure.Message)
+	require.Equal(t, map[string]string{"error": "wrapped: cause", "code": "E42"}, failure.Metadata)
+
+	handlerFailure := &Failure{Message: "bad", Metadata: map[string]string{"code": "E1"}}
+	writer = httptest.NewRecorder()
+	h.writeFailure(writer, &HandlerError{StatusCode: http.StatusBadRequest, Failure: handlerFailure})
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+	require.Equal(t, "bad", failure.Message)
+	require.Equal(t, "E1", failure.Metadata["code"])
+	require.Contains(t, failure.Metadata["error"], "bad")
+	// The handler's failure is not modified.
+	require.Equal(t, map[string]string{"code": "E1"}, handlerFailure.Metadata)
+
+	h.errorDetailsFunc = func(err error) map[string]string { return nil }
+	writer = httptest.NewRecorder()
+	h.writeFailure(writer, fmt.Errorf("foo"))
+	failure = nil
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+	require.Nil(t, failure.Metadata)
+}
+
 func TestWriteFailure_UnsuccessfulOperationError(t *testing.T) {
 	h := baseHTTPHandler{
 		logger: slog.Default(),
@@ -62,3 +120,433 @@ func TestWriteFailure_UnsuccessfulOperationError(t *testing.T) {
 	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
 	require.Equal(t, "canceled", failure.Message)
 }
+
+type serviceEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *serviceEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Service == "" {
+		return NewOperationResponseSync(request.Operation)
+	}
+	return &OperationResponseAsync{OperationID: request.Service + "/" + request.Operation}, nil
+}
+
+func (h *serviceEchoHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	if request.OperationID != request.Service+"/"+request.Operation {
+		return nil, newBadRequestError("unexpected service: %q, operation: %q, ID: %q", request.Service, request.Operation, request.OperationID)
+	}
+	return &OperationInfo{ID: request.OperationID, State: OperationStateRunning}, nil
+}
+
+func (h *serviceEchoHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
+	if request.OperationID != request.Service+"/"+request.Operation {
+		return newBadRequestError("unexpected service: %q, operation: %q, ID: %q", request.Service, request.Operation, request.OperationID)
+	}
+	return nil
+}
+
+// recordingLogHandler is a [slog.Handler] that records the messages of all log records.
+type recordingLogHandler struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (h *recordingLogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordingLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, record.Message)
+	return nil
+}
+
+func (h *recordingLogHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordingLogHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func (h *recordingLogHandler) take() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	messages := h.messages
+	h.messages = nil
+	return messages
+}
+
+type logFailuresHandler struct {
+	UnimplementedHandler
+}
+
+func (h *logFailuresHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func (h *logFailuresHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	panic("boom")
+}
+
+func TestNewHTTPHandler_Logger(t *testing.T) {
+	logs := &recordingLogHandler{}
+	handler := NewHTTPHandler(HandlerOptions{Handler: &logFailuresHandler{}, Logger: slog.New(logs)})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+	require.Equal(t, []string{"handler failed"}, logs.take())
+
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/foo/bar/result", nil))
+	require.Equal(t, http.StatusInternalServerError, writer.Code)
+	require.Equal(t, []string{"panic while handling request"}, logs.take())
+}
+
+func TestServiceRouting(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &serviceEchoHandler{}, ServiceRouting: true}, ClientOptions{Service: "default/service"})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, "default/service/foo", result.Pending.ID)
+	require.Equal(t, "default/service", result.Pending.Service)
+	info, err := result.Pending.GetInfo(ctx, GetOperationInfoOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "default/service/foo", info.ID)
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Service: "other", Operation: "bar"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, "other/bar", result.Pending.ID)
+	require.NoError(t, result.Pending.Cancel(ctx, CancelOperationOptions{}))
+
+	handle, err := client.NewHandle("foo", "default/service/foo")
+	require.NoError(t, err)
+	require.NoError(t, handle.Cancel(ctx, CancelOperationOptions{}))
+}
+
+func TestOperationNameWithoutEscaping(t *testing.T) {
+	ctx, client, teardown := setup(t, &serviceEchoHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Successful)
+	defer result.Successful.Body.Close()
+	body, err := io.ReadAll(result.Successful.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`"foo"`), body)
+}
+
+func TestRouting_TrailingSlashAndCase(t *testing.T) {
+	type testcase struct {
+		name              string
+		options           HandlerOptions
+		path              string
+		expectedStatus    int
+		expectedOperation string
+	}
+	cases := []testcase{
+		{name: "default", path: "/op", expectedStatus: http.StatusOK, expectedOperation: "op"},
+		{name: "default with trailing slash", path: "/op/", expectedStatus: http.StatusNotFound},
+		{name: "default mixed case", path: "/Op", expectedStatus: http.StatusOK, expectedOperation: "Op"},
+		{name: "trim", options: HandlerOptions{TrimTrailingSlash: true}, path: "/op", expectedStatus: http.StatusOK, expectedOperation: "op"},
+		{name: "trim with trailing slash", options: HandlerOptions{TrimTrailingSlash: true}, path: "/op/", expectedStatus: http.StatusOK, expectedOperation: "op"},
+		{name: "trim with escaped trailing slash", options: HandlerOptions{TrimTrailingSlash: true}, path: "/o%2Fp/", expectedStatus: http.StatusOK, expectedOperation: "o/p"},
+		{name: "case insensitive", options: HandlerOptions{CaseInsensitiveOperations: true}, path: "/Op", expectedStatus: http.StatusOK, expectedOperation: "op"},
+		{name: "case insensitive with trailing slash", options: HandlerOptions{CaseInsensitiveOperations: true, TrimTrailingSlash: true}, path: "/OP/", expectedStatus: http.StatusOK, expectedOperation: "op"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.options.Handler = &serviceEchoHandler{}
+			handler := NewHTTPHandler(c.options)
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, httptest.NewRequest("POST", c.path, nil))
+			require.Equal(t, c.expectedStatus, writer.Code)
+			if c.expectedOperation != "" {
+				var operation string
+				require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &operation))
+				require.Equal(t, c.expectedOperation, operation)
+			}
+		})
+	}
+}
+
+func TestRequireRequestID(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &serviceEchoHandler{}, RequireRequestID: true})
+
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusBadRequest, writer.Code)
+	var failure *Failure
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+	require.Equal(t, "missing Nexus-Request-Id header", failure.Message)
+
+	writer = httptest.NewRecorder()
+	request := httptest.NewRequest("POST", "/foo", nil)
+	request.Header.Set(headerRequestID, "request-id")
+	handler.ServeHTTP(writer, request)
+	require.Equal(t, http.StatusOK, writer.Code)
+}
+
+func TestNewHTTPHandler_InvalidOptions(t *testing.T) {
+	require.PanicsWithError(t, "nexus: HandlerOptions.Handler is required", func() {
+		NewHTTPHandler(HandlerOptions{})
+	})
+	require.PanicsWithError(t, "nexus: HandlerOptions.GetResultTimeout must not be negative, got: -1s", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, GetResultTimeout: -time.Second})
+	})
+}
+
+func TestRouting_EmptySegments(t *testing.T) {
+	type testcase struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedError  string
+	}
+	cases := []testcase{
+		{method: "GET", path: "/op//result", expectedStatus: http.StatusBadRequest, expectedError: "empty operation ID"},
+		{method: "POST", path: "/op//cancel", expectedStatus: http.StatusBadRequest, expectedError: "empty operation ID"},
+		{method: "GET", path: "//id/result", expectedStatus: http.StatusBadRequest, expectedError: "empty operation name"},
+		{method: "POST", path: "//id/cancel", expectedStatus: http.StatusBadRequest, expectedError: "empty operation name"},
+		{method: "GET", path: "/op/", expectedStatus: http.StatusNotFound},
+		{method: "GET", path: "/op", expectedStatus: http.StatusMethodNotAllowed},
+		{method: "GET", path: "/op/id/result", expectedStatus: http.StatusOK},
+		{method: "POST", path: "/op/id/cancel", expectedStatus: http.StatusNotImplemented},
+	}
+	handler := NewHTTPHandler(HandlerOptions{Handler: &asyncWithResultHandler{}})
+	for _, c := range cases {
+		c := c
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			request := httptest.NewRequest(c.method, c.path, nil)
+			request.Header.Set(headerUserAgent, userAgent)
+			handler.ServeHTTP(writer, request)
+			require.Equal(t, c.expectedStatus, writer.Code)
+			if c.expectedError != "" {
+				var failure *Failure
+				require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+				require.Equal(t, c.expectedError, failure.Message)
+			}
+		})
+	}
+}
+
+func TestRootHandler(t *testing.T) {
+	writer := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: &serviceEchoHandler{}}).ServeHTTP(writer, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusNotFound, writer.Code)
+
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler:           &serviceEchoHandler{},
+		TrimTrailingSlash: true,
+		RootHandler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set(headerContentType, "text/plain")
+			_, _ = writer.Write([]byte("nexus"))
+		}),
+	})
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+	require.Equal(t, "nexus", writer.Body.String())
+
+	// Operation routing is unaffected.
+	writer = httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusOK, writer.Code)
+}
+
+type nilResponseHandler struct {
+	UnimplementedHandler
+}
+
+func (h *nilResponseHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	switch request.Operation {
+	case "nil":
+		return nil, nil
+	case "typed-nil":
+		var response *OperationResponseSync
+		return response, nil
+	default:
+		return &OperationResponseSync{}, nil
+	}
+}
+
+func (h *nilResponseHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return nil, nil
+}
+
+func TestNilResponse(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &nilResponseHandler{}})
+	cases := []struct {
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{method: "POST", path: "/nil", expectedStatus: http.StatusInternalServerError},
+		{method: "POST", path: "/typed-nil", expectedStatus: http.StatusInternalServerError},
+		{method: "GET", path: "/foo/id/result", expectedStatus: http.StatusInternalServerError},
+		{method: "POST", path: "/nil-body", expectedStatus: http.StatusOK},
+	}
+	for _, c := range cases {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(c.method, c.path, nil))
+		require.Equal(t, c.expectedStatus, writer.Code, c.path)
+		if c.expectedStatus == http.StatusInternalServerError {
+			var failure *Failure
+			require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &failure))
+			require.Equal(t, "internal server error", failure.Message)
+		} else {
+			require.Empty(t, writer.Body.Bytes())
+		}
+	}
+}
+
+type resultsHandler struct {
+	UnimplementedHandler
+}
+
+func (h *resultsHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	switch request.Operation {
+	case "stream":
+		return NewOperationResponseSyncStream([]string{"a", "b"}), nil
+	case "raw":
+		return &OperationResponseSync{Body: strings.NewReader("raw")}, nil
+	case "secret":
+		return NewOperationResponseSync("secret")
+	default:
+		return NewOperationResponseSync(map[string]string{"name": "foo", "token": "t0k3n"})
+	}
+}
+
+func (h *resultsHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
+	return NewOperationResponseSync(map[string]string{"token": "t0k3n"})
+}
+
+func TestResponseMiddleware(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler: &resultsHandler{},
+		ResponseMiddleware: func(ctx context.Context, operation string, result any) (any, error) {
+			switch result := result.(type) {
+			case map[string]string:
+				delete(result, "token")
+				return map[string]any{"operation": operation, "data": result}, nil
+			case []string:
+				return append(result, "c"), nil
+			case string:
+				return nil, &HandlerError{StatusCode: http.StatusForbidden, Failure: &Failure{Message: "forbidden"}}
+			}
+			return result, nil
+		},
+	})
+	cases := []struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{method: "POST", path: "/redact", expectedStatus: http.StatusOK, expectedBody: `{"data":{"name":"foo"},"operation":"redact"}`},
+		{method: "GET", path: "/get/id/result", expectedStatus: http.StatusOK, expectedBody: `{"data":{},"operation":"get"}`},
+		{method: "POST", path: "/stream", expectedStatus: http.StatusOK, expectedBody: `["a","b","c"]`},
+		{method: "POST", path: "/raw", expectedStatus: http.StatusOK, expectedBody: "raw"},
+		{method: "POST", path: "/secret", expectedStatus: http.StatusForbidden, expectedBody: `{"message":"forbidden"}`},
+	}
+	for _, c := range cases {
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, httptest.NewRequest(c.method, c.path, nil))
+		require.Equal(t, c.expectedStatus, writer.Code, c.path)
+		require.Equal(t, c.expectedBody, writer.Body.String(), c.path)
+	}
+}
+
+// closeTrackingReader records whether it was closed.
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+// failingResponseWriter fails all body writes.
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingResponseWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestOnWritten(t *testing.T) {
+	var calls []error
+	var closedBeforeCall bool
+	body := &closeTrackingReader{Reader: strings.NewReader("result")}
+	response := &OperationResponseSync{
+		Body: body,
+		OnWritten: func(err error) {
+			closedBeforeCall = body.closed
+			calls = append(calls, err)
+		},
+	}
+	handler := &httpHandler{baseHTTPHandler: baseHTTPHandler{logger: slog.Default()}}
+
+	writer := httptest.NewRecorder()
+	response.applyToHTTPResponse(writer, httptest.NewRequest("GET", "/foo/id/result", nil), handler)
+	require.Equal(t, "result", writer.Body.String())
+	require.Equal(t, []error{nil}, calls)
+	require.True(t, closedBeforeCall)
+
+	// Write failures abort the response and are reported to the finalizer.
+	calls = nil
+	body.Reader = strings.NewReader("result")
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		response.applyToHTTPResponse(failingResponseWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/foo/id/result", nil), handler)
+	})
+	require.Len(t, calls, 1)
+	require.ErrorContains(t, calls[0], "connection reset")
+
+	// Nil bodies are written successfully.
+	calls = nil
+	response.Body = nil
+	response.applyToHTTPResponse(httptest.NewRecorder(), httptest.NewRequest("GET", "/foo/id/result", nil), handler)
+	require.Equal(t, []error{nil}, calls)
+}
+
+func TestOnWritten_ResponseMiddlewareFailure(t *testing.T) {
+	var calls []error
+	handler := NewHTTPHandler(HandlerOptions{
+		Handler: &onWrittenHandler{onWritten: func(err error) { calls = append(calls, err) }},
+		ResponseMiddleware: func(ctx context.Context, operation string, result any) (any, error) {
+			return nil, &HandlerError{StatusCode: http.StatusForbidden, Failure: &Failure{Message: "forbidden"}}
+		},
+	})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusForbidden, writer.Code)
+	require.Len(t, calls, 1)
+	var handlerError *HandlerError
+	require.ErrorAs(t, calls[0], &handlerError)
+}
+
+type onWrittenHandler struct {
+	UnimplementedHandler
+	onWritten func(error)
+}
+
+func (h *onWrittenHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	response, err := NewOperationResponseSync("result")
+	if err != nil {
+		return nil, err
+	}
+	response.OnWritten = h.onWritten
+	return response, nil
+}
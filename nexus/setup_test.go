@@ -15,18 +15,25 @@ const testTimeout = time.Second * 5
 const getResultMaxTimeout = time.Millisecond * 300
 
 func setup(t *testing.T, handler Handler) (ctx context.Context, client *Client, teardown func()) {
+	return setupWithOptions(t, HandlerOptions{Handler: handler}, ClientOptions{})
+}
+
+// setupWithOptions is like setup but allows customizing the handler and client options.
+// GetResultTimeout and ServiceBaseURL are set by this function if not provided.
+func setupWithOptions(t *testing.T, handlerOptions HandlerOptions, clientOptions ClientOptions) (ctx context.Context, client *Client, teardown func()) {
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 
-	httpHandler := NewHTTPHandler(HandlerOptions{
-		GetResultTimeout: getResultMaxTimeout,
-		Handler:          handler,
-	})
+	if handlerOptions.GetResultTimeout == 0 {
+		handlerOptions.GetResultTimeout = getResultMaxTimeout
+	}
+	httpHandler := NewHTTPHandler(handlerOptions)
 
 	listener, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
-	client, err = NewClient(ClientOptions{
-		ServiceBaseURL: fmt.Sprintf("http://%s/", listener.Addr().String()),
-	})
+	if clientOptions.ServiceBaseURL == "" {
+		clientOptions.ServiceBaseURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+	}
+	client, err = NewClient(clientOptions)
 	require.NoError(t, err)
 
 	go func() {
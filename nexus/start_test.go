@@ -3,9 +3,20 @@ package nexus
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -204,3 +215,652 @@ func TestUnsuccessful(t *testing.T) {
 		require.Equal(t, OperationState(c), unsuccessfulError.State)
 	}
 }
+
+// pipelinedStartOptions constructs options for starting n operations echoing distinct inputs.
+func pipelinedStartOptions(n int) []StartOperationOptions {
+	options := make([]StartOperationOptions, n)
+	for i := range options {
+		options[i] = StartOperationOptions{
+			Operation: "foo",
+			Body:      bytes.NewReader([]byte(fmt.Sprintf("input-%d", i))),
+		}
+	}
+	return options
+}
+
+// requireEchoedInputs verifies the results of starting operations constructed with pipelinedStartOptions.
+func requireEchoedInputs(t *testing.T, results []*StartOperationResult, errs []error) {
+	for i, result := range results {
+		require.NoError(t, errs[i])
+		require.NotNil(t, result.Successful)
+		body, err := io.ReadAll(result.Successful.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Successful.Body.Close())
+		require.Equal(t, []byte(fmt.Sprintf("input-%d", i)), body)
+	}
+}
+
+// connectionCounter counts the connections a client got for its requests and how many of them were reused.
+type connectionCounter struct {
+	connections, reused atomic.Int32
+}
+
+func (c *connectionCounter) context(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.connections.Add(1)
+			if info.Reused {
+				c.reused.Add(1)
+			}
+		},
+	})
+}
+
+func TestStartOperationsPipelined(t *testing.T) {
+	// A single HTTP/1.1 connection, concurrent requests wait for it to be released.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxConnsPerHost = 1
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &bodyReadingHandler{}}, ClientOptions{
+		HTTPCaller: (&http.Client{Transport: transport}).Do,
+	})
+	defer teardown()
+	counter := &connectionCounter{}
+
+	options := pipelinedStartOptions(10)
+	results, errs := client.StartOperationsPipelined(counter.context(ctx), options)
+	require.Equal(t, len(options), len(results))
+	requireEchoedInputs(t, results, errs)
+	require.Equal(t, int32(len(options)), counter.connections.Load())
+	require.Equal(t, int32(len(options)-1), counter.reused.Load())
+}
+
+func TestStartOperationsPipelined_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(NewHTTPHandler(HandlerOptions{Handler: &bodyReadingHandler{}}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	var dialed atomic.Int32
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	dialTLS := transport.DialTLSContext
+	if dialTLS == nil {
+		dialer := &tls.Dialer{Config: transport.TLSClientConfig}
+		dialTLS = dialer.DialContext
+	}
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed.Add(1)
+		return dialTLS(ctx, network, addr)
+	}
+	client, err := NewClient(ClientOptions{ServiceBaseURL: server.URL, HTTPCaller: (&http.Client{Transport: transport}).Do})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	options := pipelinedStartOptions(10)
+	results, errs := client.StartOperationsPipelined(ctx, options)
+	requireEchoedInputs(t, results, errs)
+	for _, result := range results {
+		require.Equal(t, 2, result.Successful.ProtoMajor)
+	}
+	// All requests were multiplexed over a single connection.
+	require.Equal(t, int32(1), dialed.Load())
+}
+
+func TestStartOperationsPipelined_PartialFailure(t *testing.T) {
+	ctx, client, teardown := setup(t, &bodyReadingHandler{})
+	defer teardown()
+
+	results, errs := client.StartOperationsPipelined(ctx, []StartOperationOptions{{Operation: "foo"}, {}})
+	require.NoError(t, errs[0])
+	require.NotNil(t, results[0])
+	require.ErrorIs(t, errs[1], errEmptyOperationName)
+	require.Nil(t, results[1])
+}
+
+type streamingJSONHandler struct {
+	UnimplementedHandler
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("intentional")
+}
+
+func (h *streamingJSONHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "fail" {
+		// Fail after enough data was written for the response headers to be flushed.
+		items := make([]any, 10000)
+		for i := range items {
+			items[i] = MyItem{Index: i}
+		}
+		return NewOperationResponseSyncStream(append(items, failingMarshaler{})), nil
+	}
+	items := make([]MyItem, 1000)
+	for i := range items {
+		items[i] = MyItem{Index: i}
+	}
+	return NewOperationResponseSyncStream(items), nil
+}
+
+type MyItem struct {
+	Index int `json:"index"`
+}
+
+func TestJSONStream(t *testing.T) {
+	ctx, client, teardown := setup(t, &streamingJSONHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	require.Equal(t, contentTypeJSON, response.Header.Get(headerContentType))
+	var items []MyItem
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&items))
+	require.Equal(t, 1000, len(items))
+	require.Equal(t, 999, items[999].Index)
+}
+
+func TestJSONStream_EncodingError(t *testing.T) {
+	ctx, client, teardown := setup(t, &streamingJSONHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "fail"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+type countingMarshaler struct {
+	calls *atomic.Int32
+}
+
+func (m countingMarshaler) MarshalJSON() ([]byte, error) {
+	m.calls.Add(1)
+	return []byte(`"counted"`), nil
+}
+
+func TestJSONStream_EncodesOnRead(t *testing.T) {
+	var calls atomic.Int32
+	// Responses that are closed without being read never start encoding.
+	response := NewOperationResponseSyncStream(countingMarshaler{&calls})
+	require.NoError(t, response.Body.(io.Closer).Close())
+	_, err := response.Body.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+	require.Equal(t, int32(0), calls.Load())
+
+	response = NewOperationResponseSyncStream(countingMarshaler{&calls})
+	require.Equal(t, int32(0), calls.Load())
+	b, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, "\"counted\"\n", string(b))
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestEncodeJSONStream(t *testing.T) {
+	cases := []any{
+		nil,
+		"string",
+		[]int(nil),
+		[]int{},
+		[]int{1, 2, 3},
+		[2]string{"a", "b"},
+		[]byte("bytes"),
+		map[string]int{"a": 1},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		require.NoError(t, encodeJSONStream(&buf, c))
+		expected, err := json.Marshal(c)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), buf.String())
+	}
+}
+
+func TestAsync_Location(t *testing.T) {
+	ctx, client, teardown := setup(t, &asyncWithCancelHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "f/o/o"})
+	require.NoError(t, err)
+	handle := result.Pending
+	require.NotNil(t, handle)
+	require.NotNil(t, handle.location)
+	require.Equal(t, client.operationURL("", "f/o/o", "a/sync").String(), handle.URL().String())
+	require.Equal(t, "/f%2Fo%2Fo/a%2Fsync", handle.URL().EscapedPath())
+
+	// Handles created by the client construct the URL from the client's configuration.
+	handle, err = client.NewHandle("f/o/o", "a/sync")
+	require.NoError(t, err)
+	require.Equal(t, client.operationURL("", "f/o/o", "a/sync").String(), handle.URL().String())
+}
+
+func TestAsync_LocationWithBasePath(t *testing.T) {
+	handler := NewHTTPHandler(HandlerOptions{Handler: &asyncWithCancelHandler{}, BasePath: "/api/nexus/"})
+	writer := httptest.NewRecorder()
+	handler.ServeHTTP(writer, httptest.NewRequest("POST", "/f%2Fo%2Fo", nil))
+	require.Equal(t, http.StatusCreated, writer.Code)
+	require.Equal(t, "/api/nexus/f%2Fo%2Fo/a%2Fsync", writer.Header().Get(headerLocation))
+}
+
+type cacheControlHandler struct {
+	UnimplementedHandler
+}
+
+func (h *cacheControlHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "async" {
+		return WithCacheControl(&OperationResponseAsync{OperationID: "async"}, time.Minute), nil
+	}
+	response, err := NewOperationResponseSync("success")
+	if err != nil {
+		return nil, err
+	}
+	return WithResponseHeader(WithCacheControl(response, time.Hour), http.Header{"Foo": []string{"bar"}}), nil
+}
+
+func TestWithCacheControl(t *testing.T) {
+	ctx, client, teardown := setup(t, &cacheControlHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "sync"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	require.Equal(t, "max-age=3600", response.Header.Get("Cache-Control"))
+	require.Equal(t, "bar", response.Header.Get("Foo"))
+	require.Equal(t, contentTypeJSON, response.Header.Get(headerContentType))
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`"success"`), body)
+
+	writer := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: &cacheControlHandler{}}).ServeHTTP(writer, httptest.NewRequest("POST", "/async", nil))
+	require.Equal(t, http.StatusCreated, writer.Code)
+	require.Equal(t, "max-age=60", writer.Header().Get("Cache-Control"))
+	require.Equal(t, "/async/async", writer.Header().Get(headerLocation))
+}
+
+type priorityEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *priorityEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(request.Priority)
+}
+
+func TestStart_Priority(t *testing.T) {
+	ctx, client, teardown := setup(t, &priorityEchoHandler{})
+	defer teardown()
+
+	for _, priority := range []int{0, 3, -1} {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Priority: priority})
+		require.NoError(t, err)
+		response := result.Successful
+		require.NotNil(t, response)
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		require.NoError(t, err)
+		require.Equal(t, []byte(strconv.Itoa(priority)), body)
+	}
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Header:    http.Header{headerPriority: []string{"high"}},
+	})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+}
+
+type startAfterEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *startAfterEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(request.StartAfter)
+}
+
+func TestStart_StartAfter(t *testing.T) {
+	ctx, client, teardown := setup(t, &startAfterEchoHandler{})
+	defer teardown()
+
+	startAfter := func(options StartOperationOptions) time.Time {
+		options.Operation = "foo"
+		result, err := client.StartOperation(ctx, options)
+		require.NoError(t, err)
+		response := result.Successful
+		require.NotNil(t, response)
+		defer response.Body.Close()
+		var t0 time.Time
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&t0))
+		return t0
+	}
+
+	require.True(t, startAfter(StartOperationOptions{}).IsZero())
+	scheduled := time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+	require.True(t, scheduled.Equal(startAfter(StartOperationOptions{StartAfter: scheduled})))
+	// Durations are relative to the time the request is received.
+	before := time.Now()
+	relative := startAfter(StartOperationOptions{Header: http.Header{headerStartAfter: []string{"1h"}}})
+	require.WithinRange(t, relative, before.Add(time.Hour), time.Now().Add(time.Hour))
+
+	for _, value := range []string{"tomorrow", "-1h"} {
+		_, err := client.StartOperation(ctx, StartOperationOptions{
+			Operation: "foo",
+			Header:    http.Header{headerStartAfter: []string{value}},
+		})
+		var unexpectedError *UnexpectedResponseError
+		require.ErrorAs(t, err, &unexpectedError)
+		require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+	}
+}
+
+type idempotencyKeyEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *idempotencyKeyEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync([]string{request.RequestID, request.IdempotencyKey})
+}
+
+func TestStart_IdempotencyKey(t *testing.T) {
+	ctx, client, teardown := setup(t, &idempotencyKeyEchoHandler{})
+	defer teardown()
+
+	for _, key := range []string{"", "key"} {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: "request-id", IdempotencyKey: key})
+		require.NoError(t, err)
+		response := result.Successful
+		require.NotNil(t, response)
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		require.NoError(t, err)
+		var echoed []string
+		require.NoError(t, json.Unmarshal(body, &echoed))
+		require.Equal(t, []string{"request-id", key}, echoed)
+	}
+}
+
+type largeResultHandler struct {
+	UnimplementedHandler
+}
+
+func (h *largeResultHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return &OperationResponseSync{Body: bytes.NewReader(make([]byte, 64<<20))}, nil
+}
+
+func TestMaxResultWriteDuration(t *testing.T) {
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:                &largeResultHandler{},
+		MaxResultWriteDuration: time.Millisecond * 100,
+	}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	// Simulate a slow consumer.
+	time.Sleep(time.Millisecond * 300)
+	_, err = io.ReadAll(response.Body)
+	require.Error(t, err)
+}
+
+type contentLengthHandler struct {
+	UnimplementedHandler
+}
+
+func (h *contentLengthHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	// Hide the reader's type to prevent the server from inferring the length.
+	body := io.MultiReader(bytes.NewReader(make([]byte, 64<<10)))
+	if request.Operation == "known" {
+		return &OperationResponseSync{Body: body, ContentLength: 64 << 10}, nil
+	}
+	return &OperationResponseSync{Body: body}, nil
+}
+
+func TestContentLength(t *testing.T) {
+	ctx, client, teardown := setup(t, &contentLengthHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "known"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	require.Equal(t, int64(64<<10), response.ContentLength)
+	require.Empty(t, response.TransferEncoding)
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	require.Len(t, body, 64<<10)
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "unknown"})
+	require.NoError(t, err)
+	response = result.Successful
+	require.NotNil(t, response)
+	require.Equal(t, int64(-1), response.ContentLength)
+	require.Equal(t, []string{"chunked"}, response.TransferEncoding)
+	body, err = io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	require.Len(t, body, 64<<10)
+}
+
+type acceptedHandler struct {
+	UnimplementedHandler
+}
+
+func (h *acceptedHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return &OperationResponseAccepted{OperationID: request.Operation}, nil
+}
+
+func TestStart_Accepted(t *testing.T) {
+	ctx, client, teardown := setup(t, &acceptedHandler{})
+	defer teardown()
+
+	for _, operation := range []string{"foo", "bar"} {
+		result, err := client.StartOperation(ctx, StartOperationOptions{Operation: operation})
+		require.NoError(t, err)
+		require.Nil(t, result.Successful)
+		require.Nil(t, result.Pending)
+		require.Equal(t, &OperationAccepted{ID: operation}, result.Accepted)
+	}
+
+	_, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.ErrorIs(t, err, ErrOperationResultUnavailable)
+
+	writer := httptest.NewRecorder()
+	NewHTTPHandler(HandlerOptions{Handler: &acceptedHandler{}}).ServeHTTP(writer, httptest.NewRequest("POST", "/foo", nil))
+	require.Equal(t, http.StatusAccepted, writer.Code)
+	require.Equal(t, "false", writer.Header().Get(headerResultAvailable))
+	require.Empty(t, writer.Header().Get(headerLocation))
+}
+
+func TestStart_AcceptedWithoutID(t *testing.T) {
+	writer := httptest.NewRecorder()
+	(&OperationResponseAccepted{}).applyToHTTPResponse(writer, httptest.NewRequest("POST", "/foo", nil), &httpHandler{})
+	require.Equal(t, http.StatusAccepted, writer.Code)
+	require.Empty(t, writer.Body.Bytes())
+
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return writer.Result(), nil
+		},
+	})
+	require.NoError(t, err)
+	result, err := client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, &OperationAccepted{}, result.Accepted)
+}
+
+func TestStart_ResponseClassifier(t *testing.T) {
+	// Simulate an intermediary that rewrites 201 to 200.
+	rewritingCaller := func(request *http.Request) (*http.Response, error) {
+		response, err := http.DefaultClient.Do(request)
+		if err == nil && response.StatusCode == http.StatusCreated {
+			response.StatusCode = http.StatusOK
+		}
+		return response, err
+	}
+
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &asyncHandler{}}, ClientOptions{
+		HTTPCaller: rewritingCaller,
+	})
+	defer teardown()
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Successful)
+	result.Successful.Body.Close()
+
+	ctx, client, teardown = setupWithOptions(t, HandlerOptions{Handler: &asyncHandler{}}, ClientOptions{
+		HTTPCaller:         rewritingCaller,
+		ResponseClassifier: ClassifyByOperationIDHeader,
+	})
+	defer teardown()
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, "async", result.Pending.ID)
+
+	// Sync responses are unaffected.
+	ctx, client, teardown = setupWithOptions(t, HandlerOptions{Handler: &priorityEchoHandler{}}, ClientOptions{
+		ResponseClassifier: ClassifyByOperationIDHeader,
+	})
+	defer teardown()
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Successful)
+	result.Successful.Body.Close()
+}
+
+func TestStart_ResponseClassifierMalformedInfo(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL:     "http://example.com",
+		ResponseClassifier: ClassifyByOperationIDHeader,
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{headerOperationID: []string{"foo"}, headerContentType: []string{contentTypeJSON}},
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":"foo","state":"succeeded"}`))),
+				Request:    request,
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+	_, err = client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, `invalid operation state in response info: "succeeded"`, unexpectedError.Message)
+}
+
+type conflictingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *conflictingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	if request.Operation == "handler-error" {
+		return nil, &HandlerError{StatusCode: http.StatusConflict, Failure: &Failure{Message: "exists"}}
+	}
+	return nil, fmt.Errorf("%w: resource %q exists", ErrOperationConflict, request.RequestID)
+}
+
+func TestStart_Conflict(t *testing.T) {
+	ctx, client, teardown := setup(t, &conflictingHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo", RequestID: "abc"})
+	require.ErrorIs(t, err, ErrOperationConflict)
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusConflict, unexpectedError.Response.StatusCode)
+	require.Equal(t, `operation conflict: resource "abc" exists`, unexpectedError.Failure.Message)
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "handler-error"})
+	require.ErrorIs(t, err, ErrOperationConflict)
+
+	// Other errors do not match.
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo", Header: http.Header{headerPriority: []string{"high"}}})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrOperationConflict)
+}
+
+type clientWillPollEchoHandler struct {
+	UnimplementedHandler
+}
+
+func (h *clientWillPollEchoHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return NewOperationResponseSync(request.ClientWillPoll)
+}
+
+func TestStart_ClientWillPoll(t *testing.T) {
+	ctx, client, teardown := setup(t, &clientWillPollEchoHandler{})
+	defer teardown()
+
+	readBool := func(response *http.Response) bool {
+		defer response.Body.Close()
+		var b bool
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&b))
+		return b
+	}
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.False(t, readBool(result.Successful))
+
+	result, err = client.StartOperation(ctx, StartOperationOptions{Operation: "foo", ClientWillPoll: true})
+	require.NoError(t, err)
+	require.True(t, readBool(result.Successful))
+
+	response, err := client.ExecuteOperation(ctx, ExecuteOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	require.True(t, readBool(response))
+}
+
+func TestRequestIDEcho(t *testing.T) {
+	for _, handler := range []Handler{&priorityEchoHandler{}, &asyncHandler{}, &unsuccessfulHandler{}, &UnimplementedHandler{}} {
+		request := httptest.NewRequest("POST", "/foo", nil)
+		request.Header.Set(headerRequestID, "failed")
+		writer := httptest.NewRecorder()
+		NewHTTPHandler(HandlerOptions{Handler: handler}).ServeHTTP(writer, request)
+		require.Equal(t, "failed", writer.Header().Get(headerRequestID))
+	}
+}
+
+func TestRequestIDEcho_Mismatch(t *testing.T) {
+	var logs bytes.Buffer
+	var echo string
+	client, err := NewClient(ClientOptions{
+		ServiceBaseURL: "http://example.com",
+		Logger:         slog.New(slog.NewTextHandler(&logs, nil)),
+		HTTPCaller: func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{headerRequestID: []string{echo}},
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+				Request:    request,
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	start := func(echoed string) {
+		echo = echoed
+		result, err := client.StartOperation(context.Background(), StartOperationOptions{Operation: "foo", RequestID: "abc"})
+		require.NoError(t, err)
+		require.NoError(t, result.Successful.Body.Close())
+	}
+
+	start("abc")
+	start("")
+	require.Empty(t, logs.String())
+	start("def")
+	require.Contains(t, logs.String(), "response request ID does not match the request")
+	require.Contains(t, logs.String(), "responseRequestID=def")
+}
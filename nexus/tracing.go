@@ -0,0 +1,140 @@
+package nexus
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const headerTraceParent = "Traceparent"
+
+type traceContextKey struct{}
+
+type tracerKey struct{}
+
+// TraceContext identifies the caller's span as propagated in the W3C traceparent header.
+type TraceContext struct {
+	// Hex encoded 16 byte trace ID.
+	TraceID string
+	// Hex encoded 8 byte ID of the caller's span.
+	SpanID string
+	// Whether the caller sampled the trace.
+	Sampled bool
+}
+
+// A Span is a unit of work in a trace, see [Tracer].
+type Span interface {
+	// RecordError marks the span as failed with the given error.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// A Tracer starts spans, see [HandlerOptions.Tracer]. Implement it to bridge to a tracing library, e.g. OpenTelemetry.
+type Tracer interface {
+	// Start starts a span with the given name, returning a context holding the span. The span should be a child of the
+	// span held in ctx, if any, or of the remote caller's span, see [TraceContextFromContext].
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// TraceContextFromContext returns the caller's trace context extracted from the traceparent header of the request being
+// handled, for [Tracer] implementations to parent server spans to. Returns false if the request had no valid
+// traceparent header.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	traceContext, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return traceContext, ok
+}
+
+// StartSpan starts a span as a child of the span in ctx using the [HandlerOptions.Tracer] of the handler serving the
+// request, for instrumenting handler code without depending on a tracing library. Handler methods receive a context
+// holding the request's server span, making spans started with the context properly parented. End the returned span
+// once the work completes.
+//
+// A no-op when no tracer is configured, returning ctx and a span that does nothing.
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	tracer, ok := ctx.Value(tracerKey{}).(Tracer)
+	if !ok {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+// parseTraceParent parses a W3C traceparent header value.
+func parseTraceParent(value string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return TraceContext{}, fmt.Errorf("invalid %s header: %q", headerTraceParent, value)
+	}
+	if !isHexID(parts[1], 32) || !isHexID(parts[2], 16) || len(parts[3]) != 2 {
+		return TraceContext{}, fmt.Errorf("invalid %s header: %q", headerTraceParent, value)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return TraceContext{}, fmt.Errorf("invalid %s header: %q", headerTraceParent, value)
+	}
+	return TraceContext{TraceID: parts[1], SpanID: parts[2], Sampled: flags[0]&1 == 1}, nil
+}
+
+// isHexID returns true if id is a lowercase hex string of the given length that is not all zeros.
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, c := range id {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// withTracing is a router middleware that extracts the caller's trace context and starts a server span named after the
+// matched route, e.g. "nexus.StartOperation", around each request. Responses with 5xx status codes are recorded as
+// span errors.
+func (h *httpHandler) withTracing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := context.WithValue(request.Context(), tracerKey{}, h.options.Tracer)
+		if value := request.Header.Get(headerTraceParent); value != "" {
+			if traceContext, err := parseTraceParent(value); err == nil {
+				ctx = context.WithValue(ctx, traceContextKey{}, traceContext)
+			}
+		}
+		name := "nexus.request"
+		if route := mux.CurrentRoute(request); route != nil && route.GetName() != "" {
+			name = "nexus." + route.GetName()
+		}
+		ctx, span := h.options.Tracer.Start(ctx, name)
+		defer span.End()
+		recorder := &statusRecordingWriter{ResponseWriter: writer, statusCode: http.StatusOK}
+		handler.ServeHTTP(recorder, request.WithContext(ctx))
+		if recorder.statusCode >= 500 {
+			span.RecordError(fmt.Errorf("request failed with status %d", recorder.statusCode))
+		}
+	})
+}
+
+// statusRecordingWriter records the status code of a response.
+type statusRecordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusRecordingWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap returns the underlying writer for [http.ResponseController].
+func (w *statusRecordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
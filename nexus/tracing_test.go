@@ -0,0 +1,157 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	name   string
+	parent string
+	err    error
+	ended  bool
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *fakeSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended = true
+}
+
+// fakeTracer records started spans, parenting spans to the span in the context or the remote caller's span.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{tracer: t, name: name}
+	if parent, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		span.parent = parent.name
+	} else if traceContext, ok := TraceContextFromContext(ctx); ok {
+		span.parent = "remote:" + traceContext.SpanID
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// take waits for the given number of spans to end and returns them. Server spans end after the response is sent.
+func (t *fakeTracer) take(tt *testing.T, count int) []fakeSpan {
+	var spans []fakeSpan
+	require.Eventually(tt, func() bool {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if len(t.spans) != count {
+			return false
+		}
+		spans = nil
+		for _, span := range t.spans {
+			if !span.ended {
+				return false
+			}
+			copied := *span
+			copied.tracer = nil
+			spans = append(spans, copied)
+		}
+		t.spans = nil
+		return true
+	}, time.Second, time.Millisecond)
+	return spans
+}
+
+type tracingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *tracingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	_, span := StartSpan(ctx, "downstream")
+	defer span.End()
+	if request.Operation == "fail" {
+		err := errors.New("downstream failed")
+		span.RecordError(err)
+		return nil, err
+	}
+	return NewOperationResponseSync("ok")
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &fakeTracer{}
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{Handler: &tracingHandler{}, Tracer: tracer}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{
+		Operation: "foo",
+		Header:    http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, result.Discard())
+	require.Equal(t, []fakeSpan{
+		{name: "nexus.StartOperation", parent: "remote:00f067aa0ba902b7", ended: true},
+		{name: "downstream", parent: "nexus.StartOperation", ended: true},
+	}, tracer.take(t, 2))
+
+	_, err = client.StartOperation(ctx, StartOperationOptions{Operation: "fail"})
+	require.Error(t, err)
+	spans := tracer.take(t, 2)
+	require.Equal(t, "", spans[0].parent)
+	require.ErrorContains(t, spans[0].err, "status 500")
+	require.ErrorContains(t, spans[1].err, "downstream failed")
+
+	handle, err := client.NewHandle("foo", "bar")
+	require.NoError(t, err)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.Error(t, err)
+	spans = tracer.take(t, 1)
+	require.Equal(t, "nexus.GetOperationInfo", spans[0].name)
+	require.ErrorContains(t, spans[0].err, "status 501")
+}
+
+func TestStartSpan_NoTracer(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := StartSpan(ctx, "foo")
+	require.Equal(t, ctx, spanCtx)
+	span.RecordError(errors.New("ignored"))
+	span.End()
+}
+
+func TestParseTraceParent(t *testing.T) {
+	traceContext, err := parseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	require.Equal(t, TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}, traceContext)
+
+	// Future versions may append fields.
+	traceContext, err = parseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	require.NoError(t, err)
+	require.False(t, traceContext.Sampled)
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	} {
+		_, err := parseTraceParent(value)
+		require.Error(t, err, value)
+	}
+}
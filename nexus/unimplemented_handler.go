@@ -14,20 +14,57 @@ func (h *UnimplementedHandler) mustEmbedUnimplementedHandler() {}
 
 // StartOperation implements the Handler interface.
 func (h *UnimplementedHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
-	return nil, &HandlerError{http.StatusNotImplemented, &Failure{Message: "not implemented"}}
+	return nil, newUnimplementedError()
 }
 
 // GetOperationResult implements the Handler interface.
 func (h *UnimplementedHandler) GetOperationResult(ctx context.Context, request *GetOperationResultRequest) (*OperationResponseSync, error) {
-	return nil, &HandlerError{http.StatusNotImplemented, &Failure{Message: "not implemented"}}
+	return nil, newUnimplementedError()
 }
 
 // GetOperationInfo implements the Handler interface.
 func (h *UnimplementedHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
-	return nil, &HandlerError{http.StatusNotImplemented, &Failure{Message: "not implemented"}}
+	return nil, newUnimplementedError()
 }
 
 // CancelOperation implements the Handler interface.
 func (h *UnimplementedHandler) CancelOperation(ctx context.Context, request *CancelOperationRequest) error {
-	return &HandlerError{http.StatusNotImplemented, &Failure{Message: "not implemented"}}
+	return newUnimplementedError()
+}
+
+// StreamOperationEvents implements the Handler interface.
+func (h *UnimplementedHandler) StreamOperationEvents(ctx context.Context, request *StreamOperationEventsRequest) (<-chan OperationEvent, error) {
+	return nil, newUnimplementedError()
+}
+
+// CancelOperations implements the Handler interface.
+func (h *UnimplementedHandler) CancelOperations(ctx context.Context, request *CancelOperationsRequest) (*CancelResult, error) {
+	return nil, newUnimplementedError()
+}
+
+// LookupOperation implements the Handler interface.
+func (h *UnimplementedHandler) LookupOperation(ctx context.Context, request *LookupOperationRequest) (*OperationInfo, error) {
+	return nil, newUnimplementedError()
+}
+
+// newUnimplementedError creates the error returned from UnimplementedHandler methods, see
+// [HandlerOptions.OnUnimplemented].
+func newUnimplementedError() *HandlerError {
+	return &HandlerError{StatusCode: http.StatusNotImplemented, Failure: &Failure{Message: "not implemented"}, unimplemented: true}
+}
+
+// checkUnimplemented reports errors returned from UnimplementedHandler methods to [HandlerOptions.OnUnimplemented] and
+// applies [HandlerOptions.UnimplementedStatusCode]. Other errors are returned as is.
+func (h *httpHandler) checkUnimplemented(err error, operation, method string) error {
+	handlerError, ok := err.(*HandlerError)
+	if !ok || !handlerError.unimplemented {
+		return err
+	}
+	if h.options.OnUnimplemented != nil {
+		h.options.OnUnimplemented(operation, method)
+	}
+	if h.options.UnimplementedStatusCode != 0 {
+		handlerError.StatusCode = h.options.UnimplementedStatusCode
+	}
+	return handlerError
 }
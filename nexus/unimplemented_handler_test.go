@@ -0,0 +1,76 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type partialHandler struct {
+	UnimplementedHandler
+}
+
+func (h *partialHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return &OperationResponseAsync{OperationID: "a/sync"}, nil
+}
+
+func (h *partialHandler) GetOperationInfo(ctx context.Context, request *GetOperationInfoRequest) (*OperationInfo, error) {
+	// Not an UnimplementedHandler error, should not be reported.
+	return nil, &HandlerError{StatusCode: http.StatusNotImplemented, Failure: &Failure{Message: "not implemented"}}
+}
+
+func TestOnUnimplemented(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][2]string
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler: &partialHandler{},
+		OnUnimplemented: func(operation, method string) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, [2]string{operation, method})
+		},
+		UnimplementedStatusCode: http.StatusNotFound,
+	}, ClientOptions{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	handle := result.Pending
+
+	var unexpectedError *UnexpectedResponseError
+	_, err = handle.GetResult(ctx, GetOperationResultOptions{})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotFound, unexpectedError.Response.StatusCode)
+	err = handle.Cancel(ctx, CancelOperationOptions{})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotFound, unexpectedError.Response.StatusCode)
+	_, err = handle.GetInfo(ctx, GetOperationInfoOptions{})
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedError.Response.StatusCode)
+
+	require.Equal(t, [][2]string{{"foo", "GetOperationResult"}, {"foo", "CancelOperation"}}, calls)
+}
+
+func TestOnUnimplemented_DefaultStatus(t *testing.T) {
+	var methods []string
+	ctx, client, teardown := setupWithOptions(t, HandlerOptions{
+		Handler:         &UnimplementedHandler{},
+		OnUnimplemented: func(operation, method string) { methods = append(methods, method) },
+	}, ClientOptions{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusNotImplemented, unexpectedError.Response.StatusCode)
+	require.Equal(t, []string{"StartOperation"}, methods)
+}
+
+func TestUnimplementedStatusCode_Invalid(t *testing.T) {
+	require.PanicsWithError(t, "nexus: HandlerOptions.UnimplementedStatusCode must be a 4xx or 5xx status code, got 200", func() {
+		NewHTTPHandler(HandlerOptions{Handler: &UnimplementedHandler{}, UnimplementedStatusCode: http.StatusOK})
+	})
+}
@@ -0,0 +1,67 @@
+package nexus
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Failure metadata marking failures that carry validation errors in their details.
+const (
+	failureMetadataType      = "type"
+	failureTypeValidation    = "validation"
+	validationFailureMessage = "validation failed"
+)
+
+// FieldViolation describes a validation failure of a single input field.
+type FieldViolation struct {
+	// Path of the invalid field, e.g. "address.zip".
+	Field string `json:"field"`
+	// Description of why the field is invalid.
+	Message string `json:"message"`
+}
+
+// ValidationError reports field level input validation failures.
+//
+// Return a ValidationError from a [Handler] method to fail the request with a 400 status code. The violations are
+// serialized in the response's [Failure] details in a well-known shape, and parsed back by the client into a
+// *ValidationError that can be extracted from the returned error with [errors.As].
+type ValidationError struct {
+	Violations []FieldViolation `json:"violations"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return validationFailureMessage
+	}
+	violations := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		violations[i] = violation.Field + ": " + violation.Message
+	}
+	return validationFailureMessage + ": " + strings.Join(violations, "; ")
+}
+
+func (e *ValidationError) failure() (*Failure, error) {
+	details, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &Failure{
+		Message:  e.Error(),
+		Metadata: map[string]string{failureMetadataType: failureTypeValidation},
+		Details:  details,
+	}, nil
+}
+
+// validationErrorFromFailure parses a [ValidationError] from a failure marked as a validation failure, returning nil
+// if the failure isn't one or its details are malformed.
+func validationErrorFromFailure(failure *Failure) *ValidationError {
+	if failure == nil || failure.Metadata[failureMetadataType] != failureTypeValidation {
+		return nil
+	}
+	var validationError ValidationError
+	if err := json.Unmarshal(failure.Details, &validationError); err != nil {
+		return nil
+	}
+	return &validationError
+}
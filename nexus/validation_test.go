@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type validatingHandler struct {
+	UnimplementedHandler
+}
+
+func (h *validatingHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	return nil, fmt.Errorf("invalid input: %w", &ValidationError{Violations: []FieldViolation{
+		{Field: "name", Message: "must not be empty"},
+		{Field: "address.zip", Message: "invalid format"},
+	}})
+}
+
+func TestValidationError(t *testing.T) {
+	ctx, client, teardown := setup(t, &validatingHandler{})
+	defer teardown()
+
+	_, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	var unexpectedError *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpectedError)
+	require.Equal(t, http.StatusBadRequest, unexpectedError.Response.StatusCode)
+	require.Equal(t, "validation failed: name: must not be empty; address.zip: invalid format", unexpectedError.Failure.Message)
+
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	require.Equal(t, []FieldViolation{
+		{Field: "name", Message: "must not be empty"},
+		{Field: "address.zip", Message: "invalid format"},
+	}, validationError.Violations)
+}
+
+func TestValidationErrorFromFailure(t *testing.T) {
+	require.Nil(t, validationErrorFromFailure(nil))
+	require.Nil(t, validationErrorFromFailure(&Failure{Message: "foo"}))
+	require.Nil(t, validationErrorFromFailure(&Failure{
+		Metadata: map[string]string{failureMetadataType: failureTypeValidation},
+		Details:  []byte("not json"),
+	}))
+
+	// Unrelated failures are not parsed as validation errors.
+	err := (&Client{}).newUnexpectedResponseError("foo", &http.Response{Header: http.Header{}}, nil)
+	require.False(t, errors.As(err, new(*ValidationError)))
+}
@@ -0,0 +1,51 @@
+package nexus
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	waitForStateInitialInterval = time.Millisecond * 50
+	waitForStateMaxInterval     = time.Second
+)
+
+// WaitForState is a helper for implementing long polls in Handler.GetOperationResult. It calls poll until the returned
+// operation information has a terminal state (succeeded, failed, or canceled) and returns that information.
+//
+// Polling stops once wait elapses or ctx is done, whichever comes first, returning [ErrOperationStillRunning]. The
+// handler's context is bounded by [HandlerOptions.GetResultTimeout] and the client's request timeout, while wait is the
+// caller's requested wait duration ([GetOperationResultRequest.Wait]), which may be longer. A non positive wait polls
+// exactly once.
+//
+// Polls are spaced with an exponential backoff starting at 50ms, capped at one second. Errors returned from poll are
+// returned as is.
+func WaitForState(ctx context.Context, poll func() (*OperationInfo, error), wait time.Duration) (*OperationInfo, error) {
+	deadline := time.Now().Add(wait)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	interval := waitForStateInitialInterval
+	for {
+		info, err := poll()
+		if err != nil {
+			return nil, err
+		}
+		switch info.State {
+		case OperationStateSucceeded, OperationStateFailed, OperationStateCanceled:
+			return info, nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, ErrOperationStillRunning
+		}
+		timer := time.NewTimer(min(interval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ErrOperationStillRunning
+		case <-timer.C:
+		}
+		interval = min(interval*2, waitForStateMaxInterval)
+	}
+}
@@ -0,0 +1,58 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForState(t *testing.T) {
+	polls := 0
+	info, err := WaitForState(context.Background(), func() (*OperationInfo, error) {
+		polls++
+		if polls < 3 {
+			return &OperationInfo{State: OperationStateRunning}, nil
+		}
+		return &OperationInfo{State: OperationStateSucceeded}, nil
+	}, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, OperationStateSucceeded, info.State)
+	require.Equal(t, 3, polls)
+}
+
+func TestWaitForState_WaitElapsed(t *testing.T) {
+	running := func() (*OperationInfo, error) {
+		return &OperationInfo{State: OperationStateRunning}, nil
+	}
+	startTime := time.Now()
+	_, err := WaitForState(context.Background(), running, time.Millisecond*100)
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.GreaterOrEqual(t, time.Since(startTime), time.Millisecond*100)
+
+	// The context deadline bounds the wait.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	startTime = time.Now()
+	_, err = WaitForState(ctx, running, time.Minute)
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Less(t, time.Since(startTime), time.Second)
+
+	polls := 0
+	_, err = WaitForState(context.Background(), func() (*OperationInfo, error) {
+		polls++
+		return running()
+	}, 0)
+	require.ErrorIs(t, err, ErrOperationStillRunning)
+	require.Equal(t, 1, polls)
+}
+
+func TestWaitForState_PollError(t *testing.T) {
+	pollErr := errors.New("poll failed")
+	_, err := WaitForState(context.Background(), func() (*OperationInfo, error) {
+		return nil, pollErr
+	}, time.Second)
+	require.ErrorIs(t, err, pollErr)
+}
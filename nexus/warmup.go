@@ -0,0 +1,65 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// Warmup establishes connections to the service ahead of the first operation request, so that it doesn't pay for
+// connecting and the TLS handshake, e.g. when a serverless function cold starts. A single OPTIONS request is issued to
+// each of [ClientOptions.ServiceBaseURL] or [ClientOptions.ServiceBaseURLs] concurrently. Any response indicates a
+// connection was established, only failures to get a response are returned.
+//
+// Established connections are kept idle in the connection pool of the transport behind [ClientOptions.HTTPCaller]
+// until used or closed. The pool closes idle connections after its idle timeout, 90 seconds for
+// [net/http.DefaultTransport], call Warmup periodically at a shorter interval to keep connections warm through idle
+// periods. Warmup has no effect with callers that don't pool connections.
+//
+// When load balancing across ServiceBaseURLs, warmup requests count towards the health of each instance: a failure to
+// connect counts as a failed request, see [LoadBalancingOptions].
+func (c *Client) Warmup(ctx context.Context) error {
+	baseURLs := []*url.URL{c.serviceBaseURL}
+	if c.balancer != nil {
+		baseURLs = c.balancer.baseURLs()
+	}
+	errs := make([]error, len(baseURLs))
+	var wg sync.WaitGroup
+	for i, baseURL := range baseURLs {
+		wg.Add(1)
+		go func(i int, baseURL *url.URL) {
+			defer wg.Done()
+			if err := c.warmup(ctx, baseURL); err != nil {
+				errs[i] = fmt.Errorf("failed to warm up connection to %s: %w", baseURL.Host, err)
+			}
+		}(i, baseURL)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (c *Client) warmup(ctx context.Context, baseURL *url.URL) error {
+	u := *baseURL
+	if err := c.transformURL(&u); err != nil {
+		return err
+	}
+	request, err := c.newRequest(ctx, "", "OPTIONS", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set(headerUserAgent, userAgent)
+	response, err := c.options.HTTPCaller(request)
+	if err != nil {
+		return err
+	}
+	// Drain the body for the connection to be returned to the pool.
+	_, err = io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	return nil
+}
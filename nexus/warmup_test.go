@@ -0,0 +1,54 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	ctx, client, teardown := setup(t, &syncOrAsyncHandler{})
+	defer teardown()
+	tracker := &connectionTracker{}
+	ctx = tracker.context(ctx)
+
+	require.NoError(t, client.Warmup(ctx))
+	require.Equal(t, []bool{false}, tracker.take())
+
+	// The first operation request reuses the warm connection.
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "async"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, []bool{true}, tracker.take())
+}
+
+func TestWarmup_ServiceBaseURLs(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	newServer := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			methods = append(methods, request.Method+" "+request.Host)
+			writer.WriteHeader(http.StatusNotFound)
+		}))
+	}
+	a, b := newServer(), newServer()
+	defer a.Close()
+	defer b.Close()
+
+	client, err := NewClient(ClientOptions{ServiceBaseURLs: []string{a.URL, b.URL}})
+	require.NoError(t, err)
+	require.NoError(t, client.Warmup(context.Background()))
+	require.ElementsMatch(t, []string{"OPTIONS " + a.Listener.Addr().String(), "OPTIONS " + b.Listener.Addr().String()}, methods)
+
+	// Unreachable instances fail the warmup.
+	b.Close()
+	err = client.Warmup(context.Background())
+	require.ErrorContains(t, err, "failed to warm up connection to "+b.Listener.Addr().String())
+	require.NotContains(t, err.Error(), a.Listener.Addr().String())
+}
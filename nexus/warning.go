@@ -0,0 +1,49 @@
+package nexus
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Header for conveying non-fatal warnings on successful responses. May be repeated.
+//
+// Values follow the format of the HTTP Warning header (RFC 7234) using the 299 "Miscellaneous Persistent Warning" code:
+// `299 - "deprecated operation"`.
+const headerWarning = "Nexus-Warning"
+
+const warningCodeMiscPersistent = "299"
+
+func formatWarning(message string) string {
+	return warningCodeMiscPersistent + " - " + strconv.Quote(message)
+}
+
+// parseWarning extracts the warning text from a Nexus-Warning header value, returning false if the value is malformed.
+func parseWarning(value string) (string, bool) {
+	code, rest, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found || len(code) != 3 {
+		return "", false
+	}
+	_, rest, found = strings.Cut(rest, " ")
+	if !found {
+		return "", false
+	}
+	message, err := strconv.Unquote(strings.TrimSpace(rest))
+	if err != nil {
+		return "", false
+	}
+	return message, true
+}
+
+// ResponseWarnings returns the warnings attached by the handler to a successful operation response via
+// [OperationResponseSync.Warnings], e.g. the Successful response of a [StartOperationResult]. Malformed values are
+// ignored.
+func ResponseWarnings(response *http.Response) []string {
+	var warnings []string
+	for _, value := range response.Header.Values(headerWarning) {
+		if message, ok := parseWarning(value); ok {
+			warnings = append(warnings, message)
+		}
+	}
+	return warnings
+}
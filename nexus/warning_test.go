@@ -0,0 +1,48 @@
+package nexus
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarning_RoundTrip(t *testing.T) {
+	for _, message := range []string{"deprecated", `quoted "value"`, "back\\slash", ""} {
+		parsed, ok := parseWarning(formatWarning(message))
+		require.True(t, ok)
+		require.Equal(t, message, parsed)
+	}
+	for _, value := range []string{"", "299", `299 -`, `299 - unquoted`, `2999 - "too long"`} {
+		_, ok := parseWarning(value)
+		require.False(t, ok, value)
+	}
+}
+
+type warningHandler struct {
+	UnimplementedHandler
+}
+
+func (h *warningHandler) StartOperation(ctx context.Context, request *StartOperationRequest) (OperationResponse, error) {
+	response, err := NewOperationResponseSync("partial")
+	if err != nil {
+		return nil, err
+	}
+	response.Warnings = []string{"operation is deprecated", "partial data"}
+	return response, nil
+}
+
+func TestResponseWarnings(t *testing.T) {
+	ctx, client, teardown := setup(t, &warningHandler{})
+	defer teardown()
+
+	result, err := client.StartOperation(ctx, StartOperationOptions{Operation: "foo"})
+	require.NoError(t, err)
+	response := result.Successful
+	require.NotNil(t, response)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, []string{"operation is deprecated", "partial data"}, ResponseWarnings(response))
+	require.Equal(t, `299 - "partial data"`, response.Header.Values(headerWarning)[1])
+}